@@ -0,0 +1,148 @@
+package bus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Event types published by the conductor
+const (
+	TaskQueued        = "task.queued"
+	TaskCreated       = "task.created"
+	TaskClassified    = "task.classified"
+	TaskPlanned       = "task.planned"
+	TaskAssigned      = "task.assigned"
+	TaskEscalated     = "task.escalated"
+	TaskStatusChanged = "task.status"
+	ExecutionStarted  = "execution.started"
+	ExecutionChunk    = "execution.chunk"
+	ExecutionFinished = "execution.finished"
+	ValidationVerdict = "validation.verdict"
+)
+
+// DefaultBufferSize is used when the configured buffer size is not positive
+const DefaultBufferSize = 1000
+
+// Handler receives messages published on the bus
+type Handler func(msg types.Message)
+
+// envelope carries a message, or a barrier used by Flush
+type envelope struct {
+	msg     types.Message
+	barrier chan struct{}
+}
+
+// Bus is an in-process publish/subscribe bus with a bounded buffer. Messages
+// are delivered to every subscriber in publish order by a single dispatcher,
+// so handlers should return quickly and must not publish themselves.
+// Publish blocks while the buffer is full.
+type Bus struct {
+	queue  chan envelope
+	done   chan struct{}
+	mu     sync.RWMutex // guards closed and sends on queue
+	closed bool
+
+	handlersMu sync.Mutex
+	handlers   map[int]Handler
+	nextID     int
+}
+
+// New creates a bus and starts its dispatcher
+func New(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	b := &Bus{
+		queue:    make(chan envelope, bufferSize),
+		done:     make(chan struct{}),
+		handlers: make(map[int]Handler),
+	}
+	go b.dispatch()
+	return b
+}
+
+// Subscribe registers a handler for every message and returns a function that removes it
+func (b *Bus) Subscribe(h Handler) func() {
+	b.handlersMu.Lock()
+	defer b.handlersMu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = h
+
+	return func() {
+		b.handlersMu.Lock()
+		defer b.handlersMu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+// Publish queues a message for delivery. Publishing on a closed bus is a no-op.
+func (b *Bus) Publish(msg types.Message) {
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	b.queue <- envelope{msg: msg}
+}
+
+// Flush waits until every message published before the call has been delivered
+func (b *Bus) Flush() {
+	barrier := make(chan struct{})
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return
+	}
+	b.queue <- envelope{barrier: barrier}
+	b.mu.RUnlock()
+
+	<-barrier
+}
+
+// Close stops accepting messages, delivers those already queued and stops the dispatcher
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	<-b.done
+}
+
+func (b *Bus) dispatch() {
+	defer close(b.done)
+
+	for env := range b.queue {
+		if env.barrier != nil {
+			close(env.barrier)
+			continue
+		}
+
+		b.handlersMu.Lock()
+		handlers := make([]Handler, 0, len(b.handlers))
+		for id := 0; id < b.nextID; id++ {
+			if h, ok := b.handlers[id]; ok {
+				handlers = append(handlers, h)
+			}
+		}
+		b.handlersMu.Unlock()
+
+		for _, h := range handlers {
+			h(env.msg)
+		}
+	}
+}
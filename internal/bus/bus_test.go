@@ -0,0 +1,90 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	b := New(4)
+	defer b.Close()
+
+	var mu sync.Mutex
+	var first, second []string
+	b.Subscribe(func(msg types.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		first = append(first, msg.TaskID)
+	})
+	unsubscribe := b.Subscribe(func(msg types.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		second = append(second, msg.TaskID)
+	})
+
+	// More messages than the buffer holds, so Publish has to wait for the dispatcher
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		b.Publish(types.Message{Type: TaskCreated, TaskID: id})
+	}
+	b.Flush()
+
+	unsubscribe()
+	b.Publish(types.Message{Type: TaskCreated, TaskID: "g"})
+	b.Flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := len(first); got != 7 {
+		t.Fatalf("Expected 7 messages for first subscriber, got %d", got)
+	}
+	for i, id := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		if first[i] != id {
+			t.Errorf("Message %d: expected %s, got %s", i, id, first[i])
+		}
+	}
+	if got := len(second); got != 6 {
+		t.Errorf("Expected 6 messages before unsubscribing, got %d", got)
+	}
+}
+
+func TestBus_Timestamp(t *testing.T) {
+	b := New(0)
+	defer b.Close()
+
+	var got types.Message
+	b.Subscribe(func(msg types.Message) { got = msg })
+	b.Publish(types.Message{Type: TaskStatusChanged, Payload: map[string]interface{}{"status": "done"}})
+	b.Flush()
+
+	if got.Timestamp.IsZero() {
+		t.Error("Expected Publish to set the timestamp")
+	}
+	if got.Payload["status"] != "done" {
+		t.Errorf("Unexpected payload: %v", got.Payload)
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	b := New(10)
+
+	delivered := 0
+	b.Subscribe(func(msg types.Message) { delivered++ })
+	for i := 0; i < 5; i++ {
+		b.Publish(types.Message{Type: TaskCreated})
+	}
+	b.Close()
+
+	if delivered != 5 {
+		t.Errorf("Expected Close to deliver 5 queued messages, got %d", delivered)
+	}
+
+	// Publishing, flushing and closing again are no-ops once closed
+	b.Publish(types.Message{Type: TaskCreated})
+	b.Flush()
+	b.Close()
+	if delivered != 5 {
+		t.Errorf("Expected no delivery after Close, got %d", delivered)
+	}
+}
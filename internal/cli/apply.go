@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/patch"
+	"github.com/cammy/bigo/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyCheck bool
+	applyForce bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <task-id>",
+	Short: "Write a task's changes to the working tree",
+	Long: `Writes the file changes a task's worker produced to the working tree. The
+changes are the diff recorded for the task's last completed attempt or, for
+a planned task, those of its subtasks in order.
+
+Every change is applied in memory first; if any hunk no longer matches the
+files on disk, nothing is written. Use --check to see what would change
+without writing. Changes from failed or rejected tasks are refused unless
+--force is given. A unique prefix of the task ID is enough.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+func init() {
+	applyCmd.Flags().BoolVar(&applyCheck, "check", false, "Check that the changes apply cleanly without writing them")
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "Apply changes from a failed or rejected task")
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	id, err := db.ResolveTaskID(args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task %s not found", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	return applyTask(db, cwd, id, applyCheck, applyForce)
+}
+
+// applyTask stages a task's changes against the tree under root and, unless
+// check is set, writes them and records them as applied
+func applyTask(db *ledger.Ledger, root, id string, check, force bool) error {
+	task, err := db.GetTask(id)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	switch types.TaskStatus(task.Status) {
+	case types.StatusDone, types.StatusApproved:
+	case types.StatusFailed, types.StatusRejected:
+		if !force {
+			return fmt.Errorf("task %s is %s; use --force to apply its changes anyway", id, task.Status)
+		}
+	default:
+		return fmt.Errorf("task %s is %s and has no final changes yet", id, task.Status)
+	}
+
+	patches, err := conductor.TaskPatches(db, id)
+	if err != nil {
+		return fmt.Errorf("failed to load changes: %w", err)
+	}
+	if len(patches) == 0 {
+		fmt.Printf("Task %s made no file changes\n", id)
+		return nil
+	}
+	for _, p := range patches {
+		if p.AppliedAt != nil {
+			return fmt.Errorf("changes from task %s were already applied at %s", p.TaskID, p.AppliedAt.Local().Format(time.DateTime))
+		}
+	}
+
+	tree, err := conductor.StagePatches(root, patches)
+	if err != nil {
+		return fmt.Errorf("changes do not apply cleanly, nothing written: %w", err)
+	}
+
+	stats := patch.Stats(tree.Diff())
+	for _, s := range stats {
+		fmt.Printf("  %s  +%d -%d\n", s.Path, s.Added, s.Removed)
+	}
+	if check {
+		fmt.Printf("✓ %d file(s) would change; rerun without --check to write them\n", len(stats))
+		return nil
+	}
+
+	if err := tree.Write(); err != nil {
+		return fmt.Errorf("failed to write changes: %w", err)
+	}
+	for _, p := range patches {
+		if err := db.MarkApplied(p.ExecutionID); err != nil {
+			return fmt.Errorf("failed to record applied changes: %w", err)
+		}
+	}
+	fmt.Printf("✓ Applied changes to %d file(s)\n", len(stats))
+	return nil
+}
+
+// diffSummary describes the size of a diff, such as "2 file(s), +10 -3"
+func diffSummary(diff string) string {
+	stats := patch.Stats(diff)
+	added, removed := 0, 0
+	for _, s := range stats {
+		added += s.Added
+		removed += s.Removed
+	}
+	return fmt.Sprintf("%d file(s), +%d -%d", len(stats), added, removed)
+}
@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// taskEntry is one task in a tasks file
+type taskEntry struct {
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description" json:"description"`
+}
+
+// UnmarshalYAML accepts either a bare string or a mapping with title/description
+func (e *taskEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Title = node.Value
+		return nil
+	}
+
+	type plain taskEntry
+	return node.Decode((*plain)(e))
+}
+
+// loadTaskFile reads tasks from a YAML list, JSONL, or plain text with one
+// task per line. A path of "-" reads from stdin.
+func loadTaskFile(path string) ([]conductor.TaskSpec, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tasks: %w", err)
+	}
+
+	var entries []taskEntry
+	switch detectTaskFormat(path, data) {
+	case "yaml":
+		if err := yaml.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML tasks: %w", err)
+		}
+	case "jsonl":
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var e taskEntry
+			if err := json.Unmarshal([]byte(line), &e); err != nil {
+				return nil, fmt.Errorf("line %d: invalid JSON task: %w", n, err)
+			}
+			entries = append(entries, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read tasks: %w", err)
+		}
+	default:
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, taskEntry{Title: line})
+		}
+	}
+
+	specs := make([]conductor.TaskSpec, 0, len(entries))
+	for i, e := range entries {
+		if strings.TrimSpace(e.Title) == "" {
+			return nil, fmt.Errorf("task %d has no title", i+1)
+		}
+		specs = append(specs, conductor.TaskSpec{
+			Title:       e.Title,
+			Description: e.Description,
+		})
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no tasks found in %s", path)
+	}
+	return specs, nil
+}
+
+func detectTaskFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".jsonl", ".ndjson", ".json":
+		return "jsonl"
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return "jsonl"
+	case bytes.HasPrefix(trimmed, []byte("- ")):
+		return "yaml"
+	default:
+		return "lines"
+	}
+}
+
+// runBatch executes every task in the file and renders progress as it goes
+func runBatch(ctx context.Context, cond *conductor.Conductor, path string, opts conductor.RunOptions) error {
+	specs, err := loadTaskFile(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("BigO Batch Execution")
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Printf("Tasks: %d\n", len(specs))
+	fmt.Println("───────────────────────────────────────")
+
+	if runDryRun {
+		results := make([]*conductor.RunResult, len(specs))
+		for i, spec := range specs {
+			results[i] = cond.DryRun(spec.Title, spec.Description, opts)
+		}
+		printBatchSummary(os.Stdout, specs, results)
+		fmt.Println("[DRY RUN] No execution performed")
+		return nil
+	}
+
+	progress := newBatchProgress(os.Stdout, specs)
+	unsubscribe := cond.Bus().Subscribe(progress.handle)
+	results := cond.RunBatch(ctx, specs, opts)
+	cond.Bus().Flush()
+	unsubscribe()
+	progress.finish()
+
+	printBatchSummary(os.Stdout, specs, results)
+
+	var finished []string
+	for _, r := range results {
+		if r.Status == types.StatusDone || r.Status == types.StatusApproved {
+			finished = append(finished, r.TaskID)
+		}
+	}
+	return commitTasks(finished)
+}
+
+// batchProgress renders a live table of task states from bus events. On a
+// terminal the table is redrawn in place; otherwise each state change is
+// printed on its own line.
+type batchProgress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	live     bool
+	specs    []conductor.TaskSpec
+	index    map[string]int // task ID -> position in specs
+	statuses []types.TaskStatus
+	backends []types.Backend
+	started  time.Time
+	drawn    int
+}
+
+func newBatchProgress(out *os.File, specs []conductor.TaskSpec) *batchProgress {
+	live := false
+	if info, err := out.Stat(); err == nil {
+		live = info.Mode()&os.ModeCharDevice != 0
+	}
+
+	return &batchProgress{
+		out:      out,
+		live:     live,
+		specs:    specs,
+		index:    make(map[string]int, len(specs)),
+		statuses: make([]types.TaskStatus, len(specs)),
+		backends: make([]types.Backend, len(specs)),
+		started:  time.Now(),
+	}
+}
+
+func (p *batchProgress) handle(msg types.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if msg.Type == bus.TaskQueued {
+		if i, ok := msg.Payload["index"].(int); ok && i >= 0 && i < len(p.specs) {
+			p.index[msg.TaskID] = i
+			p.statuses[i] = types.StatusPending
+		}
+		if p.live {
+			p.redraw()
+		}
+		return
+	}
+
+	i, ok := p.index[msg.TaskID]
+	if !ok {
+		return
+	}
+
+	switch msg.Type {
+	case bus.TaskAssigned:
+		if backend, ok := msg.Payload["backend"].(string); ok {
+			p.backends[i] = types.Backend(backend)
+		}
+	case bus.TaskEscalated:
+		if backend, ok := msg.Payload["to"].(string); ok {
+			p.backends[i] = types.Backend(backend)
+		}
+	case bus.TaskStatusChanged:
+		status, _ := msg.Payload["status"].(string)
+		if types.TaskStatus(status) == p.statuses[i] {
+			return
+		}
+		p.statuses[i] = types.TaskStatus(status)
+		if !p.live {
+			fmt.Fprintf(p.out, "[%d/%d] %-10s %s\n", i+1, len(p.specs), status, truncate(p.specs[i].Title, 60))
+		}
+	default:
+		return
+	}
+
+	if p.live {
+		p.redraw()
+	}
+}
+
+func (p *batchProgress) redraw() {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSTATUS\tBACKEND\tTASK")
+	done := 0
+	for i, spec := range p.specs {
+		status := p.statuses[i]
+		if isFinal(status) {
+			done++
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, status, p.backends[i], truncate(spec.Title, 60))
+	}
+	tw.Flush()
+	fmt.Fprintf(&buf, "%d/%d finished, %s elapsed\n", done, len(p.specs), time.Since(p.started).Round(time.Second))
+
+	// Move back over the previous table and clear it before drawing
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\033[%dA\033[J", p.drawn)
+	}
+	fmt.Fprint(p.out, buf.String())
+	p.drawn = strings.Count(buf.String(), "\n")
+}
+
+func (p *batchProgress) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.live && p.drawn > 0 {
+		fmt.Fprintf(p.out, "\033[%dA\033[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+func printBatchSummary(out io.Writer, specs []conductor.TaskSpec, results []*conductor.RunResult) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSTATUS\tTIER\tBACKEND\tCOST\tDURATION\tTASK")
+
+	var totalCost float64
+	counts := make(map[types.TaskStatus]int)
+	for i, r := range results {
+		tier := "-"
+		if r.Classification != nil {
+			tier = fmt.Sprintf("T%d", r.Classification.Tier)
+		}
+
+		status := string(r.Status)
+		if r.DryRun {
+			status = "planned"
+		}
+		counts[types.TaskStatus(status)]++
+
+		cost := r.TotalCostUSD
+		if r.DryRun {
+			cost = r.EstimatedCostUSD
+		}
+		totalCost += cost
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t$%.4f\t%s\t%s\n", i+1, status, tier, r.ActualBackend,
+			cost, r.Duration.Round(time.Millisecond), truncate(specs[i].Title, 60))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(out, "───────────────────────────────────────")
+	var parts []string
+	for _, s := range []types.TaskStatus{"planned", types.StatusDone, types.StatusApproved, types.StatusRejected, types.StatusFailed} {
+		if counts[s] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[s], s))
+		}
+	}
+	fmt.Fprintf(out, "Summary: %s — total cost $%.4f\n", strings.Join(parts, ", "), totalCost)
+
+	for i, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(out, "  #%d: %s\n", i+1, r.Error)
+		}
+	}
+}
+
+func isFinal(s types.TaskStatus) bool {
+	switch s {
+	case types.StatusDone, types.StatusApproved, types.StatusRejected, types.StatusFailed:
+		return true
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
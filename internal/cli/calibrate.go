@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var calibrateApply bool
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Measure classifier accuracy against task outcomes",
+	Long: `Compares the tier the classifier predicted for each past task with the
+tier it turned out to need: the tier forced with --tier, the tier an
+escalated task succeeded on, or one tier more when a task failed or its
+output was rejected by validators.
+
+Reports a confusion matrix of predicted vs. effective tier and the precision
+of each pattern, and proposes new weights for patterns matched by at least
+` + fmt.Sprint(conductor.MinCalibrationSamples) + ` tasks. With --apply, the proposed weights are written to
+.bigo/patterns.yaml.`,
+	Args: cobra.NoArgs,
+	RunE: runCalibrate,
+}
+
+func init() {
+	calibrateCmd.Flags().BoolVar(&calibrateApply, "apply", false, "Write the proposed weights to .bigo/patterns.yaml")
+	classifyCmd.AddCommand(calibrateCmd)
+}
+
+func runCalibrate(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cfg, err := loadConfig(cwd)
+	if err != nil {
+		return err
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	outcomes, err := db.GetClassificationOutcomes()
+	if err != nil {
+		return fmt.Errorf("failed to load task outcomes: %w", err)
+	}
+
+	classifier := conductor.NewClassifier()
+	if err := classifier.ApplyPatterns(cfg.Classifier.Patterns); err != nil {
+		return fmt.Errorf("invalid classifier patterns: %w", err)
+	}
+	cal := classifier.Calibrate(outcomes)
+
+	fmt.Println("Classifier Calibration")
+	fmt.Println("═══════════════════════════════════════")
+	if cal.Outcomes == 0 {
+		fmt.Println("No finished tasks to learn from yet.")
+		return nil
+	}
+	fmt.Printf("Outcomes: %d task(s), %.0f%% predicted correctly\n", cal.Outcomes, cal.Accuracy()*100)
+
+	fmt.Println("───────────────────────────────────────")
+	fmt.Println("Predicted (rows) vs. effective (columns) tier:")
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "\t")
+	for t := types.TierTrivial; t <= types.TierCritical; t++ {
+		fmt.Fprintf(tw, "T%d\t", t)
+	}
+	fmt.Fprintln(tw)
+	for p := types.TierTrivial; p <= types.TierCritical; p++ {
+		fmt.Fprintf(tw, "T%d\t", p)
+		for e := types.TierTrivial; e <= types.TierCritical; e++ {
+			if n := cal.Confusion[p][e]; n > 0 {
+				fmt.Fprintf(tw, "%d\t", n)
+			} else {
+				fmt.Fprint(tw, ".\t")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	fmt.Println("───────────────────────────────────────")
+	fmt.Println("Patterns:")
+	weights := make(map[string]map[string]float64)
+	proposals := 0
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  TIER\tPATTERN\tMATCHED\tPRECISION\tWEIGHT\tPROPOSED")
+	for _, s := range cal.Patterns {
+		weight, proposed := "-", ""
+		if s.Exists {
+			weight = fmt.Sprintf("%.2f", s.Weight)
+		} else {
+			proposed = "(pattern removed)"
+		}
+		if s.Proposed > 0 {
+			proposed = fmt.Sprintf("%.2f", s.Proposed)
+			tier := strings.ToLower(s.Tier.String())
+			if weights[tier] == nil {
+				weights[tier] = make(map[string]float64)
+			}
+			weights[tier][s.Name] = s.Proposed
+			proposals++
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%.0f%%\t%s\t%s\n", s.Tier, s.Name, s.Matched, s.Precision()*100, weight, proposed)
+	}
+	tw.Flush()
+
+	fmt.Println("───────────────────────────────────────")
+	patternsPath := filepath.Join(cwd, ".bigo", config.PatternsFile)
+	switch {
+	case proposals == 0:
+		fmt.Printf("No weight changes proposed (patterns need %d matched tasks).\n", conductor.MinCalibrationSamples)
+	case !calibrateApply:
+		fmt.Printf("%d weight change(s) proposed; rerun with --apply to write them to %s\n", proposals, patternsPath)
+	default:
+		if err := config.SavePatternWeights(patternsPath, weights); err != nil {
+			return fmt.Errorf("failed to save pattern weights: %w", err)
+		}
+		fmt.Printf("✓ Wrote %d weight change(s) to %s\n", proposals, patternsPath)
+	}
+
+	return nil
+}
@@ -2,9 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/internal/repo"
+	"github.com/cammy/bigo/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -12,19 +15,41 @@ var classifyCmd = &cobra.Command{
 	Use:   "classify [task description]",
 	Short: "Classify a task without executing it",
 	Long: `Analyzes the task description and shows the complexity tier,
-recommended backend, and reasoning behind the classification.`,
+recommended backend, and reasoning behind the classification.
+With --explain, shows how each matching pattern contributed to the tier scores.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runClassify,
 }
 
+var classifyExplain bool
+
 func init() {
+	classifyCmd.Flags().BoolVar(&classifyExplain, "explain", false, "Show each pattern's contribution to the tier scores")
 	rootCmd.AddCommand(classifyCmd)
 }
 
 func runClassify(cmd *cobra.Command, args []string) error {
 	task := strings.Join(args, " ")
 
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := loadConfig(cwd)
+	if err != nil {
+		return err
+	}
+	tiers := cfg.TierConfigs()
+
 	classifier := conductor.NewClassifier()
+	classifier.SetRouting(tiers)
+	if err := classifier.ApplyPatterns(cfg.Classifier.Patterns); err != nil {
+		return fmt.Errorf("invalid classifier patterns: %w", err)
+	}
+	if idx, err := repo.Scan(cwd); err == nil {
+		classifier.SetIndex(idx)
+	}
 	result := classifier.Classify(task, "")
 
 	fmt.Println("Task Classification")
@@ -43,25 +68,62 @@ func runClassify(cmd *cobra.Command, args []string) error {
 
 	fmt.Println("───────────────────────────────────────")
 
+	if classifyExplain {
+		printExplanation(classifier.Explain(task, ""), result)
+		fmt.Println("───────────────────────────────────────")
+	}
+
 	// Show tier routing info
+	route := tiers[result.Tier]
 	fmt.Println("\nRouting for this tier:")
-	switch result.Tier {
-	case 0:
-		fmt.Println("  → Ollama (fast) - no validation")
-		fmt.Println("  → Cost: $0.00")
-	case 1:
-		fmt.Println("  → Ollama (default) + 1 validator")
-		fmt.Println("  → Cost: $0.00")
-	case 2:
-		fmt.Println("  → Claude Sonnet + 2 validators")
-		fmt.Println("  → Est. cost: ~$0.02-0.10")
-	case 3:
-		fmt.Println("  → Claude Sonnet + Opus planning + 3 validators")
-		fmt.Println("  → Est. cost: ~$0.10-0.50")
-	case 4:
-		fmt.Println("  → Claude Opus + 5 validators")
-		fmt.Println("  → Est. cost: ~$0.50-2.00")
+	fmt.Printf("  → Primary:    %s\n", route.PrimaryBackend)
+	if route.ValidatorCount > 0 {
+		fmt.Printf("  → Validation: %d × %s (%d approval(s) required)\n",
+			route.ValidatorCount, route.ValidatorBackend, route.RequiredApprovals)
+	} else {
+		fmt.Println("  → Validation: none")
+	}
+	if len(route.Fallbacks) > 0 {
+		fallbacks := make([]string, len(route.Fallbacks))
+		for i, b := range route.Fallbacks {
+			fallbacks[i] = string(b)
+		}
+		fmt.Printf("  → Fallbacks:  %s\n", strings.Join(fallbacks, ", "))
 	}
 
 	return nil
 }
+
+// printExplanation lists the tier scores and the patterns behind them
+func printExplanation(e *conductor.Explanation, result *types.ClassificationResult) {
+	fmt.Println("Pattern scores:")
+	if !e.Matched {
+		fmt.Printf("  No pattern matched; defaulting to %s\n", e.PatternTier)
+	}
+	for tier := types.TierCritical; tier >= types.TierTrivial; tier-- {
+		score, ok := e.Scores[tier]
+		if !ok {
+			continue
+		}
+		marker := " "
+		if tier == e.PatternTier {
+			marker = "→"
+		}
+		fmt.Printf("%s %-9s %.2f\n", marker, tier, score)
+		for _, m := range e.Matches {
+			if m.Tier != tier {
+				continue
+			}
+			origin := "built-in"
+			if m.Pattern.Custom {
+				origin = "custom"
+			}
+			fmt.Printf("    +%.2f  %-18s %s  %s\n", m.Pattern.Weight, m.Pattern.Name, origin, m.Pattern.Regex)
+		}
+	}
+
+	if result.Tier != e.PatternTier {
+		fmt.Printf("Scope adjustment: %s → %s (~%d lines, %d file(s))\n",
+			e.PatternTier, result.Tier, result.EstimatedLines, result.EstimatedFiles)
+	}
+}
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -41,3 +43,19 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// loadConfig loads the project's configuration, falling back to the defaults
+// (and any patterns file) when the project has no config.yaml. A config that
+// exists but is invalid is an error rather than silently replaced.
+func loadConfig(cwd string) (*config.Config, error) {
+	dir := filepath.Join(cwd, ".bigo")
+	cfg, err := config.Load(filepath.Join(dir, "config.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg = config.Default()
+		err = cfg.LoadPatterns(filepath.Join(dir, config.PatternsFile))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimeFlag parses a point in time given as a date ("2026-01-31"), an
+// RFC 3339 timestamp, "today", "yesterday", or an age relative to now
+// ("7d", "2w", "36h", "90m")
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(s) {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	// Days and weeks are not understood by time.ParseDuration
+	if n := len(s); n > 1 {
+		if days, err := strconv.Atoi(s[:n-1]); err == nil && days >= 0 {
+			switch s[n-1] {
+			case 'd':
+				return now.AddDate(0, 0, -days), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*days), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (use a date like 2026-01-31, \"today\", or an age like 7d or 24h)", s)
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/internal/git"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// commitTasks commits the changes of each finished task on its own branch
+// when git mode is enabled, reporting but not stopping on failures
+func commitTasks(ids []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	cfg, err := loadConfig(cwd)
+	if err != nil {
+		return err
+	}
+	if !cfg.Git.Enabled() || len(ids) == 0 {
+		return nil
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	repo, err := git.Open(cwd)
+	if err != nil {
+		return fmt.Errorf("git mode is enabled but %w", err)
+	}
+
+	fmt.Println("───────────────────────────────────────")
+	var failed int
+	for _, id := range ids {
+		if err := commitTask(db, repo, cfg.Git, cwd, id); err != nil {
+			fmt.Printf("⚠ Task %s: %v\n", id, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to commit %d task(s)", failed)
+	}
+	return nil
+}
+
+// commitTask applies a task's changes in a new working tree on the branch
+// <prefix><task-id>, started from HEAD, and commits them there. The working
+// tree is kept in worktree mode and removed in branch mode; the project's
+// own checkout is never touched.
+func commitTask(db *ledger.Ledger, repo *git.Repo, cfg config.GitConfig, project, id string) error {
+	task, err := db.GetTask(id)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	patches, err := conductor.TaskPatches(db, id)
+	if err != nil {
+		return fmt.Errorf("failed to load changes: %w", err)
+	}
+	if len(patches) == 0 {
+		fmt.Printf("Task %s made no file changes; nothing to commit\n", id)
+		return nil
+	}
+
+	// The project may be a subdirectory of the repository
+	sub, err := filepath.Rel(repo.Root, project)
+	if err != nil {
+		return err
+	}
+	branch := cfg.BranchPrefix + id
+	path := filepath.Join(project, ".bigo", "worktrees", id)
+	wt, err := repo.AddWorktree(path, branch, "HEAD")
+	if err != nil {
+		return err
+	}
+	discard := func() {
+		if err := repo.RemoveWorktree(path); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+		}
+		if err := repo.DeleteBranch(branch); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+		}
+	}
+
+	tree, err := conductor.StagePatches(filepath.Join(path, sub), patches)
+	if err != nil {
+		discard()
+		return fmt.Errorf("changes do not apply to HEAD: %w", err)
+	}
+	if err := tree.Write(); err != nil {
+		discard()
+		return fmt.Errorf("failed to write changes: %w", err)
+	}
+	var paths []string
+	for _, p := range tree.Changed() {
+		paths = append(paths, filepath.Join(sub, filepath.FromSlash(p)))
+	}
+
+	message, err := commitMessage(db, task)
+	if err != nil {
+		discard()
+		return err
+	}
+	sha, err := wt.Commit(message, paths)
+	if err != nil {
+		discard()
+		return err
+	}
+
+	commit := &ledger.TaskCommit{Branch: branch, SHA: sha, Worktree: path}
+	if cfg.Mode != config.GitWorktree {
+		if err := repo.RemoveWorktree(path); err != nil {
+			fmt.Printf("⚠ %v\n", err)
+		}
+		commit.Worktree = ""
+	}
+	if err := db.SetCommit(id, commit); err != nil {
+		return fmt.Errorf("failed to record commit: %w", err)
+	}
+
+	fmt.Printf("✓ Committed %s on %s\n", shortSHA(sha), branch)
+	if commit.Worktree != "" {
+		fmt.Printf("  Worktree: %s\n", commit.Worktree)
+	}
+	return nil
+}
+
+// commitMessage builds a commit message from a task's title and details,
+// with trailers naming the task, its tier, the backend that did the work
+// and what it cost
+func commitMessage(db *ledger.Ledger, task *ledger.Task) (string, error) {
+	backend, cost, err := taskSpend(db, task)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	subject, _, _ := strings.Cut(strings.TrimSpace(task.Title), "\n")
+	b.WriteString(subject)
+	if task.Description != "" {
+		b.WriteString("\n\n")
+		b.WriteString(strings.TrimSpace(task.Description))
+	}
+	fmt.Fprintf(&b, "\n\nBigO-Task: %s\n", task.ID)
+	fmt.Fprintf(&b, "BigO-Tier: T%d (%s)\n", task.Tier, types.Tier(task.Tier))
+	fmt.Fprintf(&b, "BigO-Backend: %s\n", backend)
+	fmt.Fprintf(&b, "BigO-Cost: $%.4f\n", cost)
+	return b.String(), nil
+}
+
+// taskSpend returns the backend of a task's last attempt and the cost of
+// every execution for it and its subtasks
+func taskSpend(db *ledger.Ledger, task *ledger.Task) (string, float64, error) {
+	execs, err := db.GetExecutions(task.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to load executions: %w", err)
+	}
+	backend := task.WorkerBackend
+	var cost float64
+	for _, e := range execs {
+		cost += e.CostUSD
+		if e.Kind == ledger.KindAttempt {
+			backend = e.Backend
+		}
+	}
+
+	subtasks, err := db.GetSubtasks(task.ID)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, sub := range subtasks {
+		_, subCost, err := taskSpend(db, sub)
+		if err != nil {
+			return "", 0, err
+		}
+		cost += subCost
+	}
+	return backend, cost, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
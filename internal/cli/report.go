@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSince  string
+	reportUntil  string
+	reportPeriod string
+	reportTop    int
+	reportFormat string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarise cost, token usage and reliability over a period",
+	Long: `Reports on the executions recorded in the ledger: cost by day or week,
+backend and tier, token usage, average and 95th percentile latency and the
+failure rate of each backend, failure and escalation rates by tier, and the
+most expensive tasks.
+
+The report can be printed as a table or exported as CSV, JSON or Markdown:
+
+  bigo report --since 1w --format markdown > weekly.md
+  bigo report --since 2026-01-01 --period day --format csv > january.csv
+
+Dates for --since and --until can be a day (2026-01-31), "today",
+"yesterday", or an age such as 7d, 2w or 12h.`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Only executions at or after this time")
+	reportCmd.Flags().StringVar(&reportUntil, "until", "", "Only executions before this time")
+	reportCmd.Flags().StringVarP(&reportPeriod, "period", "p", "week", "Group costs by day or week")
+	reportCmd.Flags().IntVar(&reportTop, "top", 10, "How many of the most expensive tasks to list")
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "o", "table", "Output format: table, csv, json or markdown")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	period, err := report.ParsePeriod(reportPeriod)
+	if err != nil {
+		return err
+	}
+	format, err := report.ParseFormat(reportFormat)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	filter := ledger.ExecutionFilter{}
+	if reportSince != "" {
+		if filter.Since, err = parseTimeFlag(reportSince, now); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+	}
+	if reportUntil != "" {
+		if filter.Until, err = parseTimeFlag(reportUntil, now); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	records, err := db.ListExecutions(filter)
+	if err != nil {
+		return fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	r := report.Build(records, report.Options{
+		Since:  filter.Since,
+		Until:  filter.Until,
+		Period: period,
+		Top:    reportTop,
+	})
+	return report.Write(os.Stdout, r, format)
+}
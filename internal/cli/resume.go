@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/spf13/cobra"
+)
+
+var resumeAllowOverspend bool
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [task-id]",
+	Short: "Continue tasks left unfinished by an interrupted run",
+	Long: `Finds tasks that were in flight when a bigo process stopped and marks them
+orphaned, then picks them up from the executions recorded in the ledger.
+Output that was never reviewed is validated; otherwise the task is retried
+with the attempts left in its retry budget.
+
+Without a task ID, every orphaned task is resumed. Subtasks of an orphaned
+planned task are resumed along with it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runResume,
+}
+
+func init() {
+	resumeCmd.Flags().BoolVar(&resumeAllowOverspend, "allow-overspend", false, "Resume even if a task would exceed configured cost limits")
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	cond, closeConductor, err := openConductor(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeConductor()
+
+	ids := args
+	if len(ids) == 0 {
+		ids, err = cond.OrphanedTasks()
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			fmt.Println("No orphaned tasks to resume")
+			return nil
+		}
+	}
+
+	opts := conductor.RunOptions{AllowOverspend: resumeAllowOverspend}
+	for i, id := range ids {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println("BigO Resume")
+		fmt.Println("═══════════════════════════════════════")
+
+		result, err := cond.Resume(ctx, id, opts)
+		if err != nil {
+			if len(args) > 0 {
+				return err
+			}
+			fmt.Printf("Task %s: %v\n", id, err)
+			continue
+		}
+
+		fmt.Printf("Task: %s (%s)\n", result.Title, result.TaskID)
+		if result.Classification != nil {
+			fmt.Printf("Tier: %s (T%d)\n", result.Classification.Tier, result.Classification.Tier)
+		}
+		fmt.Println("───────────────────────────────────────")
+		printResult(result)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cammy/bigo/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <task-id>",
+	Short: "Undo the commit a task made in git mode",
+	Long: `Undoes the commit holding a task's changes. If the commit has been merged
+into the checked-out branch, a commit reverting it is added there; otherwise
+the task's branch is deleted. A worktree kept for the task is removed either
+way. A branch that has gained commits since the task's own is left alone.
+
+A unique prefix of the task ID is enough.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRevert,
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	id, err := db.ResolveTaskID(args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task %s not found", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	commit, err := db.GetCommit(id)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	if commit == nil {
+		return fmt.Errorf("task %s has no commit; only changes committed in git mode can be reverted", id)
+	}
+	if commit.RevertedAt != nil {
+		return fmt.Errorf("task %s was already reverted at %s", id, commit.RevertedAt.Local().Format(time.DateTime))
+	}
+
+	repo, err := git.Open(cwd)
+	if err != nil {
+		return err
+	}
+	merged, err := repo.IsAncestor(commit.SHA, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	tip := repo.BranchCommit(commit.Branch)
+	if !merged && tip != "" && tip != commit.SHA {
+		return fmt.Errorf("branch %s has commits after the task's own (%s); delete it yourself", commit.Branch, shortSHA(commit.SHA))
+	}
+	if commit.Worktree != "" {
+		if _, err := os.Stat(commit.Worktree); err == nil {
+			if err := repo.RemoveWorktree(commit.Worktree); err != nil {
+				return err
+			}
+		}
+	}
+
+	if merged {
+		sha, err := repo.Revert(commit.SHA)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Reverted %s with %s\n", shortSHA(commit.SHA), shortSHA(sha))
+	} else {
+		if tip != "" {
+			if err := repo.DeleteBranch(commit.Branch); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("✓ Deleted unmerged branch %s (%s)\n", commit.Branch, shortSHA(commit.SHA))
+	}
+
+	if err := db.MarkReverted(id); err != nil {
+		return fmt.Errorf("failed to record revert: %w", err)
+	}
+	return nil
+}
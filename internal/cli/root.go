@@ -40,7 +40,13 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(tasksCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(revertCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(versionCmd)
 }
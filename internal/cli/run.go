@@ -1,53 +1,293 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/cammy/bigo/internal/conductor"
-	"github.com/cammy/bigo/internal/config"
 	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/repo"
 	"github.com/cammy/bigo/internal/workers"
 	"github.com/cammy/bigo/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 var (
-	runTier   string
-	runDryRun bool
+	runTier            string
+	runDryRun          bool
+	runAllowOverspend  bool
+	runFile            string
+	runDescription     string
+	runDescriptionFile string
+	runContext         []string
+	runApplyFlag       bool
+	runStream          bool
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run [task description]",
 	Short: "Execute a task through the orchestrator",
 	Long: `Classifies the task, routes it to the appropriate backend
-(Ollama for simple tasks, Claude for complex ones), and executes it.`,
-	Args: cobra.MinimumNArgs(1),
+(Ollama for simple tasks, Claude for complex ones), and executes it.
+
+Keep the task itself short and pass long specs, stack traces or issue
+bodies with --description or --description-file; use --description-file -
+to read them from stdin:
+
+  gh issue view 42 | bigo run "Fix the crash in issue 42" --description-file -
+
+Files the task mentions by path, package or identifier are packed into the
+prompt, within each backend's context budget. Add more with --context, which
+takes files, directories and globs and puts them first:
+
+  bigo run "Add retries to the Ollama worker" -c internal/workers/ollama.go
+
+Changes the worker makes to files, as unified diffs or as whole files in
+labelled code blocks, are checked against the working tree and recorded.
+Write them with 'bigo apply <task-id>', or pass --apply to write them as
+soon as the task succeeds. With git.mode set to branch or worktree in the
+config, each successful task's changes are also committed on a branch of
+its own, leaving your checkout alone; undo one with 'bigo revert <task-id>'.
+
+--stream prints the worker's output as it is generated, for backends that
+support it (Claude, Gemini, Ollama and OpenAI-compatible servers), instead
+of only when it finishes.
+
+--tier skips classification and runs the task at the given tier. The
+override is recorded in the ledger, where classifier calibration treats it
+as the tier the task needed.
+
+With --file, runs every task in a YAML list, JSONL file, or plain text file
+(one task per line) concurrently, within each backend's max_concurrent limit.
+Use --file - to read tasks from stdin.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if runFile == "" && len(args) == 0 {
+			return fmt.Errorf("requires a task description or --file")
+		}
+		if runFile != "" && len(args) > 0 {
+			return fmt.Errorf("cannot combine a task description with --file")
+		}
+		if runFile != "" && (runDescription != "" || runDescriptionFile != "") {
+			return fmt.Errorf("cannot combine --description with --file; give each task its own description in the file")
+		}
+		if runFile != "" && runApplyFlag {
+			return fmt.Errorf("cannot combine --apply with --file; apply each task with 'bigo apply <task-id>'")
+		}
+		if runFile != "" && runStream {
+			return fmt.Errorf("cannot combine --stream with --file")
+		}
+		if runDescription != "" && runDescriptionFile != "" {
+			return fmt.Errorf("cannot combine --description with --description-file")
+		}
+		return nil
+	},
 	RunE: runTask,
 }
 
 func init() {
 	runCmd.Flags().StringVarP(&runTier, "tier", "t", "", "Force a specific tier (trivial, simple, standard, complex, critical)")
 	runCmd.Flags().BoolVarP(&runDryRun, "dry-run", "n", false, "Classify and show routing without executing")
+	runCmd.Flags().BoolVar(&runAllowOverspend, "allow-overspend", false, "Run even if the task would exceed configured cost limits")
+	runCmd.Flags().StringVarP(&runFile, "file", "f", "", "Run a batch of tasks from a YAML, JSONL or text file (- for stdin)")
+	runCmd.Flags().StringVarP(&runDescription, "description", "d", "", "Details of the task, kept out of its title")
+	runCmd.Flags().StringVar(&runDescriptionFile, "description-file", "", "Read the task's details from a file (- for stdin)")
+	runCmd.Flags().StringSliceVarP(&runContext, "context", "c", nil, "Files, directories or globs to pack into the prompt (repeatable)")
+	runCmd.Flags().BoolVar(&runApplyFlag, "apply", false, "Write the task's file changes to the working tree if it succeeds")
+	runCmd.Flags().BoolVar(&runStream, "stream", false, "Print worker output as it is generated")
 }
 
 func runTask(cmd *cobra.Command, args []string) error {
 	task := strings.Join(args, " ")
+	ctx := cmd.Context()
+
+	opts := conductor.RunOptions{AllowOverspend: runAllowOverspend, Context: runContext, Stream: runStream}
+	if runTier != "" {
+		tier, err := types.ParseTier(runTier)
+		if err != nil {
+			return err
+		}
+		opts.Tier = &tier
+	}
+
+	description, err := readDescription()
+	if err != nil {
+		return err
+	}
+
+	cond, closeConductor, err := openConductor(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer closeConductor()
+
+	if runFile != "" {
+		return runBatch(ctx, cond, runFile, opts)
+	}
+
+	fmt.Println("BigO Task Execution")
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Printf("Task: %s\n", task)
+	if description != "" {
+		fmt.Printf("Details: %d line(s)\n", strings.Count(description, "\n")+1)
+	}
+	fmt.Println("───────────────────────────────────────")
+
+	if runDryRun {
+		result := cond.DryRun(task, description, opts)
+
+		fmt.Printf("Tier:       %s (T%d)", result.Classification.Tier.String(), result.Classification.Tier)
+		if result.Classification.Source == "forced" {
+			fmt.Print(" (forced)")
+		}
+		fmt.Println()
+		fmt.Printf("Confidence: %.0f%%", result.Classification.Confidence*100)
+		if result.Classification.Source == "cache" {
+			fmt.Print(" (cached model verdict)")
+		}
+		fmt.Println()
+		fmt.Printf("Backend:    %s\n", result.ActualBackend)
+		if result.EstimatedCostUSD > 0 {
+			fmt.Printf("Est. cost:  $%.4f\n", result.EstimatedCostUSD)
+		}
+
+		if !result.WorkerAvailable {
+			fmt.Println("⚠ Primary worker not available")
+			if result.FallbackBackend != "" {
+				fmt.Printf("  Fallback: %s\n", result.FallbackBackend)
+			} else {
+				fmt.Println("  No fallback available!")
+			}
+		}
+
+		if result.PlannerBackend != "" {
+			fmt.Printf("Planning:   %s splits the task into subtasks, each routed by its own tier\n", result.PlannerBackend)
+		}
+
+		if len(result.EscalationLadder) > 0 {
+			fmt.Printf("Escalation: %s\n", joinBackends(result.EscalationLadder))
+		}
+
+		if packed := result.Context; packed != nil && len(packed.Files)+len(packed.Omitted) > 0 {
+			fmt.Printf("Context:    %d file(s), ~%d tokens\n", len(packed.Files), packed.Tokens)
+			for _, label := range packed.Labels() {
+				fmt.Printf("  %s\n", label)
+			}
+			if len(packed.Omitted) > 0 {
+				fmt.Printf("  (%d more over budget)\n", len(packed.Omitted))
+			}
+		}
+
+		if result.ValidationRequired {
+			fmt.Printf("Validation: %d validator(s) on %s, %d approval(s) required\n",
+				result.ValidatorCount, result.ValidatorBackend, result.RequiredApprovals)
+		} else {
+			fmt.Println("Validation: none for this tier")
+		}
+		if result.TestCommand != "" {
+			fmt.Printf("Tests:      %s on every change\n", result.TestCommand)
+		}
+
+		for _, w := range result.Warnings {
+			fmt.Printf("⚠ %s\n", w)
+		}
+
+		fmt.Println("───────────────────────────────────────")
+		fmt.Println("[DRY RUN] No execution performed")
+		return nil
+	}
+
+	// Execute the task
+	fmt.Println("Executing...")
+	fmt.Println()
+
+	var stream *streamPrinter
+	if runStream {
+		stream = newStreamPrinter(os.Stdout)
+		unsubscribe := cond.Bus().Subscribe(stream.handle)
+		defer unsubscribe()
+	}
+
+	result, err := cond.Run(ctx, task, description, opts)
+	if stream != nil {
+		cond.Bus().Flush()
+		stream.finish()
+	}
+	if err != nil {
+		return fmt.Errorf("execution failed: %w", err)
+	}
+
+	printResult(result)
+
+	finished := result.Status == types.StatusDone || result.Status == types.StatusApproved
+	if finished {
+		if err := commitTasks([]string{result.TaskID}); err != nil {
+			return err
+		}
+	}
+
+	if !runApplyFlag {
+		return nil
+	}
+	fmt.Println("───────────────────────────────────────")
+	if !finished {
+		return fmt.Errorf("task %s, not applying its changes", result.Status)
+	}
+	return applyRunResult(result.TaskID)
+}
 
+// applyRunResult writes the changes of a task that just ran
+func applyRunResult(id string) error {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %w", err)
 	}
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return applyTask(db, cwd, id, false, false)
+}
+
+// readDescription returns the task details given with --description or
+// read from --description-file, trimmed of surrounding whitespace
+func readDescription() (string, error) {
+	if runDescriptionFile == "" {
+		return strings.TrimSpace(runDescription), nil
+	}
+
+	var data []byte
+	var err error
+	if runDescriptionFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(runDescriptionFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read description: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// openConductor loads the project's config and ledger, checks the quotas of
+// paid backends, registers workers and sweeps up tasks orphaned by an
+// interrupted run. The returned function closes the conductor and ledger.
+func openConductor(ctx context.Context, requireLedger bool) (*conductor.Conductor, func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
 
 	// Load config and ledger
-	configPath := filepath.Join(cwd, ".bigo", "config.yaml")
-	cfg, err := config.Load(configPath)
+	cfg, err := loadConfig(cwd)
 	if err != nil {
-		// Use default config if not initialized
-		cfg = config.Default()
+		return nil, nil, err
 	}
 
 	ledgerPath := filepath.Join(cwd, ".bigo", "ledger.db")
@@ -55,13 +295,13 @@ func runTask(cmd *cobra.Command, args []string) error {
 	if _, err = os.Stat(ledgerPath); err == nil {
 		l, err = ledger.Open(ledgerPath)
 		if err != nil {
-			return fmt.Errorf("failed to open ledger: %w", err)
+			return nil, nil, fmt.Errorf("failed to open ledger: %w", err)
 		}
-		defer l.Close()
+	} else if requireLedger {
+		return nil, nil, fmt.Errorf("BigO not initialized. Run 'bigo init' first")
 	}
 
 	// Check quotas before initializing
-	ctx := cmd.Context()
 
 	// Check Claude Quota
 	if cfg.Workers.Claude.Enabled {
@@ -110,131 +350,151 @@ func runTask(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Create conductor
-	cond := conductor.NewConductor(cfg, l)
-
-	fmt.Println("BigO Task Execution")
-	fmt.Println("═══════════════════════════════════════")
-	fmt.Printf("Task: %s\n", task)
-	fmt.Println("───────────────────────────────────────")
-
-	if runDryRun {
-		result := cond.DryRun(task, "")
+	// Check the OpenAI-compatible server
+	if cfg.Workers.OpenAI.Enabled {
+		var model string
+		for _, m := range cfg.Workers.OpenAI.Models {
+			model = m
+			break
+		}
 
-		fmt.Printf("Tier:       %s (T%d)\n", result.Classification.Tier.String(), result.Classification.Tier)
-		fmt.Printf("Confidence: %.0f%%\n", result.Classification.Confidence*100)
-		fmt.Printf("Backend:    %s\n", result.ActualBackend)
+		if model != "" {
+			w := workers.NewOpenAIWorker("quota-check", workers.OpenAIConfig{
+				BaseURL: cfg.Workers.OpenAI.BaseURL,
+				APIKey:  openAIKey(cfg),
+				Model:   model,
+			})
+			fmt.Printf("Checking OpenAI-compatible server (%s)...\n", cfg.Workers.OpenAI.BaseURL)
+			if err = w.CheckQuota(ctx); err != nil {
+				fmt.Printf("⚠ OpenAI-compatible server check failed: %v\n  Disabling OpenAI backends.\n", err)
 
-		if !result.WorkerAvailable {
-			fmt.Println("⚠ Primary worker not available")
-			if result.FallbackBackend != "" {
-				fmt.Printf("  Fallback: %s\n", result.FallbackBackend)
-			} else {
-				fmt.Println("  No fallback available!")
+				cfg.Workers.OpenAI.Enabled = false
 			}
 		}
-
-		if result.ValidationRequired {
-			tierCfg := result.Classification.Tier
-			fmt.Printf("Validation: %d validator(s) required\n", tierCfg+1)
-		} else {
-			fmt.Println("Validation: none (trivial tier)")
-		}
-
-		fmt.Println("───────────────────────────────────────")
-		fmt.Println("[DRY RUN] No execution performed")
-		return nil
 	}
 
-	// Register Ollama workers
-	if cfg.Workers.Ollama.Enabled {
-		for name, model := range cfg.Workers.Ollama.Models {
-			var backend types.Backend
-			switch name {
-			case "fast":
-				backend = types.BackendOllamaFast
-			case "reasoning":
-				backend = types.BackendOllamaReason
-			default:
-				backend = types.BackendOllama
-			}
-
-			worker := workers.NewOllamaWorker(name, workers.OllamaConfig{
-				Endpoint: cfg.Workers.Ollama.Endpoint,
-				Model:    model,
-				Backend:  backend,
-			})
-			cond.RegisterWorker(worker)
+	// Create conductor
+	cond := conductor.NewConductor(cfg, l)
+	if err := registerWorkers(cond, cfg); err != nil {
+		cond.Close()
+		if l != nil {
+			l.Close()
 		}
+		return nil, nil, err
 	}
-
-	// Register Claude workers
-	if cfg.Workers.Claude.Enabled {
-		for name, model := range cfg.Workers.Claude.Models {
-			var backend types.Backend
-			switch name {
-			case "opus":
-				backend = types.BackendClaudeOpus
-			case "haiku":
-				backend = types.BackendClaudeHaiku
-			default:
-				backend = types.BackendClaudeSonnet
-			}
-
-			worker := workers.NewClaudeWorker(name, workers.ClaudeConfig{
-				Model:   model,
-				Backend: backend,
-			})
-			cond.RegisterWorker(worker)
+	cond.SetIndexLoader(func() *repo.Index {
+		idx, err := repo.Scan(cwd)
+		if err != nil {
+			fmt.Printf("⚠ Failed to index the working tree: %v\n", err)
+			return nil
+		}
+		return idx
+	})
+	closeAll := func() {
+		cond.Close()
+		if l != nil {
+			l.Close()
 		}
 	}
 
-	// Register Gemini workers
-	if cfg.Workers.Gemini.Enabled && cfg.Workers.Gemini.APIKey != "" {
-		for name, model := range cfg.Workers.Gemini.Models {
-			var backend types.Backend
-			switch name {
-			case "pro":
-				backend = types.BackendGeminiPro
-			default:
-				backend = types.BackendGeminiFlash
-			}
-
-			worker := workers.NewGeminiWorker(name, workers.GeminiConfig{
-				APIKey:  cfg.Workers.Gemini.APIKey,
-				Model:   model,
-				Backend: backend,
-			})
-			cond.RegisterWorker(worker)
+	if l != nil {
+		orphaned, err := cond.Sweep()
+		if err != nil {
+			fmt.Printf("⚠ %v\n", err)
+		}
+		if len(orphaned) > 0 {
+			fmt.Printf("⚠ %d task(s) orphaned by an interrupted run; use 'bigo resume' to continue them\n", len(orphaned))
 		}
 	}
 
-	// Execute the task
-	fmt.Println("Executing...")
-	fmt.Println()
-
-	result, err := cond.Run(ctx, task, "")
-	if err != nil {
-		return fmt.Errorf("execution failed: %w", err)
-	}
+	return cond, closeAll, nil
+}
 
-	// Display results
+// printResult displays the outcome of a run or resumed task
+func printResult(result *conductor.RunResult) {
 	fmt.Printf("Status:   %s\n", result.Status)
 	fmt.Printf("Backend:  %s\n", result.ActualBackend)
 	fmt.Printf("Duration: %s\n", result.Duration.Round(time.Millisecond))
+	if result.Attempts > 1 {
+		fmt.Printf("Attempts: %d (total cost $%.4f)\n", result.Attempts, result.TotalCostUSD)
+	}
+	if len(result.Escalations) > 0 {
+		fmt.Printf("Escalated: %s\n", joinBackends(result.Escalations))
+	}
 
 	if result.Execution != nil {
-		fmt.Printf("Tokens:   %d\n", result.Execution.TokensUsed)
+		fmt.Printf("Tokens:   %d", result.Execution.TokensUsed)
+		if u := result.Execution.Usage; u.Total() > 0 {
+			fmt.Printf(" (%d in, %d out)", u.Input(), u.OutputTokens)
+		}
+		fmt.Println()
 		fmt.Printf("Cost:     $%.4f\n", result.Execution.CostUSD)
+		if result.Execution.Diff != "" {
+			fmt.Printf("Changes:  %s\n", diffSummary(result.Execution.Diff))
+		}
 		fmt.Println("───────────────────────────────────────")
 		fmt.Println("Output:")
 		fmt.Println(result.Execution.Output)
 	}
 
+	if len(result.Subtasks) > 0 {
+		fmt.Println("───────────────────────────────────────")
+		fmt.Printf("Subtasks (planned by %s):\n", result.PlannerBackend)
+		printSubtasks(result.Subtasks)
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Printf("⚠ %s\n", w)
+	}
+
+	if len(result.ValidationResults) > 0 {
+		fmt.Println("───────────────────────────────────────")
+		fmt.Printf("Validation: %d/%d approvals (%d required)\n",
+			result.Approvals, len(result.ValidationResults), result.RequiredApprovals)
+		for _, v := range result.ValidationResults {
+			verdict := "rejected"
+			if v.Approved {
+				verdict = "approved"
+			}
+			fmt.Printf("  %s: %s\n", v.ValidatorID, verdict)
+			for _, f := range v.Findings {
+				fmt.Printf("    [%s] %s: %s\n", f.Severity, f.Location, strings.ReplaceAll(f.Message, "\n", "\n      "))
+			}
+		}
+	}
+
 	if result.Error != "" {
 		fmt.Println("───────────────────────────────────────")
 		fmt.Printf("Error: %s\n", result.Error)
 	}
 
-	return nil
+}
+
+// joinBackends renders backends as a ladder, cheapest first
+func joinBackends(backends []types.Backend) string {
+	names := make([]string, len(backends))
+	for i, b := range backends {
+		names[i] = string(b)
+	}
+	return strings.Join(names, " → ")
+}
+
+// printSubtasks lists a planned task's subtasks with their routing and outcome
+func printSubtasks(subtasks []*conductor.RunResult) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, r := range subtasks {
+		tier := "-"
+		if r.Classification != nil {
+			tier = fmt.Sprintf("T%d", r.Classification.Tier)
+		}
+		backend := string(r.ActualBackend)
+		if backend == "" {
+			backend = "-"
+		}
+		fmt.Fprintf(tw, "  %d.\t%s\t%s\t%s\t$%.4f\t%s\n", i+1, r.Status, tier, backend, r.TotalCostUSD, truncate(r.Title, 60))
+		if r.Error != "" {
+			fmt.Fprintf(tw, "\t\t\t\t\t↳ %s\n", r.Error)
+		}
+	}
+	tw.Flush()
 }
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cammy/bigo/internal/git"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var showNoOutput bool
+
+var showCmd = &cobra.Command{
+	Use:   "show <task-id>",
+	Short: "Show everything recorded for a task",
+	Long: `Prints a task with every execution attempt, its output, tokens and cost,
+the validators' verdicts and findings, and the task's subtasks.
+A unique prefix of the task ID is enough.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runShow,
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showNoOutput, "no-output", false, "Omit execution output")
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	id, err := db.ResolveTaskID(args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("task %s not found", args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	tree, err := db.GetTaskTree(id)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	task := tree.Task
+
+	execs, err := db.GetExecutions(id)
+	if err != nil {
+		return fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	var tokens int
+	var cost float64
+	for _, e := range execs {
+		tokens += e.TokensUsed
+		cost += e.CostUSD
+	}
+
+	fmt.Printf("Task %s\n", task.ID)
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Printf("Title:    %s\n", task.Title)
+	fmt.Printf("Status:   %s\n", task.Status)
+	fmt.Printf("Tier:     %s (T%d)\n", types.Tier(task.Tier), task.Tier)
+	fmt.Printf("Routed:   %s\n", task.WorkerBackend)
+	if task.ParentID != nil {
+		fmt.Printf("Parent:   %s\n", *task.ParentID)
+	}
+	if task.ContextPath != "" {
+		fmt.Printf("Context:  %s\n", strings.ReplaceAll(task.ContextPath, ",", ", "))
+	}
+	if err := printCommit(db, task.ID); err != nil {
+		return err
+	}
+	fmt.Printf("Created:  %s\n", task.CreatedAt.Local().Format(time.DateTime))
+	fmt.Printf("Updated:  %s\n", task.UpdatedAt.Local().Format(time.DateTime))
+	fmt.Printf("Cost:     $%.4f over %d execution(s), %d tokens\n", cost, len(execs), tokens)
+
+	if task.Description != "" {
+		fmt.Println("───────────────────────────────────────")
+		fmt.Println("Description:")
+		fmt.Println(indent(task.Description, "  "))
+	}
+
+	for _, e := range execs {
+		fmt.Println("───────────────────────────────────────")
+		if err := printExecution(db, e); err != nil {
+			return err
+		}
+	}
+
+	if len(tree.Children) > 0 {
+		fmt.Println("───────────────────────────────────────")
+		fmt.Println("Subtasks:")
+		if err := printTaskTree(db, tree.Children, "  "); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printCommit shows the git commit holding a task's changes, with a link to
+// it when the repository has a hosted origin
+func printCommit(db *ledger.Ledger, id string) error {
+	commit, err := db.GetCommit(id)
+	if err != nil {
+		return fmt.Errorf("failed to load commit: %w", err)
+	}
+	if commit == nil {
+		return nil
+	}
+	fmt.Printf("Commit:   %s on %s", shortSHA(commit.SHA), commit.Branch)
+	if commit.RevertedAt != nil {
+		fmt.Printf(" (reverted %s)", commit.RevertedAt.Local().Format(time.DateTime))
+	}
+	fmt.Println()
+	if cwd, err := os.Getwd(); err == nil {
+		if repo, err := git.Open(cwd); err == nil {
+			if u := git.CommitURL(repo.RemoteURL("origin"), commit.SHA); u != "" {
+				fmt.Printf("          %s\n", u)
+			}
+		}
+	}
+	if commit.Worktree != "" && commit.RevertedAt == nil {
+		fmt.Printf("Worktree: %s\n", commit.Worktree)
+	}
+	return nil
+}
+
+func printExecution(db *ledger.Ledger, e *ledger.Execution) error {
+	label := fmt.Sprintf("Attempt %d", e.Attempt)
+	switch e.Kind {
+	case ledger.KindClassifier:
+		label = "Classification"
+	case ledger.KindPlanner:
+		label = "Plan"
+	case ledger.KindValidator:
+		label = "Review"
+	case ledger.KindAggregate:
+		label = "Combined subtask output"
+	}
+
+	fmt.Printf("%s · %s · %s · %s · %d tokens · $%.4f\n", label, e.Backend, e.Status,
+		(time.Duration(e.DurationMs) * time.Millisecond).Round(time.Millisecond), e.TokensUsed, e.CostUSD)
+	fmt.Printf("  Execution %s at %s\n", e.ID, e.CreatedAt.Local().Format(time.DateTime))
+	if e.WorkerID != "" {
+		fmt.Printf("  Worker: %s\n", e.WorkerID)
+	}
+	if e.InputTokens+e.OutputTokens > 0 {
+		fmt.Printf("  Tokens: %d in, %d out\n", e.InputTokens, e.OutputTokens)
+	}
+	if len(e.ContextFiles) > 0 {
+		fmt.Printf("  Context: %s\n", strings.Join(e.ContextFiles, ", "))
+	}
+	if e.ErrorMsg != "" {
+		fmt.Printf("  Error: %s\n", e.ErrorMsg)
+	}
+	if e.Diff != "" {
+		applied := "not applied"
+		if e.AppliedAt != nil {
+			applied = "applied " + e.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("  Changes: %s, %s\n", diffSummary(e.Diff), applied)
+	}
+	if !showNoOutput && e.Output != "" {
+		fmt.Println("  Output:")
+		fmt.Println(indent(strings.TrimRight(e.Output, "\n"), "    "))
+	}
+
+	validations, err := db.GetValidations(e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load validations: %w", err)
+	}
+	if len(validations) == 0 {
+		return nil
+	}
+
+	approvals := 0
+	for _, v := range validations {
+		if v.Verdict == "approved" {
+			approvals++
+		}
+	}
+	fmt.Printf("  Validation: %d/%d approved\n", approvals, len(validations))
+	for _, v := range validations {
+		fmt.Printf("    %s (%s): %s\n", v.ValidatorID, v.Backend, v.Verdict)
+
+		var findings []types.Finding
+		if err := json.Unmarshal([]byte(v.Findings), &findings); err != nil {
+			fmt.Printf("      (unreadable findings: %v)\n", err)
+			continue
+		}
+		for _, f := range findings {
+			fmt.Printf("      [%s] %s: %s\n", f.Severity, f.Location, strings.ReplaceAll(f.Message, "\n", "\n        "))
+			if f.Suggestion != "" {
+				fmt.Printf("        → %s\n", f.Suggestion)
+			}
+		}
+	}
+	return nil
+}
+
+// printTaskTree lists subtasks with their outcome and cost, nesting their own subtasks
+func printTaskTree(db *ledger.Ledger, nodes []*ledger.TaskNode, prefix string) error {
+	for _, n := range nodes {
+		execs, err := db.GetExecutions(n.Task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load executions: %w", err)
+		}
+		var cost float64
+		backend := n.Task.WorkerBackend
+		for _, e := range execs {
+			cost += e.CostUSD
+			if e.Kind == ledger.KindAttempt {
+				backend = e.Backend
+			}
+		}
+
+		fmt.Printf("%s%s  %-10s T%d  %-16s $%.4f  %s\n", prefix, n.Task.ID, n.Task.Status, n.Task.Tier,
+			backend, cost, truncate(n.Task.Title, 60))
+		if err := printTaskTree(db, n.Children, prefix+"  "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indent prefixes every non-blank line of s
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,17 +3,33 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"time"
 
+	"github.com/cammy/bigo/internal/config"
 	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/pricing"
+	"github.com/cammy/bigo/pkg/types"
 	"github.com/spf13/cobra"
 )
 
+var statusSince string
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show task status and statistics",
-	Long:  `Displays current task queue, execution history, and cost savings.`,
-	RunE:  runStatus,
+	Long: `Displays current task queue, execution history, and cost savings.
+
+Savings compare what each execution cost with what its tier's premium
+backend (the top of the tier's escalation ladder) would have charged for the
+same input and output tokens, using the pricing table in the config.
+
+--since limits everything to tasks and executions created from then on. It
+can be a day (2026-01-31), "today", "yesterday", or an age such as 7d or 12h.`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().StringVar(&statusSince, "since", "", "Only count tasks and executions created at or after this time")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -21,34 +37,109 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get working directory: %w", err)
 	}
+	cfg, err := loadConfig(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-	ledgerPath := filepath.Join(cwd, ".bigo", "ledger.db")
-	if _, statErr := os.Stat(ledgerPath); os.IsNotExist(statErr) {
-		return fmt.Errorf("BigO not initialized. Run 'bigo init' first")
+	opts := ledger.StatsOptions{}
+	if statusSince != "" {
+		if opts.Since, err = parseTimeFlag(statusSince, time.Now()); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
 	}
+	var premium map[int]types.Backend
+	opts.Premium, premium = premiumPrices(cfg)
 
-	db, err := ledger.Open(ledgerPath)
+	db, err := openLedger()
 	if err != nil {
-		return fmt.Errorf("failed to open ledger: %w", err)
+		return err
 	}
 	defer db.Close()
 
-	stats, err := db.GetStats()
+	stats, err := db.GetStats(opts)
 	if err != nil {
 		return fmt.Errorf("failed to get stats: %w", err)
 	}
 
 	fmt.Println("BigO Status")
+	if !opts.Since.IsZero() {
+		fmt.Printf("Since %s\n", opts.Since.Local().Format(time.DateTime))
+	}
 	fmt.Println("═══════════════════════════════════════")
 	fmt.Printf("Tasks:      %d total (%d pending, %d completed)\n",
 		stats.TotalTasks, stats.PendingTasks, stats.CompletedTasks)
+	if stats.OrphanedTasks > 0 {
+		fmt.Printf("Orphaned:   %d (run 'bigo resume' to continue them)\n", stats.OrphanedTasks)
+	}
 	fmt.Printf("Executions: %d total\n", stats.TotalExecutions)
 	fmt.Println("───────────────────────────────────────")
 	fmt.Println("Cost Breakdown:")
 	fmt.Printf("  Claude:   $%.4f (%d tasks)\n", stats.ClaudeCost, stats.ClaudeTasks)
+	if stats.GeminiTasks > 0 {
+		fmt.Printf("  Gemini:   $%.4f (%d tasks)\n", stats.GeminiCost, stats.GeminiTasks)
+	}
 	fmt.Printf("  Ollama:   $%.4f (%d tasks)\n", stats.OllamaCost, stats.OllamaTasks)
-	fmt.Printf("  Savings:  $%.4f (%.1f%%)\n", stats.EstimatedSavings, stats.SavingsPercent)
+	if stats.OpenAITasks > 0 {
+		fmt.Printf("  OpenAI:   $%.4f (%d tasks)\n", stats.OpenAICost, stats.OpenAITasks)
+	}
+	fmt.Printf("  Savings:  $%.4f of $%.4f on premium backends (%.1f%%)\n",
+		stats.EstimatedSavings, stats.PremiumCost, stats.SavingsPercent)
+	printSavings(stats, premium)
+
+	escalations, err := db.GetEscalationStats(opts.Since)
+	if err != nil {
+		return fmt.Errorf("failed to get escalation stats: %w", err)
+	}
+	if len(escalations) > 0 {
+		fmt.Println("───────────────────────────────────────")
+		fmt.Println("Escalation (tasks that outgrew their tier's backend):")
+		for _, e := range escalations {
+			fmt.Printf("  T%d %-9s %d of %d tasks (%.0f%%)", e.Tier, types.Tier(e.Tier), e.Escalated, e.Tasks,
+				float64(e.Escalated)/float64(e.Tasks)*100)
+			if e.Escalated > 0 {
+				fmt.Printf(", %d rescued", e.Rescued)
+			}
+			fmt.Println()
+		}
+	}
 	fmt.Println("═══════════════════════════════════════")
 
 	return nil
 }
+
+// premiumPrices returns, by tier, the price of the tier's premium backend and
+// the backend itself. Tiers whose premium backend has no known price are left
+// out, so they count as saving nothing.
+func premiumPrices(cfg *config.Config) (map[int]pricing.Price, map[int]types.Backend) {
+	prices := make(map[int]pricing.Price)
+	backends := make(map[int]types.Backend)
+	for tier, tc := range cfg.TierConfigs() {
+		backend := tc.PremiumBackend()
+		if price, ok := cfg.BackendPrice(backend); ok {
+			prices[int(tier)] = price
+			backends[int(tier)] = backend
+		}
+	}
+	return prices, backends
+}
+
+// printSavings breaks the savings estimate down by tier and backend
+func printSavings(stats *ledger.Stats, premium map[int]types.Backend) {
+	tier := -1
+	for _, s := range stats.Savings {
+		if s.Tier != tier {
+			tier = s.Tier
+			against := "no priced premium backend"
+			if b, ok := premium[tier]; ok {
+				against = "vs " + string(b)
+			}
+			fmt.Printf("    T%d %-9s %s\n", tier, types.Tier(tier), against)
+		}
+		fmt.Printf("      %-18s %4d runs %8d tokens  $%.4f of $%.4f, saved $%.4f\n",
+			s.Backend, s.Executions, s.InputTokens+s.OutputTokens, s.Cost, s.PremiumCost, s.Savings())
+	}
+	if stats.Unpriced > 0 {
+		fmt.Printf("    %d executions without an input/output token split are not priced\n", stats.Unpriced)
+	}
+}
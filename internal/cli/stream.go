@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// streamPrinter writes worker output from ExecutionChunk events as it
+// arrives. A header names the task, attempt and backend whenever the output
+// switches to another execution, as when subtasks run side by side.
+type streamPrinter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	current string // task ID and attempt of the output being written
+	midLine bool   // the last chunk did not end a line
+}
+
+func newStreamPrinter(out io.Writer) *streamPrinter {
+	return &streamPrinter{out: out}
+}
+
+func (p *streamPrinter) handle(msg types.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch msg.Type {
+	case bus.ExecutionChunk:
+		text, _ := msg.Payload["text"].(string)
+		attempt, _ := msg.Payload["attempt"].(int)
+		backend, _ := msg.Payload["backend"].(string)
+
+		key := fmt.Sprintf("%s/%d", msg.TaskID, attempt)
+		if key != p.current {
+			p.endLine()
+			fmt.Fprintf(p.out, "── Task %s, attempt %d on %s ──\n", msg.TaskID, attempt, backend)
+			p.current = key
+		}
+		fmt.Fprint(p.out, text)
+		p.midLine = !strings.HasSuffix(text, "\n")
+	case bus.ExecutionFinished:
+		attempt, _ := msg.Payload["attempt"].(int)
+		if fmt.Sprintf("%s/%d", msg.TaskID, attempt) == p.current {
+			p.endLine()
+			fmt.Fprintln(p.out)
+			p.current = ""
+		}
+	}
+}
+
+// finish ends any partial line so later output starts cleanly
+func (p *streamPrinter) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.endLine()
+}
+
+func (p *streamPrinter) endLine() {
+	if p.midLine {
+		fmt.Fprintln(p.out)
+		p.midLine = false
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tasksStatus  string
+	tasksTier    string
+	tasksBackend string
+	tasksSince   string
+	tasksUntil   string
+	tasksLimit   int
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks [search text]",
+	Short: "List tasks recorded in the ledger",
+	Long: `Lists past and current tasks, newest first, with the number of executions
+and their total cost. Any arguments are matched against task titles and
+descriptions. Use 'bigo show <id>' to see everything recorded for a task.
+
+Dates for --since and --until can be a day (2026-01-31), "today",
+"yesterday", or an age such as 7d, 2w or 12h.`,
+	RunE: runTasks,
+}
+
+func init() {
+	tasksCmd.Flags().StringVar(&tasksStatus, "status", "", "Only tasks in this status (e.g. done, failed, orphaned)")
+	tasksCmd.Flags().StringVarP(&tasksTier, "tier", "t", "", "Only tasks in this tier (trivial, simple, standard, complex, critical)")
+	tasksCmd.Flags().StringVarP(&tasksBackend, "backend", "b", "", "Only tasks routed to or run on this provider or backend (e.g. ollama, claude:opus)")
+	tasksCmd.Flags().StringVar(&tasksSince, "since", "", "Only tasks created at or after this time")
+	tasksCmd.Flags().StringVar(&tasksUntil, "until", "", "Only tasks created before this time")
+	tasksCmd.Flags().IntVarP(&tasksLimit, "limit", "l", 50, "Maximum number of tasks to list (0 for all)")
+}
+
+func runTasks(cmd *cobra.Command, args []string) error {
+	filter := ledger.TaskFilter{
+		Status:  tasksStatus,
+		Backend: tasksBackend,
+		Search:  strings.Join(args, " "),
+		Limit:   tasksLimit,
+	}
+
+	if tasksTier != "" {
+		tier, err := types.ParseTier(tasksTier)
+		if err != nil {
+			return err
+		}
+		n := int(tier)
+		filter.Tier = &n
+	}
+
+	now := time.Now()
+	var err error
+	if tasksSince != "" {
+		if filter.Since, err = parseTimeFlag(tasksSince, now); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+	}
+	if tasksUntil != "" {
+		if filter.Until, err = parseTimeFlag(tasksUntil, now); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+	}
+
+	db, err := openLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tasks, err := db.ListTasks(filter)
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No matching tasks")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCREATED\tSTATUS\tTIER\tBACKEND\tRUNS\tCOST\tTASK")
+
+	var totalCost float64
+	for _, t := range tasks {
+		title := truncate(t.Title, 60)
+		if t.ParentID != nil {
+			title = "↳ " + title
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\tT%d\t%s\t%d\t$%.4f\t%s\n", t.ID, t.CreatedAt.Local().Format("2006-01-02 15:04"),
+			t.Status, t.Tier, t.WorkerBackend, t.Executions, t.CostUSD, title)
+		totalCost += t.CostUSD
+	}
+	tw.Flush()
+
+	fmt.Println("───────────────────────────────────────")
+	summary := fmt.Sprintf("%d task(s), total cost $%.4f", len(tasks), totalCost)
+	if tasksLimit > 0 && len(tasks) == tasksLimit {
+		summary += fmt.Sprintf(" (showing the newest %d; use --limit to see more)", tasksLimit)
+	}
+	fmt.Println(summary)
+
+	return nil
+}
+
+// openLedger opens the project's ledger for reading
+func openLedger() (*ledger.Ledger, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	ledgerPath := filepath.Join(cwd, ".bigo", "ledger.db")
+	if _, statErr := os.Stat(ledgerPath); os.IsNotExist(statErr) {
+		return nil, fmt.Errorf("BigO not initialized. Run 'bigo init' first")
+	}
+
+	db, err := ledger.Open(ledgerPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	return db, nil
+}
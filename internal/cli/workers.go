@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cammy/bigo/internal/conductor"
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/internal/workers"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// registerWorkers adds every enabled backend to the conductor, creating one
+// worker per max_concurrent slot so the conductor's pools can run them in
+// parallel. It refuses a paid provider's model that has no price, which would
+// count as free and slip past every cost limit.
+func registerWorkers(cond *conductor.Conductor, cfg *config.Config) error {
+	// Register Ollama workers
+	if cfg.Workers.Ollama.Enabled {
+		for name, model := range cfg.Workers.Ollama.Models {
+			var backend types.Backend
+			switch name {
+			case "fast":
+				backend = types.BackendOllamaFast
+			case "reasoning":
+				backend = types.BackendOllamaReason
+			default:
+				backend = types.BackendOllama
+			}
+
+			for i := 1; i <= max(1, cfg.Workers.Ollama.MaxConcurrent); i++ {
+				cond.RegisterWorker(workers.NewOllamaWorker(workerID(name, i), workers.OllamaConfig{
+					Endpoint: cfg.Workers.Ollama.Endpoint,
+					Model:    model,
+					Backend:  backend,
+				}))
+			}
+		}
+	}
+
+	// Register Claude workers
+	if cfg.Workers.Claude.Enabled {
+		for name, model := range cfg.Workers.Claude.Models {
+			var backend types.Backend
+			switch name {
+			case "opus":
+				backend = types.BackendClaudeOpus
+			case "haiku":
+				backend = types.BackendClaudeHaiku
+			default:
+				backend = types.BackendClaudeSonnet
+			}
+
+			for i := 1; i <= max(1, cfg.Workers.Claude.MaxConcurrent); i++ {
+				w := workers.NewClaudeWorker(workerID(name, i), workers.ClaudeConfig{
+					Model:   model,
+					Backend: backend,
+					Pricing: cfg.Pricing,
+				})
+				if !w.Priced() {
+					return unpriced("claude", name, model)
+				}
+				cond.RegisterWorker(w)
+			}
+		}
+	}
+
+	// Register Gemini workers
+	if cfg.Workers.Gemini.Enabled && cfg.Workers.Gemini.APIKey != "" {
+		for name, model := range cfg.Workers.Gemini.Models {
+			var backend types.Backend
+			switch name {
+			case "pro":
+				backend = types.BackendGeminiPro
+			default:
+				backend = types.BackendGeminiFlash
+			}
+
+			for i := 1; i <= max(1, cfg.Workers.Gemini.MaxConcurrent); i++ {
+				w := workers.NewGeminiWorker(workerID(name, i), workers.GeminiConfig{
+					APIKey:  cfg.Workers.Gemini.APIKey,
+					Model:   model,
+					Backend: backend,
+					Pricing: cfg.Pricing,
+				})
+				if !w.Priced() {
+					return unpriced("gemini", name, model)
+				}
+				cond.RegisterWorker(w)
+			}
+		}
+	}
+
+	// Register OpenAI-compatible workers, one backend per model alias
+	if cfg.Workers.OpenAI.Enabled {
+		for name, model := range cfg.Workers.OpenAI.Models {
+			backend := types.Backend("openai:" + name)
+
+			for i := 1; i <= max(1, cfg.Workers.OpenAI.MaxConcurrent); i++ {
+				w := workers.NewOpenAIWorker(workerID(name, i), workers.OpenAIConfig{
+					BaseURL: cfg.Workers.OpenAI.BaseURL,
+					APIKey:  openAIKey(cfg),
+					Model:   model,
+					Backend: backend,
+					Pricing: cfg.Pricing,
+				})
+				if !w.Priced() {
+					return unpriced("openai", name, model)
+				}
+				cond.RegisterWorker(w)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unpriced reports a paid provider's model missing from the pricing table.
+// A model that really is free, such as one on a local OpenAI-compatible
+// server, is given a zero price there.
+func unpriced(provider, name, model string) error {
+	err := fmt.Errorf("workers.%s.models.%s: model %q has no price; add it to pricing in .bigo/config.yaml",
+		provider, name, model)
+	if provider == "openai" {
+		return fmt.Errorf("%w, with %q: {input: 0, output: 0} if the server is free to use", err, model)
+	}
+	return err
+}
+
+// openAIKey reads the API key for OpenAI-compatible servers from the
+// configured environment variable
+func openAIKey(cfg *config.Config) string {
+	if cfg.Workers.OpenAI.APIKeyEnv == "" {
+		return ""
+	}
+	return os.Getenv(cfg.Workers.OpenAI.APIKeyEnv)
+}
+
+func workerID(name string, n int) string {
+	return fmt.Sprintf("%s-%d", name, n)
+}
@@ -0,0 +1,230 @@
+package conductor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/validators"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// classifierTitle is the title of the request sent to the classifier model
+const classifierTitle = "Classify the task below and return a JSON verdict"
+
+// defaultModelConfidence is used when the classifier model does not report its confidence
+const defaultModelConfidence = 0.8
+
+// modelVerdict is the classifier model's structured answer
+type modelVerdict struct {
+	Tier           json.RawMessage `json:"tier"`
+	EstimatedFiles int             `json:"estimated_files"`
+	EstimatedLines int             `json:"estimated_lines"`
+	Confidence     float64         `json:"confidence"`
+	Rationale      string          `json:"rationale"`
+}
+
+// classify runs the pattern classifier and, when its confidence is below the
+// configured threshold, lets the classifier model decide. Model verdicts are
+// cached by normalized task text, so a repeated task costs nothing; with call
+// false only the cache is consulted. The returned execution records the model
+// call for the caller to attach to the task once it exists, and is nil when no
+// call was made. An error means the pattern result stands. The model is not
+// asked about a task whose tier is forced.
+func (c *Conductor) classify(ctx context.Context, title, description string, opts RunOptions, call bool) (*types.ClassificationResult, *ledger.Execution, error) {
+	result := c.classifier.Classify(title, description)
+
+	threshold := c.config.Conductor.ClassifierThreshold
+	model := types.Backend(c.config.Conductor.ClassifierModel)
+	if threshold <= 0 || model == "" || model == "none" || result.Confidence >= threshold || c.ledger == nil || opts.Tier != nil {
+		return result, nil, nil
+	}
+
+	key := classificationKey(title, description)
+	cached, err := c.ledger.GetCachedClassification(key)
+	if err != nil {
+		return result, nil, fmt.Errorf("failed to read classification cache: %w", err)
+	}
+	if cached != nil {
+		c.applyVerdict(result, cached, "cache")
+		return result, nil, nil
+	}
+	if !call {
+		return result, nil, nil
+	}
+
+	// A model whose provider is disabled is not an error; the patterns decide
+	worker, ok := c.worker(model)
+	if !ok {
+		return result, nil, nil
+	}
+	if !worker.Available() {
+		return result, nil, fmt.Errorf("classifier model %s is not available", model)
+	}
+
+	classifyTask := &types.Task{
+		Title:       classifierTitle,
+		Description: buildClassifyPrompt(title, description, result.ScopeEvidence),
+		Tier:        types.TierTrivial,
+		Backend:     model,
+	}
+	execID := generateID()
+	if err := c.reserve(execID, model, estimateCost(worker, classifyTask), 0, opts); err != nil {
+		return result, nil, err
+	}
+
+	start := time.Now()
+	execResult, err := worker.Execute(ctx, classifyTask)
+	if err != nil {
+		c.release(execID)
+		return result, nil, err
+	}
+
+	exec := &ledger.Execution{
+		ID:           execID,
+		WorkerID:     execResult.WorkerID,
+		Kind:         ledger.KindClassifier,
+		Backend:      string(model),
+		Output:       execResult.Output,
+		TokensUsed:   execResult.TokensUsed,
+		InputTokens:  execResult.Usage.Input(),
+		OutputTokens: execResult.Usage.OutputTokens,
+		CostUSD:      execResult.CostUSD,
+		DurationMs:   int(time.Since(start).Milliseconds()),
+		Status:       "completed",
+	}
+	if !execResult.Success {
+		exec.Status = "failed"
+		exec.ErrorMsg = execResult.Error
+		return result, exec, fmt.Errorf("classifier model failed: %s", execResult.Error)
+	}
+
+	v, err := parseVerdict(execResult.Output)
+	if err != nil {
+		return result, exec, err
+	}
+	v.Key = key
+	v.Model = string(model)
+	if err := c.ledger.CacheClassification(v); err != nil {
+		fmt.Printf("failed to cache classification: %v\n", err)
+	}
+
+	c.applyVerdict(result, v, "model")
+	return result, exec, nil
+}
+
+// applyVerdict merges a model verdict into a pattern classification. The
+// model decides the tier; its estimates replace the keyword-based ones.
+func (c *Conductor) applyVerdict(result *types.ClassificationResult, v *ledger.CachedClassification, source string) {
+	result.Tier = types.Tier(v.Tier)
+	if v.EstimatedLines > 0 {
+		result.EstimatedLines = v.EstimatedLines
+	}
+	if v.EstimatedFiles > 0 {
+		result.EstimatedFiles = v.EstimatedFiles
+	}
+	confidence := v.Confidence
+	if confidence <= 0 {
+		confidence = defaultModelConfidence
+	}
+	if confidence > result.Confidence {
+		result.Confidence = confidence
+	}
+	result.RecommendedBackend = c.classifier.recommendBackend(result.Tier)
+	result.Source = source
+
+	result.Reasoning = c.classifier.generateReasoning(result)
+	if v.Rationale != "" {
+		result.Reasoning += fmt.Sprintf(". Model (%s): %s", v.Model, v.Rationale)
+	}
+}
+
+// classificationKey identifies a task by its text, ignoring case and whitespace
+func classificationKey(title, description string) string {
+	text := strings.Join(strings.Fields(strings.ToLower(title+" "+description)), " ")
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseVerdict extracts and checks the classifier model's JSON verdict
+func parseVerdict(output string) (*ledger.CachedClassification, error) {
+	text, err := validators.ExtractJSONObject(output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid classification: %w", err)
+	}
+
+	var v modelVerdict
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return nil, fmt.Errorf("invalid classification JSON: %w", err)
+	}
+	if len(v.Tier) == 0 {
+		return nil, fmt.Errorf("classification has no tier")
+	}
+
+	// Accept 2, "2", "T2" and "standard"
+	tier, err := types.ParseTier(strings.Trim(string(v.Tier), `"`))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tier in classification: %w", err)
+	}
+	if v.EstimatedFiles < 0 || v.EstimatedLines < 0 {
+		return nil, fmt.Errorf("classification has negative estimates")
+	}
+	if v.Confidence < 0 || v.Confidence > 1 {
+		v.Confidence = 0
+	}
+
+	return &ledger.CachedClassification{
+		Tier:           int(tier),
+		EstimatedLines: v.EstimatedLines,
+		EstimatedFiles: v.EstimatedFiles,
+		Confidence:     v.Confidence,
+		Rationale:      strings.TrimSpace(v.Rationale),
+	}, nil
+}
+
+func buildClassifyPrompt(title, description string, evidence []string) string {
+	prompt := fmt.Sprintf(`You are routing a software engineering task to a model that fits its difficulty.
+Classify the task into one of these tiers:
+
+0 trivial:  simple edits, formatting, boilerplate
+1 simple:   straightforward changes following clear patterns
+2 standard: feature work, refactoring, most tasks
+3 complex:  architecture, multi-file changes
+4 critical: security, core logic, breaking changes
+
+## Task
+%s
+
+`, title)
+
+	if description != "" {
+		prompt += fmt.Sprintf(`## Details
+%s
+
+`, description)
+	}
+
+	if len(evidence) > 0 {
+		prompt += "## Files the Task Mentions\n"
+		for _, e := range evidence {
+			prompt += "- " + e + "\n"
+		}
+		prompt += "\n"
+	}
+
+	prompt += `## Instructions
+- Estimate how many files and lines the change will touch
+- Give a one-sentence rationale for the tier
+- Report your confidence between 0 and 1
+- Respond with a single JSON object and nothing else, in this format:
+
+{"tier": 2, "estimated_files": 3, "estimated_lines": 120, "confidence": 0.8, "rationale": "..."}
+`
+
+	return prompt
+}
@@ -0,0 +1,201 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/patch"
+	"github.com/cammy/bigo/internal/repo"
+	"github.com/cammy/bigo/internal/validators"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// attempts carries a task through the execute, validate and retry loop
+type attempts struct {
+	task        *types.Task // What the worker receives; the description gains feedback on retries
+	description string      // The description before any retry feedback
+	worker      Worker
+	tierConfig  types.TierConfig
+	opts        RunOptions
+	findings    []types.Finding // Reviewer findings on the previous attempt
+	escalated   bool            // Whether the task has climbed its tier's escalation ladder
+	sources     []repo.Source   // Files packed into the prompt, most relevant first
+}
+
+// runAttempts executes, validates and retries a task until it is accepted or
+// the retry budget is spent. Each retry climbs one rung of the tier's
+// escalation ladder while there is one. Numbering starts at first, so a
+// resumed task only gets the attempts it has left.
+func (c *Conductor) runAttempts(ctx context.Context, a *attempts, first int, result *RunResult) error {
+	maxAttempts := 1 + max(0, c.config.Conductor.MaxRetries)
+
+	for attempt := first; attempt <= maxAttempts; attempt++ {
+		if result.Execution != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			a.task.Description = buildRetryDescription(a.description, result.Execution, a.findings)
+			c.escalate(a, result)
+			c.packContext(a.task, a.sources)
+		}
+
+		// Hold the estimate until the attempt's cost is recorded, so tasks
+		// running alongside this one see it against the daily limit
+		execID := generateID()
+		if err := c.reserve(execID, a.worker.Backend(), estimateCost(a.worker, a.task), result.TotalCostUSD, a.opts); err != nil {
+			if result.Execution == nil {
+				result.Error = err.Error() + "; rerun with --allow-overspend to proceed anyway"
+				result.Status = types.StatusFailed
+			} else {
+				result.Error = fmt.Sprintf("%s (not retrying after %d attempt(s): %v)", result.Error, result.Attempts, err)
+			}
+			break
+		}
+		result.Attempts = attempt
+
+		if err := c.setStatus(a.task.ID, types.StatusWorking); err != nil {
+			c.release(execID)
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+
+		var contextFiles []string
+		if packed := c.packContext(a.task, a.sources); packed != nil {
+			contextFiles = packed.Labels()
+		}
+
+		c.publish(a.task.ID, bus.ExecutionStarted, map[string]interface{}{
+			"attempt":       attempt,
+			"backend":       string(result.ActualBackend),
+			"context_files": len(contextFiles),
+		})
+		attemptStart := time.Now()
+		execResult, err := c.execute(ctx, a, attempt)
+		if err != nil {
+			execResult = &types.ExecutionResult{
+				TaskID:  a.task.ID,
+				Backend: result.ActualBackend,
+				Success: false,
+				Error:   err.Error(),
+			}
+		}
+		c.extractPatch(execResult)
+		result.Execution = execResult
+		result.TotalCostUSD += execResult.CostUSD
+
+		// Record every attempt, successful or not
+		exec := &ledger.Execution{
+			ID:           execID,
+			TaskID:       a.task.ID,
+			WorkerID:     execResult.WorkerID,
+			Kind:         ledger.KindAttempt,
+			Backend:      string(result.ActualBackend),
+			Output:       execResult.Output,
+			TokensUsed:   execResult.TokensUsed,
+			InputTokens:  execResult.Usage.Input(),
+			OutputTokens: execResult.Usage.OutputTokens,
+			CostUSD:      execResult.CostUSD,
+			DurationMs:   int(time.Since(attemptStart).Milliseconds()),
+			Status:       "completed",
+			Attempt:      attempt,
+			ContextFiles: contextFiles,
+			Diff:         execResult.Diff,
+		}
+		if !execResult.Success {
+			exec.Status = "failed"
+			exec.ErrorMsg = execResult.Error
+		}
+
+		if err := c.recordExecution(exec); err != nil {
+			return fmt.Errorf("failed to record execution: %w", err)
+		}
+		c.publish(a.task.ID, bus.ExecutionFinished, map[string]interface{}{
+			"execution_id":  exec.ID,
+			"attempt":       attempt,
+			"backend":       exec.Backend,
+			"success":       execResult.Success,
+			"tokens":        exec.TokensUsed,
+			"input_tokens":  exec.InputTokens,
+			"output_tokens": exec.OutputTokens,
+			"cost_usd":      exec.CostUSD,
+			"duration_ms":   exec.DurationMs,
+			"error":         exec.ErrorMsg,
+			"files_changed": len(patch.Stats(exec.Diff)),
+		})
+
+		if !execResult.Success {
+			result.Error = execResult.Error
+			result.Status = types.StatusFailed
+			a.findings = nil
+			continue
+		}
+
+		if c.review(ctx, a, exec.ID, execResult, result) {
+			break
+		}
+	}
+
+	if a.escalated && succeeded(result.Status) {
+		tier := c.escalatedTier(a.task.Tier, a.worker.Backend())
+		if err := c.ledger.SetEffectiveTier(a.task.ID, int(tier)); err != nil {
+			fmt.Printf("failed to record effective tier: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// review runs the tier's validation (if any) on an execution and records the
+// outcome on result. It reports whether the task reached a final state; a
+// rejection leaves the findings on a for the next attempt.
+func (c *Conductor) review(ctx context.Context, a *attempts, executionID string, execResult *types.ExecutionResult, result *RunResult) bool {
+	if a.tierConfig.ValidatorCount == 0 && !c.runsTests(execResult) {
+		result.Error = ""
+		result.Status = types.StatusDone
+		return true
+	}
+
+	result.ValidationRequired = true
+	if err := c.setStatus(a.task.ID, types.StatusValidating); err != nil {
+		fmt.Printf("failed to update task status: %v\n", err)
+	}
+
+	verdict, err := c.validate(ctx, a, executionID, execResult, result)
+	if err != nil {
+		// Retrying cannot help when there is nobody, or no budget, to validate
+		result.Error = err.Error()
+		result.Status = types.StatusFailed
+		return true
+	}
+
+	if c.settle(a, verdict, result) {
+		return true
+	}
+	if err := c.ledger.RecordRejection(a.task.ID); err != nil {
+		fmt.Printf("failed to record rejection: %v\n", err)
+	}
+	return false
+}
+
+// settle records a validation verdict on result and reports whether it
+// approved the execution; a rejection leaves the findings on a for the next
+// attempt
+func (c *Conductor) settle(a *attempts, verdict *validators.Verdict, result *RunResult) bool {
+	result.ValidationResults = verdict.Results
+	result.Approvals = verdict.Approvals
+	result.RequiredApprovals = verdict.Required
+	if verdict.Approved {
+		result.Error = ""
+		result.Status = types.StatusApproved
+		return true
+	}
+
+	result.Error = fmt.Sprintf("rejected by validators (%d/%d approvals)", verdict.Approvals, verdict.Required)
+	if verdict.Vetoed {
+		result.Error = fmt.Sprintf("rejected: `%s` failed", c.config.Validators.Tests.Command)
+	}
+	result.Status = types.StatusRejected
+	a.findings = verdict.Findings()
+	return false
+}
@@ -0,0 +1,60 @@
+package conductor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// TaskSpec describes one task submitted as part of a batch
+type TaskSpec struct {
+	Title       string
+	Description string
+}
+
+// RunBatch runs every task concurrently and returns their results in input order.
+// Parallelism per backend is bounded by the worker pools, so tasks routed to a
+// saturated backend wait their turn. Each task is announced with a task.queued
+// event carrying its index in specs before it starts, so subscribers can follow
+// the batch on the bus.
+func (c *Conductor) RunBatch(ctx context.Context, specs []TaskSpec, opts RunOptions) []*RunResult {
+	results := make([]*RunResult, len(specs))
+
+	ids := make([]string, len(specs))
+	for i, spec := range specs {
+		ids[i] = generateID()
+		c.publish(ids[i], bus.TaskQueued, map[string]interface{}{
+			"index": i,
+			"title": spec.Title,
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec TaskSpec) {
+			defer wg.Done()
+
+			taskOpts := opts
+			taskOpts.taskID = ids[i]
+
+			result, err := c.Run(ctx, spec.Title, spec.Description, taskOpts)
+			if err != nil {
+				result = &RunResult{
+					TaskID: ids[i],
+					Status: types.StatusFailed,
+					Error:  err.Error(),
+				}
+				c.publish(ids[i], bus.TaskStatusChanged, map[string]interface{}{
+					"status": string(types.StatusFailed),
+				})
+			}
+			results[i] = result
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,166 @@
+package conductor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// CostEstimator is implemented by workers that can price a task before running it
+type CostEstimator interface {
+	EstimateCost(task *types.Task) float64
+}
+
+// estimateCost returns the worker's up-front estimate, or zero for free backends
+func estimateCost(w Worker, task *types.Task) float64 {
+	if e, ok := w.(CostEstimator); ok {
+		return e.EstimateCost(task)
+	}
+	return 0
+}
+
+// provider returns the provider part of a backend name, e.g. "claude" for "claude:opus"
+func provider(b types.Backend) string {
+	p, _, _ := strings.Cut(string(b), ":")
+	return p
+}
+
+// costLimits returns the spending limits configured for a backend's provider
+func (c *Conductor) costLimits(b types.Backend) config.CostLimits {
+	switch provider(b) {
+	case "claude":
+		return c.config.Workers.Claude.CostLimits
+	case "gemini":
+		return c.config.Workers.Gemini.CostLimits
+	case "openai":
+		return c.config.Workers.OpenAI.CostLimits
+	default:
+		return config.CostLimits{}
+	}
+}
+
+// checkBudget reports whether running the task on the worker would break a cost limit,
+// given what has already been spent on this task
+func (c *Conductor) checkBudget(w Worker, task *types.Task, spentOnTask float64, opts RunOptions) error {
+	return c.checkSpend(w.Backend(), estimateCost(w, task), spentOnTask, opts)
+}
+
+// checkSpend reports whether spending an estimated amount on the backend would
+// break a cost limit, given what has already been spent on the task
+func (c *Conductor) checkSpend(b types.Backend, estimate, spentOnTask float64, opts RunOptions) error {
+	c.holdsMu.Lock()
+	defer c.holdsMu.Unlock()
+	return c.checkSpendLocked(b, estimate, spentOnTask, opts)
+}
+
+// checkSpendLocked is checkSpend for callers holding holdsMu. Today's spend
+// includes the estimates held for calls whose cost is not yet recorded. A
+// subtask is held to its parent's per-task limit, which covers the planner
+// call and every subtask of the plan, running or finished.
+func (c *Conductor) checkSpendLocked(b types.Backend, estimate, spentOnTask float64, opts RunOptions) error {
+	limits := c.costLimits(b)
+
+	if limits.PerTaskUSD > 0 && opts.parentID != "" {
+		spentOnPlan, err := c.ledger.GetPlanSpend(opts.parentID)
+		if err != nil {
+			return fmt.Errorf("failed to read the planned task's spend: %w", err)
+		}
+		for _, h := range c.holds {
+			if h.parentID == opts.parentID {
+				spentOnPlan += h.amount
+			}
+		}
+		if spentOnPlan+estimate > limits.PerTaskUSD {
+			return fmt.Errorf("estimated cost $%.4f on %s exceeds the per-task limit of $%.2f, shared by the planned task's subtasks",
+				spentOnPlan+estimate, b, limits.PerTaskUSD)
+		}
+	} else if limits.PerTaskUSD > 0 && spentOnTask+estimate > limits.PerTaskUSD {
+		return fmt.Errorf("estimated cost $%.4f on %s exceeds the per-task limit of $%.2f",
+			spentOnTask+estimate, b, limits.PerTaskUSD)
+	}
+
+	if limits.DailyUSD > 0 {
+		spentToday, err := c.ledger.GetSpendSince(startOfDay(time.Now()), provider(b)+":")
+		if err != nil {
+			return fmt.Errorf("failed to read today's spend: %w", err)
+		}
+		for _, h := range c.holds {
+			if h.provider == provider(b) {
+				spentToday += h.amount
+			}
+		}
+		if spentToday+estimate > limits.DailyUSD {
+			return fmt.Errorf("%s daily limit of $%.2f would be exceeded ($%.4f spent or under way today, $%.4f estimated)",
+				provider(b), limits.DailyUSD, spentToday, estimate)
+		}
+	}
+
+	return nil
+}
+
+// hold is spend committed to a call whose real cost is not yet in the ledger
+type hold struct {
+	provider string
+	parentID string // The planned task a subtask's spend counts towards, if any
+	amount   float64
+}
+
+// reserve holds an estimate against the backend's provider until release is
+// called with the same ID, normally that of the execution which will record
+// the real cost. Concurrent tasks see each other's holds, so they cannot all
+// pass the daily limit, or a plan's subtasks its per-task limit, on the same
+// ledger total. Unless opts allow overspending, the limits are checked
+// atomically with taking the hold.
+func (c *Conductor) reserve(id string, b types.Backend, estimate, spentOnTask float64, opts RunOptions) error {
+	c.holdsMu.Lock()
+	defer c.holdsMu.Unlock()
+
+	if !opts.AllowOverspend {
+		if err := c.checkSpendLocked(b, estimate, spentOnTask, opts); err != nil {
+			return err
+		}
+	}
+	c.holds[id] = hold{provider: provider(b), parentID: opts.parentID, amount: estimate}
+	return nil
+}
+
+// release drops the hold taken under an ID, if there is one
+func (c *Conductor) release(id string) {
+	c.holdsMu.Lock()
+	defer c.holdsMu.Unlock()
+	delete(c.holds, id)
+}
+
+// recordExecution writes an execution to the ledger and releases the hold
+// taken for it, as its real cost now counts towards today's spend
+func (c *Conductor) recordExecution(exec *ledger.Execution) error {
+	defer c.release(exec.ID)
+	return c.ledger.CreateExecution(exec)
+}
+
+// findAffordableWorker returns a cheaper fallback for the tier that stays within budget
+func (c *Conductor) findAffordableWorker(tier types.Tier, task *types.Task, current Worker) Worker {
+	currentEstimate := estimateCost(current, task)
+
+	for _, w := range c.fallbackWorkers(tier) {
+		if w.Backend() == current.Backend() {
+			continue
+		}
+		if estimateCost(w, task) >= currentEstimate {
+			continue
+		}
+		if c.checkBudget(w, task, 0, RunOptions{}) == nil {
+			return w
+		}
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,156 @@
+package conductor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+const (
+	// MinCalibrationSamples is how many outcomes a pattern needs before a new weight is proposed
+	MinCalibrationSamples = 5
+
+	// minWeightChange keeps calibration from proposing changes too small to matter
+	minWeightChange = 0.05
+)
+
+// recordPrediction stores the classifier's tier and every matching pattern,
+// so calibration can later compare them with the task's outcome
+func (c *Conductor) recordPrediction(taskID, title, description string, tier types.Tier) {
+	var hits []ledger.PatternHit
+	for _, m := range c.classifier.Explain(title, description).Matches {
+		hits = append(hits, ledger.PatternHit{Tier: int(m.Tier), Name: m.Pattern.Name})
+	}
+	if err := c.ledger.RecordPrediction(taskID, int(tier), hits); err != nil {
+		fmt.Printf("failed to record classification: %v\n", err)
+	}
+}
+
+// EffectiveTier judges which tier a task really needed. A tier forced by the
+// user wins, then the tier an escalated task succeeded on. A task that
+// succeeded at once confirms its prediction; one that failed or was rejected
+// needed at least one tier more. Tasks that never ran tell nothing.
+func EffectiveTier(o *ledger.ClassificationOutcome) (types.Tier, bool) {
+	switch {
+	case o.ForcedTier != nil:
+		return types.Tier(*o.ForcedTier), true
+	case o.EffectiveTier != nil:
+		return types.Tier(*o.EffectiveTier), true
+	case o.Attempts == 0:
+		return 0, false
+	}
+
+	predicted := types.Tier(o.PredictedTier)
+	switch types.TaskStatus(o.Status) {
+	case types.StatusDone, types.StatusApproved, types.StatusFailed, types.StatusRejected:
+	default:
+		// Still running or orphaned
+		return 0, false
+	}
+	if succeeded(types.TaskStatus(o.Status)) && o.Rejections == 0 && o.FailedAttempts == 0 {
+		return predicted, true
+	}
+	if predicted < types.TierCritical {
+		return predicted + 1, true
+	}
+	return predicted, true
+}
+
+// Calibration compares the classifier's predictions with task outcomes
+type Calibration struct {
+	Outcomes int // Tasks whose effective tier is known
+
+	// Confusion counts tasks by [predicted][effective] tier
+	Confusion [types.TierCritical + 1][types.TierCritical + 1]int
+	Patterns  []*PatternStats
+}
+
+// Accuracy is the share of tasks whose predicted tier was the effective one
+func (c *Calibration) Accuracy() float64 {
+	if c.Outcomes == 0 {
+		return 0
+	}
+	correct := 0
+	for t := range c.Confusion {
+		correct += c.Confusion[t][t]
+	}
+	return float64(correct) / float64(c.Outcomes)
+}
+
+// PatternStats is how often a pattern's tier turned out to be the effective one
+type PatternStats struct {
+	Tier     types.Tier
+	Name     string
+	Matched  int
+	Correct  int
+	Weight   float64 // Current weight; 0 if the pattern no longer exists
+	Exists   bool
+	Proposed float64 // Proposed weight, or 0 when there is no proposal
+}
+
+// Precision is the share of tasks matching the pattern whose effective tier was the pattern's tier
+func (s *PatternStats) Precision() float64 {
+	if s.Matched == 0 {
+		return 0
+	}
+	return float64(s.Correct) / float64(s.Matched)
+}
+
+// Calibrate scores the classifier's patterns against the outcomes of past
+// tasks and proposes new weights for patterns with enough samples: a
+// pattern right half the time keeps its weight, one always right gains
+// half again, and one never right loses half.
+func (c *Classifier) Calibrate(outcomes []*ledger.ClassificationOutcome) *Calibration {
+	cal := &Calibration{}
+	stats := make(map[ledger.PatternHit]*PatternStats)
+
+	for _, o := range outcomes {
+		effective, ok := EffectiveTier(o)
+		if !ok || o.PredictedTier < int(types.TierTrivial) || o.PredictedTier > int(types.TierCritical) {
+			continue
+		}
+		cal.Outcomes++
+		cal.Confusion[o.PredictedTier][effective]++
+
+		for _, hit := range o.Patterns {
+			s, ok := stats[hit]
+			if !ok {
+				s = &PatternStats{Tier: types.Tier(hit.Tier), Name: hit.Name}
+				stats[hit] = s
+			}
+			s.Matched++
+			if types.Tier(hit.Tier) == effective {
+				s.Correct++
+			}
+		}
+	}
+
+	for _, s := range stats {
+		for _, p := range c.patterns[s.Tier] {
+			if p.Name == s.Name {
+				s.Weight = p.Weight
+				s.Exists = true
+				break
+			}
+		}
+		if s.Exists && s.Matched >= MinCalibrationSamples {
+			proposed := math.Round(math.Max(0.05, math.Min(1, s.Weight*(0.5+s.Precision())))*100) / 100
+			if math.Abs(proposed-s.Weight) >= minWeightChange {
+				s.Proposed = proposed
+			}
+		}
+		cal.Patterns = append(cal.Patterns, s)
+	}
+
+	sort.Slice(cal.Patterns, func(i, j int) bool {
+		a, b := cal.Patterns[i], cal.Patterns[j]
+		if a.Tier != b.Tier {
+			return a.Tier < b.Tier
+		}
+		return a.Name < b.Name
+	})
+	return cal
+}
@@ -1,16 +1,22 @@
 package conductor
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/internal/repo"
 	"github.com/cammy/bigo/pkg/types"
 )
 
 // Classifier determines task complexity and routes to appropriate backends
 type Classifier struct {
 	patterns map[types.Tier][]Pattern
+	routing  map[types.Tier]types.TierConfig
+	index    func() *repo.Index // Loads the working tree the scope of tasks is measured against; may be nil
 }
 
 // Pattern represents a classification pattern
@@ -18,17 +24,116 @@ type Pattern struct {
 	Name   string
 	Regex  *regexp.Regexp
 	Weight float64
+	Custom bool // Added or changed by the classifier.patterns config
 }
 
 // NewClassifier creates a new task classifier with default patterns
 func NewClassifier() *Classifier {
 	c := &Classifier{
 		patterns: make(map[types.Tier][]Pattern),
+		routing:  types.DefaultTierConfigs(),
 	}
 	c.initPatterns()
 	return c
 }
 
+// SetRouting replaces the tier routing used to recommend backends
+func (c *Classifier) SetRouting(routing map[types.Tier]types.TierConfig) {
+	c.routing = routing
+}
+
+// ApplyPatterns adds, reweights or disables patterns per tier. A rule naming
+// a pattern of its tier changes it; any other rule adds a pattern. Rules are
+// checked before any is applied, so an error leaves the patterns unchanged.
+func (c *Classifier) ApplyPatterns(rules config.PatternsConfig) error {
+	patterns := make(map[types.Tier][]Pattern, len(c.patterns))
+	for tier, list := range c.patterns {
+		patterns[tier] = append([]Pattern(nil), list...)
+	}
+
+	// Apply tiers in order so errors are reported consistently
+	for tier := types.TierTrivial; tier <= types.TierCritical; tier++ {
+		for name, tierRules := range rules {
+			if t, err := types.ParseTier(name); err != nil {
+				return fmt.Errorf("patterns: %w", err)
+			} else if t != tier {
+				continue
+			}
+
+			for _, rule := range tierRules {
+				list, err := applyPatternRule(patterns[tier], rule)
+				if err != nil {
+					return fmt.Errorf("patterns.%s.%s: %w", name, rule.Name, err)
+				}
+				patterns[tier] = list
+			}
+		}
+	}
+
+	c.patterns = patterns
+	return nil
+}
+
+func applyPatternRule(list []Pattern, rule config.PatternRule) ([]Pattern, error) {
+	for i, p := range list {
+		if p.Name != rule.Name {
+			continue
+		}
+		if rule.Disabled {
+			return append(list[:i:i], list[i+1:]...), nil
+		}
+		if rule.Regex != "" {
+			re, err := config.CompilePattern(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex: %w", err)
+			}
+			p.Regex = re
+		}
+		if rule.Weight != nil {
+			p.Weight = *rule.Weight
+		}
+		p.Custom = true
+		list[i] = p
+		return list, nil
+	}
+
+	if rule.Disabled {
+		return nil, fmt.Errorf("no such pattern to disable")
+	}
+	if rule.Regex == "" {
+		return nil, fmt.Errorf("regex is required for a new pattern")
+	}
+	re, err := config.CompilePattern(rule.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	weight := config.DefaultPatternWeight
+	if rule.Weight != nil {
+		weight = *rule.Weight
+	}
+	return append(list, Pattern{Name: rule.Name, Regex: re, Weight: weight, Custom: true}), nil
+}
+
+// SetIndex lets the classifier measure the scope of tasks against a working tree
+func (c *Classifier) SetIndex(idx *repo.Index) {
+	c.index = func() *repo.Index { return idx }
+}
+
+// SetIndexLoader is SetIndex for a working tree that is only scanned when
+// first needed. load is called once; it returns nil if the scan failed.
+func (c *Classifier) SetIndexLoader(load func() *repo.Index) {
+	c.index = sync.OnceValue(load)
+}
+
+// Index returns the working tree's index, loading it on first use, or nil
+// without one
+func (c *Classifier) Index() *repo.Index {
+	if c.index == nil {
+		return nil
+	}
+	return c.index()
+}
+
 func (c *Classifier) initPatterns() {
 	// TRIVIAL patterns - simple edits, formatting, typos
 	c.patterns[types.TierTrivial] = []Pattern{
@@ -89,28 +194,19 @@ func (c *Classifier) Classify(title, description string) *types.ClassificationRe
 		Tier:       types.TierStandard, // Default
 		Confidence: 0.5,
 		Patterns:   []string{},
+		Source:     "patterns",
 	}
 
-	// Score each tier
-	scores := make(map[types.Tier]float64)
-	matchedPatterns := make(map[types.Tier][]string)
-
-	for tier, patterns := range c.patterns {
-		for _, p := range patterns {
-			if p.Regex.MatchString(text) {
-				scores[tier] += p.Weight
-				matchedPatterns[tier] = append(matchedPatterns[tier], p.Name)
-			}
-		}
-	}
-
-	// Find highest scoring tier
+	// Score each tier and take the highest scoring one
+	explanation := c.score(text)
 	maxScore := 0.0
-	for tier, score := range scores {
-		if score > maxScore {
-			maxScore = score
-			result.Tier = tier
-			result.Patterns = matchedPatterns[tier]
+	if explanation.Matched {
+		result.Tier = explanation.PatternTier
+		maxScore = explanation.Scores[result.Tier]
+		for _, m := range explanation.Matches {
+			if m.Tier == result.Tier {
+				result.Patterns = append(result.Patterns, m.Pattern.Name)
+			}
 		}
 	}
 
@@ -119,9 +215,11 @@ func (c *Classifier) Classify(title, description string) *types.ClassificationRe
 		result.Confidence = min(0.95, 0.5+(maxScore*0.15))
 	}
 
-	// Estimate scope from description
-	result.EstimatedLines = c.estimateLines(text)
-	result.EstimatedFiles = c.estimateFiles(text)
+	// Estimate scope from the files the task mentions, or failing that from its wording
+	if !c.repoScope(title+"\n"+description, result) {
+		result.EstimatedLines = c.estimateLines(text)
+		result.EstimatedFiles = c.estimateFiles(text)
+	}
 
 	// Adjust tier based on scope
 	result.Tier = c.adjustTierByScope(result.Tier, result.EstimatedLines, result.EstimatedFiles)
@@ -135,6 +233,134 @@ func (c *Classifier) Classify(title, description string) *types.ClassificationRe
 	return result
 }
 
+const (
+	// maxLinesPerFile caps the lines attributed to a file mentioned as a whole;
+	// a task naming a large file rarely rewrites all of it
+	maxLinesPerFile = 100
+
+	// linesPerDependent is the edit expected in each file using a changed interface
+	linesPerDependent = 3
+
+	// maxScopeEvidence caps the files listed in the reasoning
+	maxScopeEvidence = 5
+)
+
+// interfaceChange matches tasks that break the callers of what they change
+var interfaceChange = regexp.MustCompile(`(?i)\b(rename|remove|delete|move|replace|deprecate|signature)`)
+
+// repoScope estimates the lines and files a task touches from the files,
+// packages and identifiers it mentions in the working tree. It reports
+// false when there is no index or the task mentions nothing in it.
+func (c *Classifier) repoScope(text string, result *types.ClassificationResult) bool {
+	idx := c.Index()
+	if idx == nil {
+		return false
+	}
+	mentions := idx.Mentions(text)
+	if len(mentions) == 0 {
+		return false
+	}
+
+	// Callers only need edits when the change breaks them
+	breaking := interfaceChange.MatchString(text)
+
+	// A file whose declarations are named is measured by them, not as a whole
+	named := make(map[string]bool)
+	for _, m := range mentions {
+		if m.Decl != nil {
+			named[m.File.Path] = true
+		}
+	}
+
+	lines := 0
+	dependents := 0
+	var evidence []string
+	for _, m := range mentions {
+		if !contains(result.RelatedFiles, m.File.Path) {
+			result.RelatedFiles = append(result.RelatedFiles, m.File.Path)
+		}
+
+		if m.Decl != nil {
+			lines += m.Decl.Lines()
+			if breaking {
+				dependents += m.Dependents
+				lines += m.Dependents * linesPerDependent
+			}
+			evidence = append(evidence, fmt.Sprintf("%s (%s, %d lines, %d dependents)", m.File.Path, m.Via, m.Decl.Lines(), m.Dependents))
+		} else if !named[m.File.Path] {
+			share := m.File.Lines / 10
+			if share > maxLinesPerFile {
+				share = maxLinesPerFile
+			}
+			lines += max(1, share)
+			evidence = append(evidence, fmt.Sprintf("%s (%d lines, %d dependents)", m.File.Path, m.File.Lines, m.Dependents))
+		}
+	}
+	if len(evidence) > maxScopeEvidence {
+		evidence = append(evidence[:maxScopeEvidence], fmt.Sprintf("%d more", len(evidence)-maxScopeEvidence))
+	}
+	result.ScopeEvidence = evidence
+
+	result.EstimatedLines = lines
+	result.EstimatedFiles = len(result.RelatedFiles) + dependents
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Explanation breaks the pattern scoring of a task down by pattern
+type Explanation struct {
+	Matches     []PatternMatch // Matching patterns, by tier
+	Scores      map[types.Tier]float64
+	Matched     bool       // Whether any pattern matched
+	PatternTier types.Tier // Highest scoring tier, before scope adjustment
+}
+
+// PatternMatch is a pattern that matched a task and the tier it scored for
+type PatternMatch struct {
+	Tier    types.Tier
+	Pattern Pattern
+}
+
+// Explain reports how each pattern contributed to the tier scores of a task
+func (c *Classifier) Explain(title, description string) *Explanation {
+	return c.score(strings.ToLower(title + " " + description))
+}
+
+func (c *Classifier) score(text string) *Explanation {
+	e := &Explanation{
+		Scores:      make(map[types.Tier]float64),
+		PatternTier: types.TierStandard,
+	}
+
+	for tier := types.TierTrivial; tier <= types.TierCritical; tier++ {
+		for _, p := range c.patterns[tier] {
+			if p.Regex.MatchString(text) {
+				e.Scores[tier] += p.Weight
+				e.Matches = append(e.Matches, PatternMatch{Tier: tier, Pattern: p})
+			}
+		}
+	}
+
+	// Ties go to the lower tier
+	maxScore := 0.0
+	for tier := types.TierTrivial; tier <= types.TierCritical; tier++ {
+		if score := e.Scores[tier]; score > maxScore {
+			maxScore = score
+			e.PatternTier = tier
+			e.Matched = true
+		}
+	}
+	return e
+}
+
 func (c *Classifier) estimateLines(text string) int {
 	// Heuristics based on task description
 	if strings.Contains(text, "single line") || strings.Contains(text, "one line") {
@@ -195,8 +421,7 @@ func (c *Classifier) adjustTierByScope(tier types.Tier, lines, files int) types.
 }
 
 func (c *Classifier) recommendBackend(tier types.Tier) types.Backend {
-	configs := types.DefaultTierConfigs()
-	if cfg, ok := configs[tier]; ok {
+	if cfg, ok := c.routing[tier]; ok {
 		return cfg.PrimaryBackend
 	}
 	return types.BackendClaudeSonnet
@@ -214,6 +439,10 @@ func (c *Classifier) generateReasoning(result *types.ClassificationResult) strin
 	parts = append(parts,
 		"Estimated scope: ~"+itoa(result.EstimatedLines)+" lines across "+itoa(result.EstimatedFiles)+" file(s)")
 
+	if len(result.ScopeEvidence) > 0 {
+		parts = append(parts, "From the repository: "+strings.Join(result.ScopeEvidence, "; "))
+	}
+
 	return strings.Join(parts, ". ")
 }
 
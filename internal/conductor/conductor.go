@@ -5,10 +5,15 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/cammy/bigo/internal/bus"
 	"github.com/cammy/bigo/internal/config"
 	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/repo"
+	"github.com/cammy/bigo/internal/workers"
 	"github.com/cammy/bigo/pkg/types"
 )
 
@@ -17,7 +22,13 @@ type Conductor struct {
 	config     *config.Config
 	ledger     *ledger.Ledger
 	classifier *Classifier
-	workers    map[types.Backend]Worker
+	tiers      map[types.Tier]types.TierConfig
+	pools      map[types.Backend]*workers.Pool
+	limits     map[string]*workers.Limit // Shared by the pools of each provider's models
+	bus        *bus.Bus
+
+	holdsMu sync.Mutex
+	holds   map[string]hold // Estimated spend on calls still running, by execution ID
 }
 
 // Worker interface for different backends
@@ -30,214 +41,397 @@ type Worker interface {
 
 // NewConductor creates a new conductor instance
 func NewConductor(cfg *config.Config, l *ledger.Ledger) *Conductor {
-	return &Conductor{
+	tiers := cfg.TierConfigs()
+
+	classifier := NewClassifier()
+	classifier.SetRouting(tiers)
+	if err := classifier.ApplyPatterns(cfg.Classifier.Patterns); err != nil {
+		fmt.Printf("⚠ Ignoring classifier patterns: %v\n", err)
+	}
+
+	c := &Conductor{
 		config:     cfg,
 		ledger:     l,
-		classifier: NewClassifier(),
-		workers:    make(map[types.Backend]Worker),
+		classifier: classifier,
+		tiers:      tiers,
+		pools:      make(map[types.Backend]*workers.Pool),
+		limits:     make(map[string]*workers.Limit),
+		bus:        bus.New(cfg.Bus.BufferSize),
+		holds:      make(map[string]hold),
+	}
+	if l != nil {
+		c.bus.Subscribe(c.recordEvent)
 	}
+	return c
 }
 
-// RegisterWorker adds a worker backend to the conductor
+// RegisterWorker adds a worker to its backend's pool. Register one worker per
+// unit of concurrency; the pool holds at most the provider's max_concurrent,
+// and the pools of a provider's models share that limit between them.
 func (c *Conductor) RegisterWorker(w Worker) {
-	c.workers[w.Backend()] = w
+	pool, ok := c.pools[w.Backend()]
+	if !ok {
+		pool = workers.NewPool(w.Backend(), c.maxConcurrent(w.Backend()))
+		limit, ok := c.limits[provider(w.Backend())]
+		if !ok {
+			limit = workers.NewLimit(c.maxConcurrent(w.Backend()))
+			c.limits[provider(w.Backend())] = limit
+		}
+		pool.SetLimit(limit)
+		c.pools[w.Backend()] = pool
+	}
+	pool.Add(w)
+}
+
+// SetIndex lets the classifier measure the scope of tasks against the project's working tree
+func (c *Conductor) SetIndex(idx *repo.Index) {
+	c.classifier.SetIndex(idx)
+}
+
+// SetIndexLoader is SetIndex for a working tree that is only scanned once a
+// task needs it, so commands that run no task skip the scan
+func (c *Conductor) SetIndexLoader(load func() *repo.Index) {
+	c.classifier.SetIndexLoader(load)
+}
+
+// worker returns a handle that runs tasks on the backend's pool, queueing when it is saturated
+func (c *Conductor) worker(b types.Backend) (Worker, bool) {
+	pool, ok := c.pools[b]
+	if !ok {
+		return nil, false
+	}
+	return &pooledWorker{pool: pool}, true
+}
+
+// maxConcurrent returns the configured concurrency limit for a backend's provider
+func (c *Conductor) maxConcurrent(b types.Backend) int {
+	switch provider(b) {
+	case "claude":
+		return c.config.Workers.Claude.MaxConcurrent
+	case "ollama":
+		return c.config.Workers.Ollama.MaxConcurrent
+	case "gemini":
+		return c.config.Workers.Gemini.MaxConcurrent
+	case "openai":
+		return c.config.Workers.OpenAI.MaxConcurrent
+	default:
+		return 1
+	}
+}
+
+// RunOptions tunes a single Run invocation
+type RunOptions struct {
+	// AllowOverspend runs the task even when it would break a cost limit
+	AllowOverspend bool
+
+	// Tier, when set, overrides the classifier's tier. The classifier's own
+	// prediction is still recorded, and the override is kept in the ledger.
+	// Subtasks of a planned task are classified on their own.
+	Tier *types.Tier
+
+	// Context lists files, directories or globs, relative to the working
+	// tree, whose contents are packed into the prompt ahead of the files the
+	// task mentions
+	Context []string
+
+	// Stream asks workers that support it for their output as it is
+	// generated, published as ExecutionChunk events
+	Stream bool
+
+	// taskID is assigned in advance by RunBatch so events can be matched to its tasks
+	taskID string
+
+	// parentID is set for subtasks of a planned task, which are never planned themselves
+	parentID string
+
+	// background is appended to the description sent to workers and validators
+	// but kept out of classification, so a subtask is routed on its own merits
+	background string
 }
 
 // Run executes a task through the full pipeline
-func (c *Conductor) Run(ctx context.Context, title, description string) (*RunResult, error) {
-	// Step 1: Classify
-	classification := c.classifier.Classify(title, description)
+func (c *Conductor) Run(ctx context.Context, title, description string, opts RunOptions) (*RunResult, error) {
+	// Step 1: Classify, asking the classifier model when patterns are inconclusive
+	classification, classifyExec, classifyErr := c.classify(ctx, title, description, opts, true)
+	if classifyExec != nil {
+		// Released when the call is recorded, unless the run stops first
+		defer c.release(classifyExec.ID)
+	}
+	predicted := classification.Tier
+	if opts.Tier != nil {
+		c.forceTier(classification, *opts.Tier)
+	}
+
+	sources, err := c.contextSources(title, description, opts.Context)
+	if err != nil {
+		return nil, err
+	}
 
 	// Step 2: Create task in ledger
+	taskID := opts.taskID
+	if taskID == "" {
+		taskID = generateID()
+	}
+
 	task := &ledger.Task{
-		ID:            generateID(),
+		ID:            taskID,
+		ParentID:      optionalString(opts.parentID),
 		Title:         title,
 		Description:   description,
 		Tier:          int(classification.Tier),
 		Status:        string(types.StatusPending),
 		WorkerBackend: string(classification.RecommendedBackend),
+		ContextPath:   strings.Join(opts.Context, ","),
 	}
 
 	if err := c.ledger.CreateTask(task); err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
+	c.recordPrediction(task.ID, title, description, predicted)
+	if opts.Tier != nil {
+		if err := c.ledger.SetForcedTier(task.ID, int(*opts.Tier)); err != nil {
+			fmt.Printf("failed to record forced tier: %v\n", err)
+		}
+	}
+	created := map[string]interface{}{"title": title}
+	if opts.parentID != "" {
+		created["parent_id"] = opts.parentID
+	}
+	c.publish(task.ID, bus.TaskCreated, created)
+	defer c.startHeartbeat(task.ID)()
+
+	// In flight from here on, so the sweep finds the task if this process dies
+	if err := c.setStatus(task.ID, types.StatusAssigned); err != nil {
+		return nil, fmt.Errorf("failed to update task status: %w", err)
+	}
+	c.publish(task.ID, bus.TaskClassified, map[string]interface{}{
+		"tier":       int(classification.Tier),
+		"confidence": classification.Confidence,
+		"backend":    string(classification.RecommendedBackend),
+		"patterns":   classification.Patterns,
+		"source":     classification.Source,
+	})
 
 	result := &RunResult{
 		TaskID:         task.ID,
+		Title:          title,
+		ParentID:       opts.parentID,
 		Classification: classification,
 		StartTime:      time.Now(),
 	}
+	if classifyExec != nil {
+		classifyExec.TaskID = task.ID
+		result.TotalCostUSD += classifyExec.CostUSD
+		if err := c.recordExecution(classifyExec); err != nil {
+			return nil, fmt.Errorf("failed to record classification: %w", err)
+		}
+	}
+	if classifyErr != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("model classification skipped: %v", classifyErr))
+	}
 
-	// Step 3: Find available worker
-	worker, ok := c.workers[classification.RecommendedBackend]
+	// Step 3: Split complex tasks into independently routed subtasks
+	tierConfig := c.tiers[classification.Tier]
+	if tierConfig.PlannerBackend != "" && opts.parentID == "" {
+		parent := &types.Task{
+			ID:          task.ID,
+			Title:       title,
+			Description: description,
+			Tier:        classification.Tier,
+		}
+		planned, err := c.runPlan(ctx, parent, tierConfig.PlannerBackend, opts, result)
+		if err != nil {
+			return nil, err
+		}
+		if planned {
+			return c.finish(result), nil
+		}
+	}
+
+	// Step 4: Find available worker
+	worker, ok := c.worker(classification.RecommendedBackend)
 	if !ok || !worker.Available() {
 		// Try fallback backends
 		worker = c.findFallbackWorker(classification.Tier)
 		if worker == nil {
 			result.Error = "no available worker for this task tier"
 			result.Status = types.StatusFailed
-			return result, nil
+			return c.finish(result), nil
 		}
 		result.ActualBackend = worker.Backend()
 	} else {
 		result.ActualBackend = classification.RecommendedBackend
 	}
 
-	// Step 4: Update status and execute
-	if err := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusWorking)); err != nil {
-		return nil, fmt.Errorf("failed to update task status: %w", err)
+	// Workers and validators see the background; the ledger keeps the task as given
+	if opts.background != "" {
+		description = strings.TrimSpace(description + "\n\n" + opts.background)
 	}
 
-	execResult, err := worker.Execute(ctx, &types.Task{
+	attemptTask := &types.Task{
 		ID:          task.ID,
 		Title:       title,
 		Description: description,
 		Tier:        classification.Tier,
 		Backend:     result.ActualBackend,
-	})
-
-	if err != nil {
-		result.Error = err.Error()
-		result.Status = types.StatusFailed
-		if updateErr := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusFailed)); updateErr != nil {
-			fmt.Printf("failed to update task status: %v\n", updateErr)
+	}
+	c.packContext(attemptTask, sources)
+
+	// Step 5: Enforce cost limits, downgrading to a cheaper backend if possible
+	result.EstimatedCostUSD = estimateCost(worker, attemptTask)
+	if err := c.checkBudget(worker, attemptTask, result.TotalCostUSD, opts); err != nil {
+		switch cheaper := c.findAffordableWorker(classification.Tier, attemptTask, worker); {
+		case opts.AllowOverspend:
+			result.Warnings = append(result.Warnings, err.Error()+" (overspend allowed)")
+		case cheaper != nil:
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s; downgraded from %s to %s", err, worker.Backend(), cheaper.Backend()))
+			worker = cheaper
+			result.ActualBackend = cheaper.Backend()
+			attemptTask.Backend = cheaper.Backend()
+			c.packContext(attemptTask, sources)
+			result.EstimatedCostUSD = estimateCost(worker, attemptTask)
+		default:
+			result.Error = err.Error() + "; rerun with --allow-overspend to proceed anyway"
+			result.Status = types.StatusFailed
+			return c.finish(result), nil
 		}
-		return result, nil
 	}
 
-	result.Execution = execResult
+	c.publish(task.ID, bus.TaskAssigned, map[string]interface{}{
+		"backend":  string(result.ActualBackend),
+		"fallback": result.ActualBackend != classification.RecommendedBackend,
+	})
 
-	// Check if execution reported failure
-	if !execResult.Success {
-		result.Error = execResult.Error
-		result.Status = types.StatusFailed
-		if updateErr := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusFailed)); updateErr != nil {
-			fmt.Printf("failed to update task status: %v\n", updateErr)
-		}
-		return result, nil
+	// Step 6: Execute, validate and retry with feedback until accepted
+	if err := c.runAttempts(ctx, &attempts{
+		task:        attemptTask,
+		description: description,
+		worker:      worker,
+		tierConfig:  tierConfig,
+		opts:        opts,
+		sources:     sources,
+	}, 1, result); err != nil {
+		return nil, err
 	}
+
+	return c.finish(result), nil
+}
+
+// finish stamps the end time and records the task's final status
+func (c *Conductor) finish(result *RunResult) *RunResult {
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
 
-	// Step 5: Record execution
-	exec := &ledger.Execution{
-		ID:         generateID(),
-		TaskID:     task.ID,
-		Backend:    string(result.ActualBackend),
-		Output:     execResult.Output,
-		TokensUsed: execResult.TokensUsed,
-		CostUSD:    execResult.CostUSD,
-		DurationMs: int(result.Duration.Milliseconds()),
-		Status:     "completed",
-	}
-
-	if !execResult.Success {
-		exec.Status = "failed"
-		exec.ErrorMsg = execResult.Error
-	}
-
-	if err := c.ledger.CreateExecution(exec); err != nil {
-		return nil, fmt.Errorf("failed to record execution: %w", err)
-	}
-
-	// Step 6: Validation (if required for this tier)
-	tierConfig := types.DefaultTierConfigs()[classification.Tier]
-	if tierConfig.ValidatorCount > 0 {
-		result.ValidationRequired = true
-		// TODO: Implement validation pipeline
-		result.ValidationPending = true
-	}
-
-	// Update final status
-	if execResult.Success {
-		if result.ValidationRequired && result.ValidationPending {
-			result.Status = types.StatusValidating
-			if updateErr := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusValidating)); updateErr != nil {
-				fmt.Printf("failed to update task status: %v\n", updateErr)
-			}
-		} else {
-			result.Status = types.StatusDone
-			if updateErr := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusDone)); updateErr != nil {
-				fmt.Printf("failed to update task status: %v\n", updateErr)
-			}
-		}
-	} else {
-		result.Status = types.StatusFailed
-		if updateErr := c.ledger.UpdateTaskStatus(task.ID, string(types.StatusFailed)); updateErr != nil {
-			fmt.Printf("failed to update task status: %v\n", updateErr)
-		}
+	if err := c.setStatus(result.TaskID, result.Status); err != nil {
+		fmt.Printf("failed to update task status: %v\n", err)
 	}
-
-	return result, nil
+	return result
 }
 
-// DryRun classifies a task without executing it
-func (c *Conductor) DryRun(title, description string) *RunResult {
-	classification := c.classifier.Classify(title, description)
+// DryRun classifies a task without executing it. The classifier model is
+// not called, but a verdict it cached for the same task is used. Of opts,
+// only Tier and Context are honored.
+func (c *Conductor) DryRun(title, description string, opts RunOptions) *RunResult {
+	classification, _, _ := c.classify(context.Background(), title, description, opts, false)
+	if opts.Tier != nil {
+		c.forceTier(classification, *opts.Tier)
+	}
 
 	// Check worker availability
-	worker, ok := c.workers[classification.RecommendedBackend]
+	worker, ok := c.worker(classification.RecommendedBackend)
 	workerAvailable := ok && worker.Available()
 
+	task := &types.Task{
+		Title:       title,
+		Description: description,
+		Tier:        classification.Tier,
+		Backend:     classification.RecommendedBackend,
+	}
+
+	var warnings []string
+	sources, err := c.contextSources(title, description, opts.Context)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
 	var fallbackBackend types.Backend
-	if !workerAvailable {
-		if fb := c.findFallbackWorker(classification.Tier); fb != nil {
-			fallbackBackend = fb.Backend()
-		}
+	var estimate float64
+	var packed *repo.Context
+	if workerAvailable {
+		packed = c.packContext(task, sources)
+		estimate = estimateCost(worker, task)
+	} else if fb := c.findFallbackWorker(classification.Tier); fb != nil {
+		fallbackBackend = fb.Backend()
+		task.Backend = fallbackBackend
+		packed = c.packContext(task, sources)
+		estimate = estimateCost(fb, task)
+	}
+
+	tierConfig := c.tiers[classification.Tier]
+	var testCommand string
+	if c.config.Validators.Tests.Command != "" && c.classifier.Index() != nil {
+		testCommand = c.config.Validators.Tests.Command
 	}
 
 	return &RunResult{
 		Classification:     classification,
 		ActualBackend:      classification.RecommendedBackend,
 		FallbackBackend:    fallbackBackend,
+		PlannerBackend:     tierConfig.PlannerBackend,
 		WorkerAvailable:    workerAvailable,
-		ValidationRequired: types.DefaultTierConfigs()[classification.Tier].ValidatorCount > 0,
+		EstimatedCostUSD:   estimate,
+		ValidationRequired: tierConfig.ValidatorCount > 0,
+		ValidatorBackend:   tierConfig.ValidatorBackend,
+		ValidatorCount:     tierConfig.ValidatorCount,
+		RequiredApprovals:  tierConfig.RequiredApprovals,
+		EscalationLadder:   tierConfig.Escalation,
+		TestCommand:        testCommand,
+		Context:            packed,
+		Warnings:           warnings,
 		DryRun:             true,
 	}
 }
 
+// forceTier replaces the classifier's tier with one chosen by the user and
+// routes the task to that tier's primary backend
+func (c *Conductor) forceTier(result *types.ClassificationResult, tier types.Tier) {
+	if tier != result.Tier {
+		result.Reasoning = fmt.Sprintf("Tier forced to %s; classifier chose %s. %s", tier, result.Tier, result.Reasoning)
+	}
+	result.Tier = tier
+	result.RecommendedBackend = c.classifier.recommendBackend(tier)
+	result.Confidence = 1
+	result.Source = "forced"
+}
+
 func (c *Conductor) findFallbackWorker(tier types.Tier) Worker {
-	// Fallback priority based on tier
-	var fallbacks []types.Backend
-
-	switch tier {
-	case types.TierTrivial, types.TierSimple:
-		// For simple tasks, try any Ollama, then Haiku
-		fallbacks = []types.Backend{
-			types.BackendOllama,
-			types.BackendOllamaFast,
-			types.BackendClaudeHaiku,
-		}
-	case types.TierStandard:
-		// For standard, try Sonnet then Ollama reasoning
-		fallbacks = []types.Backend{
-			types.BackendClaudeSonnet,
-			types.BackendOllamaReason,
-			types.BackendClaudeHaiku,
-		}
-	case types.TierComplex, types.TierCritical:
-		// For complex/critical, only Claude
-		fallbacks = []types.Backend{
-			types.BackendClaudeOpus,
-			types.BackendClaudeSonnet,
-		}
+	if fallbacks := c.fallbackWorkers(tier); len(fallbacks) > 0 {
+		return fallbacks[0]
 	}
+	return nil
+}
 
-	for _, backend := range fallbacks {
-		if w, ok := c.workers[backend]; ok && w.Available() {
-			return w
+// fallbackWorkers returns the tier's available fallback workers in priority order
+func (c *Conductor) fallbackWorkers(tier types.Tier) []Worker {
+	var available []Worker
+	for _, backend := range c.tiers[tier].Fallbacks {
+		if w, ok := c.worker(backend); ok && w.Available() {
+			available = append(available, w)
 		}
 	}
-
-	return nil
+	return available
 }
 
 // RunResult contains the outcome of a task execution
 type RunResult struct {
 	TaskID             string
+	Title              string
+	ParentID           string
 	Classification     *types.ClassificationResult
 	ActualBackend      types.Backend
 	FallbackBackend    types.Backend
+	PlannerBackend     types.Backend
 	WorkerAvailable    bool
 	Execution          *types.ExecutionResult
 	Status             types.TaskStatus
@@ -246,11 +440,31 @@ type RunResult struct {
 	EndTime            time.Time
 	Duration           time.Duration
 	ValidationRequired bool
-	ValidationPending  bool
+	ValidatorBackend   types.Backend
+	ValidatorCount     int
 	ValidationResults  []*types.ValidationResult
+	Approvals          int
+	RequiredApprovals  int
+	Attempts           int
+	Escalations        []types.Backend // Backends the task escalated to, in order
+	EscalationLadder   []types.Backend // Dry runs: the rungs a failing task would climb
+	Context            *repo.Context   // Dry runs: the files packed into the prompt
+	TestCommand        string          // Dry runs: the command run against changes, if any
+	EstimatedCostUSD   float64
+	TotalCostUSD       float64
+	Warnings           []string
+	Subtasks           []*RunResult // Set when a planner split the task
 	DryRun             bool
 }
 
+// optionalString maps an empty string to NULL for nullable columns
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func generateID() string {
 	b := make([]byte, 4)
 	if _, err := rand.Read(b); err != nil {
@@ -2,20 +2,33 @@ package conductor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/cammy/bigo/internal/bus"
 	"github.com/cammy/bigo/internal/config"
 	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/repo"
+	"github.com/cammy/bigo/internal/validators"
 	"github.com/cammy/bigo/pkg/types"
 )
 
 // MockWorker implements Worker interface
 type MockWorker struct {
-	BackendType    types.Backend
-	ExecuteFunc    func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error)
-	AvailableFunc  func() bool
-	CheckQuotaFunc func(ctx context.Context) error
+	WorkerID         string
+	BackendType      types.Backend
+	ExecuteFunc      func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error)
+	AvailableFunc    func() bool
+	CheckQuotaFunc   func(ctx context.Context) error
+	EstimateCostFunc func(task *types.Task) float64
 }
 
 func (m *MockWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
@@ -30,6 +43,9 @@ func (m *MockWorker) Available() bool {
 	}
 	return true
 }
+func (m *MockWorker) ID() string {
+	return m.WorkerID
+}
 func (m *MockWorker) Backend() types.Backend {
 	return m.BackendType
 }
@@ -39,6 +55,12 @@ func (m *MockWorker) CheckQuota(ctx context.Context) error {
 	}
 	return nil
 }
+func (m *MockWorker) EstimateCost(task *types.Task) float64 {
+	if m.EstimateCostFunc != nil {
+		return m.EstimateCostFunc(task)
+	}
+	return 0
+}
 
 func TestConductor_Run(t *testing.T) {
 	// Setup Ledger (using real sqlite on temp file)
@@ -58,6 +80,7 @@ func TestConductor_Run(t *testing.T) {
 	cfg := &config.Config{} // Empty config is fine for now
 
 	conductor := NewConductor(cfg, l)
+	defer conductor.Close()
 
 	// Register mock workers
 	// Trivial -> OllamaFast
@@ -65,6 +88,7 @@ func TestConductor_Run(t *testing.T) {
 	// Standard -> ClaudeSonnet
 
 	mockOllama := &MockWorker{
+		WorkerID:    "ollama-1",
 		BackendType: types.BackendOllama,
 		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
 			return &types.ExecutionResult{
@@ -73,6 +97,7 @@ func TestConductor_Run(t *testing.T) {
 				Success:    true,
 				Output:     "Ollama Output",
 				TokensUsed: 10,
+				Usage:      types.Usage{InputTokens: 6, CacheReadTokens: 1, OutputTokens: 3},
 			}, nil
 		},
 	}
@@ -96,7 +121,7 @@ func TestConductor_Run(t *testing.T) {
 	// "add simple function" -> TierSimple -> BackendOllama
 	t.Run("Simple Task", func(t *testing.T) {
 		ctx := context.Background()
-		res, err := conductor.Run(ctx, "Add simple function", "This checks something simple")
+		res, err := conductor.Run(ctx, "Add simple function", "This checks something simple", RunOptions{})
 		if err != nil {
 			t.Fatalf("Run failed: %v", err)
 		}
@@ -107,13 +132,28 @@ func TestConductor_Run(t *testing.T) {
 		if res.Execution.Output != "Ollama Output" {
 			t.Errorf("Expected output 'Ollama Output', got '%s'", res.Execution.Output)
 		}
+
+		// Cached input is recorded as input; the review follows the attempt
+		execs, err := l.GetExecutions(res.TaskID)
+		if err != nil || len(execs) != 2 {
+			t.Fatalf("Expected 2 executions, got %d (%v)", len(execs), err)
+		}
+		if execs[1].Kind != ledger.KindValidator {
+			t.Errorf("Expected the review recorded after the attempt, got %q", execs[1].Kind)
+		}
+		if execs[0].InputTokens != 7 || execs[0].OutputTokens != 3 {
+			t.Errorf("Expected 7 input and 3 output tokens, got %d and %d", execs[0].InputTokens, execs[0].OutputTokens)
+		}
+		if execs[0].Kind != ledger.KindAttempt || execs[0].WorkerID != "ollama-1" {
+			t.Errorf("Expected an attempt by the pool worker, got %q by %q", execs[0].Kind, execs[0].WorkerID)
+		}
 	})
 
 	// Test Case 2: Trivial Task (Expect OllamaFast)
 	// "Fix typo" -> TierTrivial -> BackendOllamaFast
 	t.Run("Trivial Task", func(t *testing.T) {
 		ctx := context.Background()
-		res, err := conductor.Run(ctx, "Fix typo", "spelling mistake")
+		res, err := conductor.Run(ctx, "Fix typo", "spelling mistake", RunOptions{})
 		if err != nil {
 			t.Fatalf("Run failed: %v", err)
 		}
@@ -159,7 +199,7 @@ func TestConductor_Run(t *testing.T) {
 	t.Run("Fallback", func(t *testing.T) {
 		ctx := context.Background()
 		// "Implement new feature" -> TierStandard -> ClaudeSonnet (Primary)
-		res, err := conductor.Run(ctx, "Implement new feature", "Standard logic")
+		res, err := conductor.Run(ctx, "Implement new feature", "Standard logic", RunOptions{})
 		if err != nil {
 			t.Fatalf("Run failed: %v", err)
 		}
@@ -170,3 +210,2416 @@ func TestConductor_Run(t *testing.T) {
 		}
 	})
 }
+
+func TestConductor_Validation(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-val-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	// Simple tier: Ollama executes, one Ollama validator must approve
+	verdict := `{"approved": true}`
+	c := NewConductor(&config.Config{}, l)
+	defer c.Close()
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendOllama,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			if strings.HasPrefix(task.Title, "Review") {
+				return &types.ExecutionResult{Success: true, Output: verdict}, nil
+			}
+			return &types.ExecutionResult{Success: true, Output: "func helper() {}"}, nil
+		},
+	})
+
+	t.Run("Approved", func(t *testing.T) {
+		res, err := c.Run(context.Background(), "Add simple function", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusApproved {
+			t.Errorf("Expected status %s, got %s (%s)", types.StatusApproved, res.Status, res.Error)
+		}
+		if len(res.ValidationResults) != 1 {
+			t.Fatalf("Expected 1 validation result, got %d", len(res.ValidationResults))
+		}
+
+		task, err := l.GetTask(res.TaskID)
+		if err != nil {
+			t.Fatalf("GetTask failed: %v", err)
+		}
+		if task.Status != string(types.StatusApproved) {
+			t.Errorf("Expected ledger status %s, got %s", types.StatusApproved, task.Status)
+		}
+
+		validations, err := l.GetValidations(res.ValidationResults[0].ExecutionID)
+		if err != nil {
+			t.Fatalf("GetValidations failed: %v", err)
+		}
+		if len(validations) != 1 || validations[0].Verdict != "approved" {
+			t.Errorf("Expected one approved validation in ledger, got %+v", validations)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		verdict = `{"approved": false, "findings": [{"severity": "error", "message": "wrong"}]}`
+		res, err := c.Run(context.Background(), "Add simple function", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusRejected {
+			t.Errorf("Expected status %s, got %s", types.StatusRejected, res.Status)
+		}
+	})
+
+	t.Run("Fallback reviewer is independent of the output", func(t *testing.T) {
+		one := 1
+		c := NewConductor(&config.Config{Routing: config.RoutingConfig{
+			"simple": {
+				Primary:           "ollama:default",
+				Validator:         "claude:sonnet",
+				ValidatorCount:    &one,
+				RequiredApprovals: &one,
+				Fallbacks:         []string{"ollama:default", "claude:haiku"},
+			},
+		}}, l)
+		defer c.Close()
+
+		var reviewers []types.Backend
+		for _, b := range []types.Backend{types.BackendOllama, types.BackendClaudeHaiku} {
+			c.RegisterWorker(&MockWorker{
+				BackendType: b,
+				ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+					if strings.HasPrefix(task.Title, "Review") {
+						reviewers = append(reviewers, b)
+						return &types.ExecutionResult{Backend: b, Success: true, Output: `{"approved": true}`}, nil
+					}
+					return &types.ExecutionResult{Backend: b, Success: true, Output: "func helper() {}"}, nil
+				},
+			})
+		}
+
+		tier := types.TierSimple
+		res, err := c.Run(context.Background(), "Add simple function", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusApproved || len(reviewers) != 1 || reviewers[0] != types.BackendClaudeHaiku {
+			t.Errorf("Expected Haiku to review Ollama's output, got %s reviewed by %v (%s)", res.Status, reviewers, res.Error)
+		}
+
+		// Without another backend, the output goes unreviewed rather than marked by its author
+		c = NewConductor(&config.Config{Routing: config.RoutingConfig{
+			"simple": {
+				Primary:           "ollama:default",
+				Validator:         "claude:sonnet",
+				ValidatorCount:    &one,
+				RequiredApprovals: &one,
+				Fallbacks:         []string{"ollama:default"},
+			},
+		}}, l)
+		defer c.Close()
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllama,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Backend: types.BackendOllama, Success: true, Output: `{"approved": true}`}, nil
+			},
+		})
+		res, err = c.Run(context.Background(), "Add simple function", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed || !strings.Contains(res.Error, "no available validator") {
+			t.Errorf("Expected validation to fail without an independent reviewer, got %s: %q", res.Status, res.Error)
+		}
+	})
+}
+
+func TestConductor_Retry(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-retry-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Conductor.MaxRetries = 2
+
+	t.Run("Recovers after failure and rejection", func(t *testing.T) {
+		c := NewConductor(cfg, l)
+		defer c.Close()
+
+		var prompts []string
+		reviews := 0
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllama,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				if strings.HasPrefix(task.Title, "Review") {
+					reviews++
+					if reviews == 1 {
+						return &types.ExecutionResult{Success: true, Output: `{"approved": false, "findings": [{"severity": "error", "location": "util.go:3", "message": "off by one"}]}`}, nil
+					}
+					return &types.ExecutionResult{Success: true, Output: `{"approved": true}`}, nil
+				}
+
+				prompts = append(prompts, task.Description)
+				if len(prompts) == 1 {
+					return &types.ExecutionResult{Success: false, Error: "connection reset"}, nil
+				}
+				return &types.ExecutionResult{Success: true, Output: "attempt output"}, nil
+			},
+		})
+
+		res, err := c.Run(context.Background(), "Add simple function", "original spec", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusApproved {
+			t.Errorf("Expected status %s, got %s (%s)", types.StatusApproved, res.Status, res.Error)
+		}
+		if res.Attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", res.Attempts)
+		}
+		if !strings.Contains(prompts[1], "connection reset") {
+			t.Errorf("Second prompt missing previous error: %s", prompts[1])
+		}
+		if !strings.Contains(prompts[2], "attempt output") || !strings.Contains(prompts[2], "off by one") {
+			t.Errorf("Third prompt missing previous output or findings: %s", prompts[2])
+		}
+
+		executions, err := l.GetExecutions(res.TaskID)
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		// Both attempts that produced output were reviewed
+		if len(executions) != 5 {
+			t.Fatalf("Expected 3 attempts and 2 reviews, got %d executions", len(executions))
+		}
+		if executions[0].Status != "failed" || executions[3].Attempt != 3 || executions[3].Kind != ledger.KindAttempt ||
+			executions[4].Attempt != 3 || executions[4].Kind != ledger.KindValidator {
+			t.Errorf("Unexpected execution rows: %+v", executions)
+		}
+	})
+
+	t.Run("Stops when retries run out", func(t *testing.T) {
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllamaFast,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: false, Error: "model not loaded"}, nil
+			},
+		})
+
+		res, err := c.Run(context.Background(), "Fix typo", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed {
+			t.Errorf("Expected status %s, got %s", types.StatusFailed, res.Status)
+		}
+		if res.Attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", res.Attempts)
+		}
+	})
+
+	t.Run("Stops when budget runs out", func(t *testing.T) {
+		budgetCfg := &config.Config{}
+		budgetCfg.Conductor.MaxRetries = 5
+		budgetCfg.Workers.Claude.CostLimits.PerTaskUSD = 0.10
+
+		// Trivial tier falls back to Haiku, which is subject to Claude's limits
+		c := NewConductor(budgetCfg, l)
+		defer c.Close()
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendClaudeHaiku,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: false, Error: "bad output", CostUSD: 0.06}, nil
+			},
+		})
+
+		res, err := c.Run(context.Background(), "Fix typo", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Attempts != 2 {
+			t.Errorf("Expected 2 attempts before budget ran out, got %d", res.Attempts)
+		}
+		if !strings.Contains(res.Error, "per-task limit") {
+			t.Errorf("Expected budget error, got %q", res.Error)
+		}
+	})
+}
+
+func TestConductor_CostLimits(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-cost-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	sonnet := &MockWorker{
+		BackendType:      types.BackendClaudeSonnet,
+		EstimateCostFunc: func(task *types.Task) float64 { return 2.0 },
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			return &types.ExecutionResult{Success: true, Output: `{"approved": true}`, CostUSD: 2.0}, nil
+		},
+	}
+	reasoning := &MockWorker{
+		BackendType: types.BackendOllamaReason,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			return &types.ExecutionResult{Success: true, Output: `{"approved": true}`}, nil
+		},
+	}
+
+	cfg := &config.Config{}
+	cfg.Workers.Claude.CostLimits = config.CostLimits{DailyUSD: 10.0, PerTaskUSD: 1.0}
+
+	t.Run("Downgrades to a cheaper backend", func(t *testing.T) {
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(sonnet)
+		c.RegisterWorker(reasoning)
+
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.ActualBackend != types.BackendOllamaReason {
+			t.Errorf("Expected downgrade to %s, got %s", types.BackendOllamaReason, res.ActualBackend)
+		}
+		if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "per-task limit") {
+			t.Errorf("Expected per-task limit warning, got %v", res.Warnings)
+		}
+	})
+
+	t.Run("Refuses without a cheaper backend", func(t *testing.T) {
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(sonnet)
+
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed || res.Execution != nil {
+			t.Errorf("Expected task to be refused before execution, got %s", res.Status)
+		}
+		if !strings.Contains(res.Error, "--allow-overspend") {
+			t.Errorf("Expected hint about --allow-overspend, got %q", res.Error)
+		}
+	})
+
+	t.Run("Allows overspend when asked", func(t *testing.T) {
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(sonnet)
+
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{AllowOverspend: true})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.ActualBackend != types.BackendClaudeSonnet || res.Execution == nil {
+			t.Errorf("Expected execution on %s, got %s", types.BackendClaudeSonnet, res.ActualBackend)
+		}
+		if len(res.Warnings) == 0 {
+			t.Error("Expected an overspend warning")
+		}
+	})
+
+	t.Run("Enforces the daily limit", func(t *testing.T) {
+		// The overspend run above already spent $2 (plus validator cost) today
+		dailyCfg := &config.Config{}
+		dailyCfg.Workers.Claude.CostLimits = config.CostLimits{DailyUSD: 3.0}
+
+		c := NewConductor(dailyCfg, l)
+		defer c.Close()
+		c.RegisterWorker(sonnet)
+
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed || !strings.Contains(res.Error, "daily limit") {
+			t.Errorf("Expected daily limit refusal, got %s: %q", res.Status, res.Error)
+		}
+	})
+
+	t.Run("Applies the OpenAI limits to openai backends", func(t *testing.T) {
+		openaiCfg := &config.Config{Routing: config.RoutingConfig{
+			"standard": {Primary: "openai:gpt", Fallbacks: []string{}, Escalation: []string{}},
+		}}
+		openaiCfg.Workers.OpenAI.CostLimits = config.CostLimits{PerTaskUSD: 1.0}
+
+		c := NewConductor(openaiCfg, l)
+		defer c.Close()
+		c.RegisterWorker(&MockWorker{
+			BackendType:      "openai:gpt",
+			EstimateCostFunc: func(task *types.Task) float64 { return 2.0 },
+		})
+
+		tier := types.TierStandard
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed || res.Execution != nil || !strings.Contains(res.Error, "per-task limit") {
+			t.Errorf("Expected the OpenAI per-task limit to refuse the task, got %s: %q", res.Status, res.Error)
+		}
+	})
+
+	t.Run("Counts validator spend", func(t *testing.T) {
+		two := 2
+		panelCfg := &config.Config{Routing: config.RoutingConfig{
+			"standard": {
+				Primary:           "claude:sonnet",
+				Validator:         "claude:haiku",
+				ValidatorCount:    &two,
+				RequiredApprovals: &two,
+			},
+		}}
+		panelCfg.Workers.Claude.CostLimits = config.CostLimits{PerTaskUSD: 1.0}
+
+		reviewEstimate := 0.3
+		c := NewConductor(panelCfg, l)
+		defer c.Close()
+		c.RegisterWorker(&MockWorker{
+			BackendType:      types.BackendClaudeSonnet,
+			EstimateCostFunc: func(task *types.Task) float64 { return 0.3 },
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: true, Output: "done", CostUSD: 0.3}, nil
+			},
+		})
+		c.RegisterWorker(&MockWorker{
+			BackendType:      types.BackendClaudeHaiku,
+			EstimateCostFunc: func(task *types.Task) float64 { return reviewEstimate },
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: true, Output: `{"approved": true}`, CostUSD: 0.25,
+					Usage: types.Usage{InputTokens: 100, OutputTokens: 10}}, nil
+			},
+		})
+
+		tier := types.TierStandard
+		res, err := c.Run(context.Background(), "Implement new feature", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusApproved || math.Abs(res.TotalCostUSD-0.8) > 1e-9 {
+			t.Errorf("Expected an approval costing $0.80 with both reviews, got %s costing $%.4f (%s)", res.Status, res.TotalCostUSD, res.Error)
+		}
+		execs, err := l.GetExecutions(res.TaskID)
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		var reviews int
+		for _, e := range execs {
+			if e.Kind == ledger.KindValidator && e.Backend == string(types.BackendClaudeHaiku) && e.CostUSD == 0.25 && e.InputTokens == 100 {
+				reviews++
+			}
+		}
+		if reviews != 2 {
+			t.Errorf("Expected both reviews recorded as executions, got %d of %d", reviews, len(execs))
+		}
+
+		// $0.30 spent on the attempt and $0.80 estimated for the panel breaks the $1 limit
+		reviewEstimate = 0.4
+		res, err = c.Run(context.Background(), "Implement new feature", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Status != types.StatusFailed || res.Execution == nil || !strings.Contains(res.Error, "cannot validate") ||
+			!strings.Contains(res.Error, "per-task limit") {
+			t.Errorf("Expected the panel to be refused after the attempt, got %s: %q", res.Status, res.Error)
+		}
+	})
+}
+
+func TestConductor_ConfiguredRouting(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-routing-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	one := 1
+	cfg := &config.Config{
+		Routing: config.RoutingConfig{
+			"complex": {
+				Primary:           "gemini:pro",
+				Validator:         "gemini:flash",
+				ValidatorCount:    &one,
+				RequiredApprovals: &one,
+				Fallbacks:         []string{"gemini:flash"},
+			},
+		},
+	}
+
+	var executed []types.Backend
+	newMock := func(b types.Backend) *MockWorker {
+		return &MockWorker{
+			BackendType: b,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				executed = append(executed, b)
+				return &types.ExecutionResult{Success: true, Output: `{"approved": true}`}, nil
+			},
+		}
+	}
+
+	t.Run("Primary and validator from config", func(t *testing.T) {
+		executed = nil
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(newMock(types.BackendGeminiPro))
+		c.RegisterWorker(newMock(types.BackendGeminiFlash))
+
+		res, err := c.Run(context.Background(), "Redesign the architecture", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.Classification.RecommendedBackend != types.BackendGeminiPro {
+			t.Errorf("Expected recommendation %s, got %s", types.BackendGeminiPro, res.Classification.RecommendedBackend)
+		}
+		if res.Status != types.StatusApproved {
+			t.Errorf("Expected status %s, got %s (%s)", types.StatusApproved, res.Status, res.Error)
+		}
+		if len(executed) != 2 || executed[0] != types.BackendGeminiPro || executed[1] != types.BackendGeminiFlash {
+			t.Errorf("Expected execution on gemini:pro then validation on gemini:flash, got %v", executed)
+		}
+	})
+
+	t.Run("Fallback list from config", func(t *testing.T) {
+		executed = nil
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(newMock(types.BackendGeminiFlash))
+		c.RegisterWorker(newMock(types.BackendClaudeOpus))
+
+		res, err := c.Run(context.Background(), "Redesign the architecture", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.ActualBackend != types.BackendGeminiFlash {
+			t.Errorf("Expected fallback to %s, got %s", types.BackendGeminiFlash, res.ActualBackend)
+		}
+	})
+}
+
+func TestConductor_MaxConcurrent(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-pool-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Workers.Ollama.MaxConcurrent = 2
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	// Two models of the same provider share its limit
+	for i := 0; i < 6; i++ {
+		backend := types.BackendOllamaFast
+		if i%2 == 1 {
+			backend = types.BackendOllama
+		}
+		c.RegisterWorker(&MockWorker{
+			BackendType: backend,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return &types.ExecutionResult{Success: true, Output: "done"}, nil
+			},
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := c.Run(context.Background(), "Fix typo", "", RunOptions{})
+			if err != nil {
+				t.Errorf("Run failed: %v", err)
+				return
+			}
+			if res.Status != types.StatusDone {
+				t.Errorf("Expected status %s, got %s (%s)", types.StatusDone, res.Status, res.Error)
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, _ := c.worker(types.BackendOllama)
+			if _, err := w.Execute(context.Background(), &types.Task{ID: generateID(), Title: "Fix typo"}); err != nil {
+				t.Errorf("Execute failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("Expected at most 2 concurrent executions, peak was %d", peak)
+	}
+}
+
+func TestConductor_RunBatch(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-batch-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	c := NewConductor(&config.Config{}, l)
+	defer c.Close()
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendOllamaFast,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			if strings.Contains(task.Title, "broken") {
+				return &types.ExecutionResult{Success: false, Error: "model crashed"}, nil
+			}
+			return &types.ExecutionResult{Success: true, Output: task.Title, CostUSD: 0.01}, nil
+		},
+	})
+
+	specs := []TaskSpec{
+		{Title: "Fix typo in README"},
+		{Title: "Fix broken typo"},
+		{Title: "Fix typo in docs", Description: "spelling"},
+	}
+
+	var mu sync.Mutex
+	queued := make(map[string]int)
+	final := make(map[int]types.TaskStatus)
+	unsubscribe := c.Bus().Subscribe(func(msg types.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch msg.Type {
+		case bus.TaskQueued:
+			queued[msg.TaskID] = msg.Payload["index"].(int)
+		case bus.TaskStatusChanged:
+			if i, ok := queued[msg.TaskID]; ok {
+				final[i] = types.TaskStatus(msg.Payload["status"].(string))
+			}
+		}
+	})
+	results := c.RunBatch(context.Background(), specs, RunOptions{})
+	c.Bus().Flush()
+	unsubscribe()
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if len(queued) != 3 {
+		t.Errorf("Expected 3 queued events, got %d", len(queued))
+	}
+	for i, r := range results {
+		if queued[r.TaskID] != i {
+			t.Errorf("Task %d: queued event has index %d", i, queued[r.TaskID])
+		}
+		if final[i] != r.Status {
+			t.Errorf("Task %d: last status event %s, result %s", i, final[i], r.Status)
+		}
+	}
+
+	want := []types.TaskStatus{types.StatusDone, types.StatusFailed, types.StatusDone}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("Task %d: expected status %s, got %s", i, want[i], r.Status)
+		}
+	}
+	if results[2].Execution.Output != "Fix typo in docs" {
+		t.Errorf("Results out of order: %q", results[2].Execution.Output)
+	}
+}
+
+func TestConductor_RunBatch_DailyLimit(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-batch-limit-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	zero := 0
+	cfg := &config.Config{Routing: config.RoutingConfig{
+		"standard": {Primary: "claude:sonnet", ValidatorCount: &zero, Fallbacks: []string{}, Escalation: []string{}},
+	}}
+	cfg.Workers.Claude.MaxConcurrent = 5
+	cfg.Workers.Claude.CostLimits = config.CostLimits{DailyUSD: 2.5}
+
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	for i := 0; i < 5; i++ {
+		c.RegisterWorker(&MockWorker{
+			BackendType:      types.BackendClaudeSonnet,
+			EstimateCostFunc: func(task *types.Task) float64 { return 1.0 },
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				// Long enough for every task to pass routing before any cost is recorded
+				time.Sleep(50 * time.Millisecond)
+				return &types.ExecutionResult{Success: true, Output: "done", CostUSD: 1.0}, nil
+			},
+		})
+	}
+
+	specs := make([]TaskSpec, 5)
+	for i := range specs {
+		specs[i] = TaskSpec{Title: fmt.Sprintf("Implement feature %d", i)}
+	}
+	tier := types.TierStandard
+	results := c.RunBatch(context.Background(), specs, RunOptions{Tier: &tier})
+
+	var done, refused int
+	for _, r := range results {
+		switch {
+		case r.Status == types.StatusDone:
+			done++
+		case r.Status == types.StatusFailed && strings.Contains(r.Error, "daily limit"):
+			refused++
+		default:
+			t.Errorf("Unexpected result %s: %q", r.Status, r.Error)
+		}
+	}
+	if done != 2 || refused != 3 {
+		t.Errorf("Expected 2 tasks within the $2.50 limit and 3 refused, got %d and %d", done, refused)
+	}
+
+	spent, err := l.GetSpendSince(startOfDay(time.Now()), "claude:")
+	if err != nil {
+		t.Fatalf("GetSpendSince failed: %v", err)
+	}
+	if spent > 2.5 {
+		t.Errorf("Expected the batch to stay within the daily limit, spent $%.2f", spent)
+	}
+	if len(c.holds) != 0 {
+		t.Errorf("Expected every hold released, %d left", len(c.holds))
+	}
+}
+
+func TestConductor_Events(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-events-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	c := NewConductor(&config.Config{}, l)
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendClaudeSonnet,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			if strings.Contains(task.Title, "Review") {
+				return &types.ExecutionResult{Success: true, Output: `{"approved": true, "findings": []}`}, nil
+			}
+			return &types.ExecutionResult{Success: true, Output: "done", TokensUsed: 42}, nil
+		},
+	})
+
+	var mu sync.Mutex
+	var published []string
+	c.Bus().Subscribe(func(msg types.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, msg.Type)
+	})
+
+	result, err := c.Run(context.Background(), "Implement user authentication feature", "", RunOptions{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Status != types.StatusApproved {
+		t.Fatalf("Expected approved, got %s (%s)", result.Status, result.Error)
+	}
+	c.Close()
+
+	want := []string{
+		bus.TaskCreated,
+		bus.TaskStatusChanged, // assigned
+		bus.TaskClassified,
+		bus.TaskAssigned,
+		bus.TaskStatusChanged, // working
+		bus.ExecutionStarted,
+		bus.ExecutionFinished,
+		bus.TaskStatusChanged, // validating
+	}
+	for range result.ValidationResults {
+		want = append(want, bus.ValidationVerdict)
+	}
+	want = append(want, bus.TaskStatusChanged) // approved
+	if strings.Join(published, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected events:\n got  %v\n want %v", published, want)
+	}
+
+	// The ledger subscriber keeps the same log
+	events, err := l.GetEvents(result.TaskID)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d recorded events, got %d", len(want), len(events))
+	}
+	if last := events[len(events)-1]; last.Payload != `{"status":"approved"}` {
+		t.Errorf("Unexpected final payload: %s", last.Payload)
+	}
+}
+
+func TestConductor_Planning(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-plan-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	const title = "Redesign the plugin architecture across multiple services"
+
+	setup := func(plan string) (*Conductor, *sync.Map) {
+		c := NewConductor(&config.Config{}, l)
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendClaudeOpus,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				if task.Title != plannerTitle {
+					// Also serves direct runs and reviews when the plan is unusable
+					return &types.ExecutionResult{Success: true, Output: `{"approved": true, "findings": []}`}, nil
+				}
+				return &types.ExecutionResult{Success: true, Output: plan, CostUSD: 0.5}, nil
+			},
+		})
+
+		descriptions := &sync.Map{}
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllamaFast,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				descriptions.Store(task.Title, task.Description)
+				if strings.Contains(task.Title, "broken") {
+					return &types.ExecutionResult{Success: false, Error: "model crashed"}, nil
+				}
+				return &types.ExecutionResult{Success: true, Output: "output of " + task.Title, TokensUsed: 10}, nil
+			},
+		})
+		return c, descriptions
+	}
+
+	t.Run("Subtasks run in dependency order", func(t *testing.T) {
+		c, descriptions := setup(`<think>two steps</think>{"subtasks": [
+			{"title": "Fix typo in docs", "description": "spelling", "depends_on": [2]},
+			{"title": "Fix typo in README", "depends_on": []}
+		]}`)
+		defer c.Close()
+
+		result, err := c.Run(context.Background(), title, "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Status != types.StatusDone {
+			t.Fatalf("Expected done, got %s (%s)", result.Status, result.Error)
+		}
+		if result.PlannerBackend != types.BackendClaudeOpus || len(result.Subtasks) != 2 {
+			t.Fatalf("Expected 2 subtasks planned by opus, got %d by %s", len(result.Subtasks), result.PlannerBackend)
+		}
+		for _, sub := range result.Subtasks {
+			if sub.ParentID != result.TaskID || sub.ActualBackend != types.BackendOllamaFast {
+				t.Errorf("Subtask %q: parent %s, backend %s", sub.Title, sub.ParentID, sub.ActualBackend)
+			}
+		}
+		if result.TotalCostUSD != 0.5 {
+			t.Errorf("Expected planner cost to be counted, got $%.2f", result.TotalCostUSD)
+		}
+
+		// The dependent subtask sees the parent and its prerequisite's output
+		desc, _ := descriptions.Load("Fix typo in docs")
+		for _, want := range []string{"spelling", title, "output of Fix typo in README"} {
+			if !strings.Contains(desc.(string), want) {
+				t.Errorf("Subtask description missing %q:\n%s", want, desc)
+			}
+		}
+		if !strings.Contains(result.Execution.Output, "## Subtask 2: Fix typo in README (done)") {
+			t.Errorf("Unexpected aggregate output:\n%s", result.Execution.Output)
+		}
+
+		tree, err := l.GetTaskTree(result.TaskID)
+		if err != nil {
+			t.Fatalf("GetTaskTree failed: %v", err)
+		}
+		if tree.Task.Status != string(types.StatusDone) || len(tree.Children) != 2 {
+			t.Fatalf("Expected done parent with 2 children, got %s with %d", tree.Task.Status, len(tree.Children))
+		}
+
+		execs, err := l.GetExecutions(result.TaskID)
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		if len(execs) != 2 || execs[0].Kind != ledger.KindPlanner || execs[1].Kind != ledger.KindAggregate {
+			t.Errorf("Expected planner and aggregate executions on the parent, got %d", len(execs))
+		}
+	})
+
+	t.Run("Failed prerequisite skips dependents", func(t *testing.T) {
+		c, descriptions := setup(`{"subtasks": [
+			{"title": "Fix broken typo"},
+			{"title": "Fix typo in docs", "depends_on": [1]}
+		]}`)
+		defer c.Close()
+
+		result, err := c.Run(context.Background(), title, "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Status != types.StatusFailed || result.Error != "2 of 2 subtasks did not succeed" {
+			t.Errorf("Expected both subtasks to fail, got %s (%s)", result.Status, result.Error)
+		}
+		if _, ran := descriptions.Load("Fix typo in docs"); ran {
+			t.Error("Dependent subtask should not have run")
+		}
+		if !strings.HasPrefix(result.Subtasks[1].Error, "skipped") {
+			t.Errorf("Expected skipped subtask, got %q", result.Subtasks[1].Error)
+		}
+	})
+
+	t.Run("Invalid plan runs the task directly", func(t *testing.T) {
+		c, _ := setup(`{"subtasks": [{"title": "a", "depends_on": [2]}, {"title": "b", "depends_on": [1]}]}`)
+		defer c.Close()
+
+		result, err := c.Run(context.Background(), title, "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(result.Subtasks) != 0 {
+			t.Errorf("Expected no subtasks, got %d", len(result.Subtasks))
+		}
+		if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], "cycle") {
+			t.Errorf("Expected a planning warning, got %v", result.Warnings)
+		}
+	})
+}
+
+func TestConductor_Resume(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-resume-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	// orphan records a task interrupted after the given executions
+	orphan := func(t *testing.T, task *ledger.Task, execs ...*ledger.Execution) {
+		t.Helper()
+		task.Status = string(types.StatusOrphaned)
+		if err := l.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+		for _, e := range execs {
+			e.ID = generateID()
+			e.TaskID = task.ID
+			if err := l.CreateExecution(e); err != nil {
+				t.Fatalf("CreateExecution failed: %v", err)
+			}
+		}
+	}
+
+	setup := func(maxRetries int) (*Conductor, *[]string) {
+		cfg := &config.Config{}
+		cfg.Conductor.MaxRetries = maxRetries
+		c := NewConductor(cfg, l)
+
+		var mu sync.Mutex
+		var executed []string
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllama,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				if strings.Contains(task.Title, "Review") {
+					return &types.ExecutionResult{Success: true, Output: `{"approved": true, "findings": []}`}, nil
+				}
+				mu.Lock()
+				executed = append(executed, task.Description)
+				mu.Unlock()
+				return &types.ExecutionResult{Success: true, Output: "fixed"}, nil
+			},
+		})
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllamaFast,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				// Also reviews the output of ollama:default, the SIMPLE tier's validator
+				if strings.Contains(task.Title, "Review") {
+					return &types.ExecutionResult{Success: true, Output: `{"approved": true, "findings": []}`}, nil
+				}
+				mu.Lock()
+				executed = append(executed, task.Title)
+				mu.Unlock()
+				return &types.ExecutionResult{Success: true, Output: "output of " + task.Title}, nil
+			},
+		})
+		return c, &executed
+	}
+
+	simple := func(id string) *ledger.Task {
+		return &ledger.Task{ID: id, Title: "Add simple function", Tier: int(types.TierSimple), WorkerBackend: string(types.BackendOllama)}
+	}
+
+	t.Run("Unreviewed output is validated", func(t *testing.T) {
+		c, executed := setup(3)
+		defer c.Close()
+		orphan(t, simple("resume-validate"), &ledger.Execution{Backend: "ollama:default", Output: "done", Status: "completed", Attempt: 1})
+
+		result, err := c.Resume(context.Background(), "resume-validate", RunOptions{})
+		if err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result.Status != types.StatusApproved || result.Attempts != 1 {
+			t.Errorf("Expected approval of the recorded attempt, got %s after %d attempt(s)", result.Status, result.Attempts)
+		}
+		if len(*executed) != 0 {
+			t.Errorf("Expected no new execution, got %d", len(*executed))
+		}
+	})
+
+	t.Run("Recorded rejection is not reviewed again", func(t *testing.T) {
+		c, executed := setup(3)
+		defer c.Close()
+		attempt := &ledger.Execution{Backend: "ollama:default", Output: "done", Status: "completed", Attempt: 1}
+		orphan(t, simple("resume-rejected"), attempt)
+		if err := l.CreateValidation(&ledger.Validation{ID: generateID(), ExecutionID: attempt.ID, ValidatorID: "validator-1",
+			Backend: "ollama:default", Verdict: "rejected",
+			Findings: `[{"severity": "error", "location": "main.go:3", "message": "off by one"}]`}); err != nil {
+			t.Fatalf("CreateValidation failed: %v", err)
+		}
+
+		result, err := c.Resume(context.Background(), "resume-rejected", RunOptions{})
+		if err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result.Status != types.StatusApproved || result.Attempts != 2 {
+			t.Fatalf("Expected approval on attempt 2, got %s after %d (%s)", result.Status, result.Attempts, result.Error)
+		}
+		if len(*executed) != 1 || !strings.Contains((*executed)[0], "off by one") {
+			t.Errorf("Expected one retry with the recorded findings, got %v", *executed)
+		}
+
+		// Only the new attempt was reviewed
+		execs, err := l.GetExecutions("resume-rejected")
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		reviews := 0
+		for _, e := range execs {
+			if e.Kind == ledger.KindValidator {
+				reviews++
+			}
+		}
+		if reviews != 1 {
+			t.Errorf("Expected 1 review, got %d", reviews)
+		}
+	})
+
+	t.Run("Failed attempt is retried with feedback", func(t *testing.T) {
+		c, executed := setup(1)
+		defer c.Close()
+		orphan(t, simple("resume-retry"), &ledger.Execution{Backend: "ollama:default", Status: "failed", ErrorMsg: "connection reset", Attempt: 1})
+
+		result, err := c.Resume(context.Background(), "resume-retry", RunOptions{})
+		if err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result.Status != types.StatusApproved || result.Attempts != 2 {
+			t.Fatalf("Expected approval on attempt 2, got %s after %d", result.Status, result.Attempts)
+		}
+		if len(*executed) != 1 || !strings.Contains((*executed)[0], "connection reset") {
+			t.Errorf("Expected one retry with the previous error, got %v", *executed)
+		}
+
+		task, _ := l.GetTask("resume-retry")
+		if task.Status != string(types.StatusApproved) {
+			t.Errorf("Expected ledger status approved, got %s", task.Status)
+		}
+	})
+
+	t.Run("Retry budget is honoured", func(t *testing.T) {
+		c, executed := setup(0)
+		defer c.Close()
+		orphan(t, simple("resume-spent"), &ledger.Execution{Backend: "ollama:default", Status: "failed", ErrorMsg: "timeout", Attempt: 1})
+
+		result, err := c.Resume(context.Background(), "resume-spent", RunOptions{})
+		if err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result.Status != types.StatusFailed || len(*executed) != 0 {
+			t.Errorf("Expected failure without new attempts, got %s with %d", result.Status, len(*executed))
+		}
+		if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], "retry budget") {
+			t.Errorf("Expected a retry budget warning, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("Only orphaned tasks resume", func(t *testing.T) {
+		c, _ := setup(3)
+		defer c.Close()
+		if err := l.CreateTask(&ledger.Task{ID: "resume-done", Title: "Done", Status: "done"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Resume(context.Background(), "resume-done", RunOptions{}); err == nil {
+			t.Error("Expected an error resuming a finished task")
+		}
+	})
+
+	t.Run("Planned task runs the steps that never started", func(t *testing.T) {
+		c, executed := setup(3)
+		defer c.Close()
+
+		parentID := "resume-plan"
+		orphan(t, &ledger.Task{ID: parentID, Title: "Redesign the plugin architecture", Tier: int(types.TierComplex)},
+			&ledger.Execution{Kind: ledger.KindPlanner, Backend: "claude:opus", Status: "completed", CostUSD: 0.5,
+				Output: `{"subtasks": [{"title": "Fix typo in README"}, {"title": "Fix typo in docs", "depends_on": [1]}]}`})
+		if err := l.CreateTask(&ledger.Task{ID: "resume-plan-1", ParentID: &parentID, Title: "Fix typo in README", Status: "done"}); err != nil {
+			t.Fatal(err)
+		}
+
+		ids, err := c.OrphanedTasks()
+		if err != nil {
+			t.Fatalf("OrphanedTasks failed: %v", err)
+		}
+		found := false
+		for _, id := range ids {
+			found = found || id == parentID
+		}
+		if !found {
+			t.Fatalf("Expected %s among orphaned tasks %v", parentID, ids)
+		}
+
+		result, err := c.Resume(context.Background(), parentID, RunOptions{})
+		if err != nil {
+			t.Fatalf("Resume failed: %v", err)
+		}
+		if result.Status != types.StatusDone || len(result.Subtasks) != 2 {
+			t.Fatalf("Expected done with 2 subtasks, got %s with %d (%s)", result.Status, len(result.Subtasks), result.Error)
+		}
+		if len(*executed) != 1 || (*executed)[0] != "Fix typo in docs" {
+			t.Errorf("Expected only the unstarted step to run, got %v", *executed)
+		}
+		if result.Subtasks[0].TaskID != "resume-plan-1" {
+			t.Errorf("Expected the finished subtask to be reused, got %s", result.Subtasks[0].TaskID)
+		}
+	})
+}
+
+func TestConductor_PlanPerTaskLimit(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-plan-limit-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	zero := 0
+	route := config.TierRoute{Primary: "claude:sonnet", ValidatorCount: &zero, Fallbacks: []string{}, Escalation: []string{}}
+	planned := route
+	planned.Planner = "claude:opus"
+	cfg := &config.Config{Routing: config.RoutingConfig{
+		"trivial": route, "simple": route, "standard": route, "complex": planned, "critical": route,
+	}}
+	cfg.Workers.Claude.MaxConcurrent = 3
+	cfg.Workers.Claude.CostLimits = config.CostLimits{PerTaskUSD: 2.5}
+
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	c.RegisterWorker(&MockWorker{
+		BackendType:      types.BackendClaudeOpus,
+		EstimateCostFunc: func(task *types.Task) float64 { return 0.5 },
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			return &types.ExecutionResult{Success: true, CostUSD: 0.5, Output: `{"subtasks": [
+				{"title": "Implement feature one"}, {"title": "Implement feature two"}, {"title": "Implement feature three"}]}`}, nil
+		},
+	})
+	for i := 0; i < 3; i++ {
+		c.RegisterWorker(&MockWorker{
+			BackendType:      types.BackendClaudeSonnet,
+			EstimateCostFunc: func(task *types.Task) float64 { return 1.0 },
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				// Long enough for every subtask to pass routing before any cost is recorded
+				time.Sleep(50 * time.Millisecond)
+				return &types.ExecutionResult{Success: true, Output: "done", CostUSD: 1.0}, nil
+			},
+		})
+	}
+
+	// The planner's $0.50 and two $1 subtasks fit the $2.50 limit; a third does not
+	tier := types.TierComplex
+	result, err := c.Run(context.Background(), "Redesign the plugin architecture", "", RunOptions{Tier: &tier})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Subtasks) != 3 {
+		t.Fatalf("Expected 3 subtasks, got %d (%s)", len(result.Subtasks), result.Error)
+	}
+	var done, refused int
+	for _, r := range result.Subtasks {
+		switch {
+		case r.Status == types.StatusDone:
+			done++
+		case r.Status == types.StatusFailed && strings.Contains(r.Error, "shared by the planned task's subtasks"):
+			refused++
+		default:
+			t.Errorf("Unexpected subtask result %s: %q", r.Status, r.Error)
+		}
+	}
+	if done != 2 || refused != 1 {
+		t.Errorf("Expected 2 subtasks within the limit and 1 refused, got %d and %d", done, refused)
+	}
+	if result.Status != types.StatusFailed || math.Abs(result.TotalCostUSD-2.5) > 1e-9 {
+		t.Errorf("Expected the task to fail at $2.50, got %s at $%.4f", result.Status, result.TotalCostUSD)
+	}
+}
+
+func TestConductor_ResumeInterruptedPlan(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-replan-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Conductor.StaleAfter = "1ms"
+	c := NewConductor(cfg, l)
+	defer c.Close()
+
+	planning := make(chan struct{})
+	var plans atomic.Int32
+	var taskID string
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendClaudeOpus,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			if plans.Add(1) == 1 {
+				// The first planner call outlives its run
+				taskID = task.ID
+				close(planning)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return &types.ExecutionResult{Success: true,
+				Output: `{"subtasks": [{"title": "Fix typo in README"}, {"title": "Fix typo in docs", "depends_on": [1]}]}`}, nil
+		},
+	})
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendOllamaFast,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			return &types.ExecutionResult{Success: true, Output: "output of " + task.Title}, nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tier := types.TierComplex
+	done := make(chan error)
+	go func() {
+		_, err := c.Run(ctx, "Redesign the plugin architecture", "", RunOptions{Tier: &tier})
+		done <- err
+	}()
+
+	<-planning
+	task, err := l.GetTask(taskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.Status != string(types.StatusAssigned) {
+		t.Errorf("Expected the task in flight while planning, got %s", task.Status)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the interrupted run to return its context's error, got %v", err)
+	}
+
+	// Timestamps have a resolution of a second
+	time.Sleep(1100 * time.Millisecond)
+	orphaned, err := c.Sweep()
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	if len(orphaned) != 1 || orphaned[0].ID != taskID {
+		t.Fatalf("Expected the task orphaned by the sweep, got %d task(s)", len(orphaned))
+	}
+
+	result, err := c.Resume(context.Background(), taskID, RunOptions{})
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if result.Status != types.StatusDone || len(result.Subtasks) != 2 || result.PlannerBackend != types.BackendClaudeOpus {
+		t.Errorf("Expected the task planned again and done with 2 subtasks, got %s with %d (%s)",
+			result.Status, len(result.Subtasks), result.Error)
+	}
+	if n := plans.Load(); n != 2 {
+		t.Errorf("Expected 2 planner calls, got %d", n)
+	}
+}
+
+func TestConductor_ModelClassification(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-classify-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Conductor.ClassifierModel = string(types.BackendClaudeHaiku)
+	cfg.Conductor.ClassifierThreshold = 0.6
+
+	setup := func(verdict string) (*Conductor, *int) {
+		c := NewConductor(cfg, l)
+		calls := 0
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendClaudeHaiku,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				if task.Title != classifierTitle {
+					// Also serves as the fallback for standard tasks and their reviews
+					return &types.ExecutionResult{Success: true, Output: `{"approved": true, "findings": []}`}, nil
+				}
+				calls++
+				return &types.ExecutionResult{Success: true, Output: verdict, TokensUsed: 20, CostUSD: 0.001}, nil
+			},
+		})
+		c.RegisterWorker(&MockWorker{
+			BackendType: types.BackendOllamaFast,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: true, Output: "done"}, nil
+			},
+		})
+		return c, &calls
+	}
+
+	t.Run("Inconclusive patterns ask the model once", func(t *testing.T) {
+		c, calls := setup(`<think>tiny</think>{"tier": 0, "estimated_files": 1, "estimated_lines": 3, "confidence": 0.9, "rationale": "one-word change"}`)
+		defer c.Close()
+
+		result, err := c.Run(context.Background(), "Tweak the widget colour", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		class := result.Classification
+		if class.Tier != types.TierTrivial || class.Source != "model" || class.Confidence != 0.9 {
+			t.Fatalf("Expected trivial from the model at 90%%, got %s from %s at %.2f", class.Tier, class.Source, class.Confidence)
+		}
+		if class.EstimatedLines != 3 || class.EstimatedFiles != 1 || !strings.Contains(class.Reasoning, "one-word change") {
+			t.Errorf("Model estimates not merged: %+v", class)
+		}
+		if result.ActualBackend != types.BackendOllamaFast || result.Status != types.StatusDone {
+			t.Errorf("Expected done on %s, got %s on %s", types.BackendOllamaFast, result.Status, result.ActualBackend)
+		}
+		if result.TotalCostUSD != 0.001 {
+			t.Errorf("Expected the classification cost to count, got $%.4f", result.TotalCostUSD)
+		}
+
+		execs, err := l.GetExecutions(result.TaskID)
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		if len(execs) != 2 || execs[0].Kind != ledger.KindClassifier || execs[0].Backend != string(types.BackendClaudeHaiku) {
+			t.Fatalf("Expected the classifier call recorded first, got %d execution(s)", len(execs))
+		}
+
+		// The same text, differently cased and spaced, is answered from the cache
+		again, err := c.Run(context.Background(), "  tweak the   WIDGET colour ", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if *calls != 1 {
+			t.Errorf("Expected 1 model call, got %d", *calls)
+		}
+		if again.Classification.Source != "cache" || again.Classification.Tier != types.TierTrivial {
+			t.Errorf("Expected trivial from the cache, got %s from %s", again.Classification.Tier, again.Classification.Source)
+		}
+
+		if dry := c.DryRun("Tweak the widget colour", "", RunOptions{}); dry.Classification.Source != "cache" {
+			t.Errorf("Expected dry run to use the cache, got %s", dry.Classification.Source)
+		}
+	})
+
+	t.Run("Confident patterns skip the model", func(t *testing.T) {
+		c, calls := setup(`{"tier": 4}`)
+		defer c.Close()
+
+		result, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if *calls != 0 || result.Classification.Source != "patterns" {
+			t.Errorf("Expected no model call, got %d (source %s)", *calls, result.Classification.Source)
+		}
+	})
+
+	t.Run("Unusable verdict keeps the pattern result", func(t *testing.T) {
+		c, calls := setup(`I think this is quite hard`)
+		defer c.Close()
+
+		dry := c.DryRun("Polish the onboarding flow", "", RunOptions{})
+		if *calls != 0 || dry.Classification.Source != "patterns" {
+			t.Fatalf("Expected dry run not to call the model, got %d call(s)", *calls)
+		}
+
+		result, err := c.Run(context.Background(), "Polish the onboarding flow", "", RunOptions{AllowOverspend: true})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Classification.Source != "patterns" || result.Classification.Tier != types.TierStandard {
+			t.Errorf("Expected the pattern tier, got %s from %s", result.Classification.Tier, result.Classification.Source)
+		}
+		if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[0], "model classification skipped") {
+			t.Errorf("Expected a classification warning, got %v", result.Warnings)
+		}
+	})
+}
+
+func TestParseVerdict(t *testing.T) {
+	for _, tt := range []struct {
+		output string
+		tier   int
+		ok     bool
+	}{
+		{`{"tier": 3, "rationale": "spans services"}`, 3, true},
+		{`Sure: {"tier": "T1"}`, 1, true},
+		{`{"tier": "critical", "confidence": 7}`, 4, true},
+		{`{"tier": 9}`, 0, false},
+		{`{"estimated_files": 2}`, 0, false},
+		{`{"tier": 2, "estimated_lines": -1}`, 0, false},
+		{`no verdict`, 0, false},
+	} {
+		v, err := parseVerdict(tt.output)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseVerdict(%q) error = %v, want ok %v", tt.output, err, tt.ok)
+			continue
+		}
+		if tt.ok && v.Tier != tt.tier {
+			t.Errorf("parseVerdict(%q) tier = %d, want %d", tt.output, v.Tier, tt.tier)
+		}
+		if tt.ok && (v.Confidence < 0 || v.Confidence > 1) {
+			t.Errorf("parseVerdict(%q) kept out-of-range confidence %v", tt.output, v.Confidence)
+		}
+	}
+}
+
+func TestClassifier_RepoScope(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body := strings.Repeat("\tstep()\n", 600)
+	write("engine/engine.go", "package engine\n\nfunc Reconcile() {\n"+body+"}\n\nfunc Helper() {\n\tstep()\n}\n")
+	for i := 0; i < 12; i++ {
+		write(fmt.Sprintf("callers/c%d.go", i), "package callers\n\nfunc use() {\n\tengine.Helper()\n}\n")
+	}
+
+	idx, err := repo.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	c := NewClassifier()
+	c.SetIndex(idx)
+
+	tests := []struct {
+		task  string
+		tier  types.Tier
+		lines int
+		files int
+	}{
+		// The whole of a 600-line function is in play
+		{"Speed up Reconcile", types.TierComplex, 602, 1},
+		// Editing a three-line function leaves its callers alone...
+		{"Tidy up Helper", types.TierSimple, 3, 1},
+		// ...but renaming it touches all twelve
+		{"Rename Helper to Assist", types.TierComplex, 3 + 12*linesPerDependent, 13},
+	}
+	for _, tt := range tests {
+		result := c.Classify(tt.task, "")
+		if result.Tier != tt.tier || result.EstimatedLines != tt.lines || result.EstimatedFiles != tt.files {
+			t.Errorf("Classify(%q) = %s, %d lines, %d files; want %s, %d lines, %d files",
+				tt.task, result.Tier, result.EstimatedLines, result.EstimatedFiles, tt.tier, tt.lines, tt.files)
+		}
+		if !strings.Contains(result.Reasoning, "engine/engine.go") {
+			t.Errorf("Classify(%q) reasoning does not name the file: %s", tt.task, result.Reasoning)
+		}
+	}
+
+	// Tasks that mention nothing in the tree keep the wording-based estimate
+	if result := c.Classify("Improve the onboarding flow", ""); result.EstimatedLines != 50 || len(result.RelatedFiles) != 0 {
+		t.Errorf("Expected the default estimate, got %d lines, files %v", result.EstimatedLines, result.RelatedFiles)
+	}
+}
+
+func TestClassifier_ApplyPatterns(t *testing.T) {
+	weight := func(w float64) *float64 { return &w }
+
+	c := NewClassifier()
+	err := c.ApplyPatterns(config.PatternsConfig{
+		"critical": {{Name: "hipaa", Regex: `\bHIPAA\b`, Weight: weight(0.9)}},
+		"complex":  {{Name: "migration", Disabled: true}},
+		"trivial": {
+			{Name: "config_migration", Regex: `\bmigrat\w*\s+(the\s+)?config`},
+			{Name: "typo", Weight: weight(0.4)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyPatterns failed: %v", err)
+	}
+
+	if result := c.Classify("Log HIPAA access", ""); result.Tier != types.TierCritical || result.Patterns[0] != "hipaa" {
+		t.Errorf("Expected the added pattern to make the task critical, got %s %v", result.Tier, result.Patterns)
+	}
+	if result := c.Classify("Migrate the config flag", ""); result.Tier != types.TierTrivial {
+		t.Errorf("Expected config migrations to be trivial, got %s %v", result.Tier, result.Patterns)
+	}
+
+	e := c.Explain("Fix a typo in the HIPAA notice", "")
+	if e.PatternTier != types.TierCritical || e.Scores[types.TierTrivial] != 0.4 || e.Scores[types.TierCritical] != 0.9 {
+		t.Errorf("Unexpected explanation: tier %s, scores %v", e.PatternTier, e.Scores)
+	}
+	for _, m := range e.Matches {
+		if !m.Pattern.Custom {
+			t.Errorf("Expected pattern %s to be marked custom", m.Pattern.Name)
+		}
+	}
+
+	// Invalid rules are rejected without touching the patterns
+	for _, rules := range []config.PatternsConfig{
+		{"complex": {{Name: "missing", Disabled: true}}},
+		{"complex": {{Name: "no_regex", Weight: weight(0.5)}}},
+		{"complex": {{Name: "bad", Regex: `(`}}},
+		{"hard": {{Name: "x", Regex: "x"}}},
+		{"critical": {{Name: "hipaa", Disabled: true}}, "simple": {{Name: "bad", Regex: `[`}}},
+	} {
+		if err := c.ApplyPatterns(rules); err == nil {
+			t.Errorf("Expected ApplyPatterns(%v) to fail", rules)
+		}
+	}
+	if result := c.Classify("Log HIPAA access", ""); result.Tier != types.TierCritical {
+		t.Errorf("Expected a failed ApplyPatterns to leave patterns unchanged, got %s", result.Tier)
+	}
+}
+
+func TestClassifier_Calibrate(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-calibrate-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	c := NewConductor(&config.Config{}, l)
+	defer c.Close()
+	c.RegisterWorker(&MockWorker{BackendType: types.BackendOllamaFast})
+
+	result, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	c.Bus().Flush()
+
+	outcomes, err := l.GetClassificationOutcomes()
+	if err != nil {
+		t.Fatalf("GetClassificationOutcomes failed: %v", err)
+	}
+	if len(outcomes) != 1 || outcomes[0].TaskID != result.TaskID || outcomes[0].Attempts != 1 {
+		t.Fatalf("Expected one recorded outcome with 1 attempt, got %+v", outcomes)
+	}
+	if o := outcomes[0]; o.PredictedTier != int(types.TierTrivial) || len(o.Patterns) != 1 || o.Patterns[0].Name != "typo" {
+		t.Errorf("Unexpected prediction: tier %d, patterns %v", o.PredictedTier, o.Patterns)
+	}
+	if tier, ok := EffectiveTier(outcomes[0]); !ok || tier != types.TierTrivial {
+		t.Errorf("Expected a clean success to confirm T0, got %s (%v)", tier, ok)
+	}
+
+	two := 2
+	typo := []ledger.PatternHit{{Tier: int(types.TierTrivial), Name: "typo"}}
+	refactor := []ledger.PatternHit{{Tier: int(types.TierStandard), Name: "refactor"}}
+	var history []*ledger.ClassificationOutcome
+	for i := 0; i < 5; i++ {
+		// Typo fixes always succeed on the first try
+		history = append(history, &ledger.ClassificationOutcome{PredictedTier: 0, Patterns: typo, Status: "done", Attempts: 1})
+	}
+	for i := 0; i < 6; i++ {
+		// "Refactor" tasks were classified as standard but kept being rejected
+		history = append(history, &ledger.ClassificationOutcome{PredictedTier: 2, Patterns: refactor, Status: "approved", Attempts: 2, Rejections: 1})
+	}
+	history = append(history,
+		&ledger.ClassificationOutcome{PredictedTier: 2, Status: "done", Attempts: 1, ForcedTier: &two},
+		&ledger.ClassificationOutcome{PredictedTier: 1, Status: "done", Attempts: 2, FailedAttempts: 1, EffectiveTier: &two},
+		&ledger.ClassificationOutcome{PredictedTier: 3, Status: "failed"},               // never ran
+		&ledger.ClassificationOutcome{PredictedTier: 3, Status: "working", Attempts: 1}, // still running
+	)
+
+	cal := c.classifier.Calibrate(history)
+	if cal.Outcomes != 13 {
+		t.Fatalf("Expected 13 usable outcomes, got %d", cal.Outcomes)
+	}
+	if cal.Confusion[0][0] != 5 || cal.Confusion[2][3] != 6 || cal.Confusion[2][2] != 1 || cal.Confusion[1][2] != 1 {
+		t.Errorf("Unexpected confusion matrix: %v", cal.Confusion)
+	}
+	if got := cal.Accuracy(); got < 0.46 || got > 0.47 {
+		t.Errorf("Expected 6/13 accuracy, got %.3f", got)
+	}
+
+	if len(cal.Patterns) != 2 {
+		t.Fatalf("Expected stats for 2 patterns, got %d", len(cal.Patterns))
+	}
+	typoStats, refactorStats := cal.Patterns[0], cal.Patterns[1]
+	if typoStats.Name != "typo" || typoStats.Precision() != 1 || typoStats.Proposed != 1 {
+		t.Errorf("Expected typo at 100%% precision to be raised to 1.0, got %+v", typoStats)
+	}
+	if refactorStats.Name != "refactor" || refactorStats.Precision() != 0 || refactorStats.Proposed != 0.3 {
+		t.Errorf("Expected refactor at 0%% precision to be halved to 0.3, got %+v", refactorStats)
+	}
+}
+
+func TestConductor_ForcedTier(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-forced-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	c := NewConductor(&config.Config{}, l)
+	defer c.Close()
+	var descriptions []string
+	var mu sync.Mutex
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendOllama,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			if strings.HasPrefix(task.Title, "Review") {
+				return &types.ExecutionResult{Success: true, Output: `{"approved": true}`}, nil
+			}
+			mu.Lock()
+			descriptions = append(descriptions, task.Description)
+			mu.Unlock()
+			return &types.ExecutionResult{Success: true, Output: "fixed"}, nil
+		},
+	})
+
+	// A typo fix is trivial, but the user knows better
+	simple := types.TierSimple
+	opts := RunOptions{Tier: &simple}
+
+	dry := c.DryRun("Fix typo in README", "", opts)
+	if dry.Classification.Tier != types.TierSimple || dry.Classification.Source != "forced" || dry.ActualBackend != types.BackendOllama {
+		t.Errorf("Expected a forced simple dry run on %s, got %s via %s on %s",
+			types.BackendOllama, dry.Classification.Tier, dry.Classification.Source, dry.ActualBackend)
+	}
+
+	result, err := c.Run(context.Background(), "Fix typo in README", "The heading says 'Instalation'", opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Status != types.StatusApproved || result.ActualBackend != types.BackendOllama || len(result.ValidationResults) != 1 {
+		t.Errorf("Expected an approved simple-tier run on %s, got %s on %s (%s)",
+			types.BackendOllama, result.Status, result.ActualBackend, result.Error)
+	}
+	if len(descriptions) != 1 || descriptions[0] != "The heading says 'Instalation'" {
+		t.Errorf("Expected the description to reach the worker, got %q", descriptions)
+	}
+
+	task, err := l.GetTask(result.TaskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.Tier != int(types.TierSimple) {
+		t.Errorf("Expected the task to be stored at the forced tier, got T%d", task.Tier)
+	}
+
+	outcomes, err := l.GetClassificationOutcomes()
+	if err != nil {
+		t.Fatalf("GetClassificationOutcomes failed: %v", err)
+	}
+	if len(outcomes) != 1 {
+		t.Fatalf("Expected one outcome, got %d", len(outcomes))
+	}
+	o := outcomes[0]
+	if o.PredictedTier != int(types.TierTrivial) || o.ForcedTier == nil || *o.ForcedTier != int(types.TierSimple) {
+		t.Errorf("Expected the trivial prediction and simple override to be recorded, got %+v", o)
+	}
+	if tier, ok := EffectiveTier(o); !ok || tier != types.TierSimple {
+		t.Errorf("Expected the override to be the effective tier, got %s (%v)", tier, ok)
+	}
+}
+
+func TestConductor_Escalation(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-escalation-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	// Only Claude Haiku can fix anything; ollama:reasoning is not registered
+	var executed []types.Backend
+	newMock := func(b types.Backend, cost float64) *MockWorker {
+		return &MockWorker{
+			BackendType: b,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				executed = append(executed, b)
+				if b != types.BackendClaudeHaiku {
+					return &types.ExecutionResult{Success: false, Error: "model gave up", CostUSD: cost}, nil
+				}
+				return &types.ExecutionResult{Success: true, Output: "fixed", CostUSD: cost}, nil
+			},
+		}
+	}
+	register := func(c *Conductor) {
+		c.RegisterWorker(newMock(types.BackendOllamaFast, 0))
+		c.RegisterWorker(newMock(types.BackendOllama, 0))
+		c.RegisterWorker(newMock(types.BackendClaudeHaiku, 0.01))
+	}
+
+	t.Run("Climbs the ladder", func(t *testing.T) {
+		executed = nil
+		cfg := &config.Config{}
+		cfg.Conductor.MaxRetries = 3
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		register(c)
+
+		res, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		c.Bus().Flush()
+
+		want := []types.Backend{types.BackendOllamaFast, types.BackendOllama, types.BackendClaudeHaiku}
+		if fmt.Sprint(executed) != fmt.Sprint(want) {
+			t.Errorf("Expected attempts on %v, got %v", want, executed)
+		}
+		if res.Status != types.StatusDone || res.ActualBackend != types.BackendClaudeHaiku || res.Attempts != 3 {
+			t.Errorf("Expected success on %s after 3 attempts, got %s on %s after %d (%s)",
+				types.BackendClaudeHaiku, res.Status, res.ActualBackend, res.Attempts, res.Error)
+		}
+		if fmt.Sprint(res.Escalations) != fmt.Sprint(want[1:]) || res.TotalCostUSD != 0.01 {
+			t.Errorf("Unexpected escalations %v costing $%.4f", res.Escalations, res.TotalCostUSD)
+		}
+
+		execs, err := l.GetExecutions(res.TaskID)
+		if err != nil {
+			t.Fatalf("GetExecutions failed: %v", err)
+		}
+		for i, e := range execs {
+			if e.Backend != string(want[i]) || e.Attempt != i+1 {
+				t.Errorf("Execution %d: expected attempt %d on %s, got %d on %s", i, i+1, want[i], e.Attempt, e.Backend)
+			}
+		}
+
+		events, err := l.GetEvents(res.TaskID)
+		if err != nil {
+			t.Fatalf("GetEvents failed: %v", err)
+		}
+		escalated := 0
+		for _, e := range events {
+			if e.Type == bus.TaskEscalated {
+				escalated++
+			}
+		}
+		if escalated != 2 {
+			t.Errorf("Expected 2 %s events, got %d", bus.TaskEscalated, escalated)
+		}
+
+		// Succeeding on Haiku, which no tier routes to, means the task needed one tier more
+		outcomes, err := l.GetClassificationOutcomes()
+		if err != nil {
+			t.Fatalf("GetClassificationOutcomes failed: %v", err)
+		}
+		if o := outcomes[len(outcomes)-1]; o.EffectiveTier == nil || *o.EffectiveTier != int(types.TierSimple) {
+			t.Errorf("Expected effective tier T1, got %v", o.EffectiveTier)
+		}
+
+		stats, err := l.GetEscalationStats(time.Time{})
+		if err != nil {
+			t.Fatalf("GetEscalationStats failed: %v", err)
+		}
+		if len(stats) != 1 || stats[0].Tier != int(types.TierTrivial) || stats[0].Tasks != 1 ||
+			stats[0].Escalated != 1 || stats[0].Rescued != 1 {
+			t.Errorf("Unexpected escalation stats: %+v", stats[0])
+		}
+	})
+
+	t.Run("Disabled by config", func(t *testing.T) {
+		executed = nil
+		cfg := &config.Config{Routing: config.RoutingConfig{"trivial": {Escalation: []string{}}}}
+		cfg.Conductor.MaxRetries = 2
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		register(c)
+
+		res, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		want := []types.Backend{types.BackendOllamaFast, types.BackendOllamaFast, types.BackendOllamaFast}
+		if fmt.Sprint(executed) != fmt.Sprint(want) || res.Status != types.StatusFailed || len(res.Escalations) != 0 {
+			t.Errorf("Expected 3 failed attempts on %s, got %v (%s)", types.BackendOllamaFast, executed, res.Status)
+		}
+	})
+
+	t.Run("Skips rungs over budget", func(t *testing.T) {
+		executed = nil
+		cfg := &config.Config{}
+		cfg.Conductor.MaxRetries = 2
+		cfg.Workers.Claude.CostLimits.PerTaskUSD = 0.001
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.RegisterWorker(newMock(types.BackendOllamaFast, 0))
+		c.RegisterWorker(&MockWorker{
+			BackendType:      types.BackendClaudeHaiku,
+			EstimateCostFunc: func(task *types.Task) float64 { return 0.01 },
+		})
+
+		res, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if len(executed) != 3 || res.ActualBackend != types.BackendOllamaFast || len(res.Escalations) != 0 {
+			t.Errorf("Expected to stay on %s, got %v on %s", types.BackendOllamaFast, executed, res.ActualBackend)
+		}
+	})
+
+	t.Run("Escalated producer does not review itself", func(t *testing.T) {
+		one := 1
+		cfg := &config.Config{Routing: config.RoutingConfig{
+			"simple": {
+				Primary:           "ollama:default",
+				Validator:         "claude:sonnet",
+				ValidatorCount:    &one,
+				RequiredApprovals: &one,
+				Fallbacks:         []string{"ollama:default"},
+				Escalation:        []string{"ollama:default", "claude:sonnet"},
+			},
+		}}
+		cfg.Conductor.MaxRetries = 1
+		c := NewConductor(cfg, l)
+		defer c.Close()
+
+		// Ollama gives up, so the task climbs to Sonnet, the tier's validator,
+		// and Ollama reviews the output in its place
+		var reviewers []types.Backend
+		for _, b := range []types.Backend{types.BackendOllama, types.BackendClaudeSonnet} {
+			c.RegisterWorker(&MockWorker{
+				BackendType: b,
+				ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+					if strings.HasPrefix(task.Title, "Review") {
+						reviewers = append(reviewers, b)
+						return &types.ExecutionResult{Backend: b, Success: true, Output: `{"approved": true}`}, nil
+					}
+					if b == types.BackendOllama {
+						return &types.ExecutionResult{Backend: b, Success: false, Error: "model gave up"}, nil
+					}
+					return &types.ExecutionResult{Backend: b, Success: true, Output: "func helper() {}"}, nil
+				},
+			})
+		}
+
+		tier := types.TierSimple
+		res, err := c.Run(context.Background(), "Add simple function", "", RunOptions{Tier: &tier})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if res.ActualBackend != types.BackendClaudeSonnet {
+			t.Fatalf("Expected the task to escalate to %s, got %s (%s)", types.BackendClaudeSonnet, res.ActualBackend, res.Error)
+		}
+		if res.Status != types.StatusApproved || len(reviewers) != 1 || reviewers[0] != types.BackendOllama {
+			t.Errorf("Expected Ollama to review Sonnet's output, got %s reviewed by %v (%s)", res.Status, reviewers, res.Error)
+		}
+	})
+}
+
+func TestConductor_Context(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"docs/guide.md":     "# Guide\n\nInstall with make.\n",
+		"docs/reference.md": strings.Repeat("Reference text that will not fit.\n", 400),
+		"util/strings.go":   "package util\n\nfunc Slugify(s string) string {\n\treturn s\n}\n",
+		"cmd/tool/main.go":  "package main\n\nfunc main() {\n\tutil.Slugify(\"x\")\n}\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idx, err := repo.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tmpfile, err := os.CreateTemp("", "conductor-context-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Conductor.MaxRetries = 1
+	cfg.Context = config.ContextConfig{
+		Auto:          true,
+		DefaultTokens: 100000,
+		Tokens:        map[string]int{string(types.BackendOllamaFast): 250},
+	}
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	c.SetIndex(idx)
+
+	// The small model fails, so the task escalates to one with a bigger budget
+	prompts := make(map[types.Backend]string)
+	for _, b := range []types.Backend{types.BackendOllamaFast, types.BackendOllama} {
+		b := b
+		c.RegisterWorker(&MockWorker{
+			BackendType: b,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				prompts[b] = task.Context
+				return &types.ExecutionResult{Success: b == types.BackendOllama, Output: "done", Error: "too small"}, nil
+			},
+		})
+	}
+
+	result, err := c.Run(context.Background(), "Fix typo in Slugify", "", RunOptions{Context: []string{"docs"}})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Status != types.StatusDone || result.ActualBackend != types.BackendOllama {
+		t.Fatalf("Expected success on %s, got %s on %s (%s)", types.BackendOllama, result.Status, result.ActualBackend, result.Error)
+	}
+
+	execs, err := l.GetExecutions(result.TaskID)
+	if err != nil || len(execs) != 2 {
+		t.Fatalf("Expected 2 executions, got %d (%v)", len(execs), err)
+	}
+	small, large := execs[0].ContextFiles, execs[1].ContextFiles
+	if len(small) != 2 || small[0] != "docs/guide.md" || !strings.HasPrefix(small[1], "docs/reference.md:1-") {
+		t.Errorf("Expected the guide and the head of the reference on the small budget, got %v", small)
+	}
+	if strings.Join(large, ",") != "docs/guide.md,docs/reference.md,util/strings.go" {
+		t.Errorf("Expected given files, then mentioned ones, on the large budget, got %v", large)
+	}
+	if !strings.Contains(prompts[types.BackendOllama], "func Slugify(s string) string") ||
+		strings.Contains(prompts[types.BackendOllamaFast], "func Slugify") {
+		t.Errorf("Expected Slugify only in the larger prompt")
+	}
+
+	task, err := l.GetTask(result.TaskID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if task.ContextPath != "docs" {
+		t.Errorf("Expected the context patterns to be recorded, got %q", task.ContextPath)
+	}
+
+	if _, err := c.Run(context.Background(), "Fix typo", "", RunOptions{Context: []string{"nowhere/*.go"}}); err == nil {
+		t.Error("Expected an unmatched context pattern to fail the run")
+	}
+}
+
+func TestConductor_Patches(t *testing.T) {
+	root := t.TempDir()
+	original := "package util\n\nfunc Slugify(s string) string {\n\treturn s\n}\n"
+	if err := os.MkdirAll(filepath.Join(root, "util"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "util", "strings.go"), []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := repo.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tmpfile, err := os.CreateTemp("", "conductor-patches-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	cfg := &config.Config{}
+	cfg.Conductor.MaxRetries = 1
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	c.SetIndex(idx)
+
+	// The small model's diff does not match the file; the retry's does
+	var retryPrompt string
+	for _, b := range []types.Backend{types.BackendOllamaFast, types.BackendOllama} {
+		b := b
+		c.RegisterWorker(&MockWorker{
+			BackendType: b,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				removed := "\treturn s\n"
+				if b == types.BackendOllamaFast {
+					removed = "\treturn strings.ToLower(s)\n"
+				} else {
+					retryPrompt = task.Description
+				}
+				output := "Done:\n\n```diff\n--- a/util/strings.go\n+++ b/util/strings.go\n@@ -4 +4 @@\n-" + removed + "+\treturn strings.ReplaceAll(s, \" \", \"-\")\n```\n"
+				return &types.ExecutionResult{Success: true, Output: output}, nil
+			},
+		})
+	}
+
+	result, err := c.Run(context.Background(), "Fix typo in Slugify", "", RunOptions{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Status != types.StatusDone || result.Attempts != 2 {
+		t.Fatalf("Expected success on the second attempt, got %s after %d (%s)", result.Status, result.Attempts, result.Error)
+	}
+	if !strings.Contains(retryPrompt, "patch does not apply: util/strings.go: hunk 1 does not match") {
+		t.Errorf("Expected the retry to be told why the patch failed, got:\n%s", retryPrompt)
+	}
+
+	execs, err := l.GetExecutions(result.TaskID)
+	if err != nil || len(execs) != 2 {
+		t.Fatalf("Expected 2 executions, got %d (%v)", len(execs), err)
+	}
+	if execs[0].Status != "failed" || execs[0].Diff != "" || execs[1].Diff != result.Execution.Diff {
+		t.Errorf("Expected only the second attempt's diff recorded, got %q and %q", execs[0].Diff, execs[1].Diff)
+	}
+
+	patches, err := TaskPatches(l, result.TaskID)
+	if err != nil || len(patches) != 1 {
+		t.Fatalf("Expected one patch, got %d (%v)", len(patches), err)
+	}
+	if patches[0].ExecutionID != execs[1].ID || !strings.Contains(patches[0].Diff, "+\treturn strings.ReplaceAll") {
+		t.Errorf("Unexpected patch: %+v", patches[0])
+	}
+
+	tree, err := StagePatches(root, patches)
+	if err != nil {
+		t.Fatalf("StagePatches failed: %v", err)
+	}
+	if err := tree.Write(); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "util", "strings.go"))
+	if err != nil || !strings.Contains(string(data), "strings.ReplaceAll") {
+		t.Errorf("Expected the change on disk, got:\n%s", data)
+	}
+
+	// Once written, the same patch no longer applies
+	if _, err := StagePatches(root, patches); err == nil {
+		t.Error("Expected the patch to conflict with the changed file")
+	}
+}
+
+func TestConductor_PlannedPatchesOverlap(t *testing.T) {
+	root := t.TempDir()
+	original := "package util\n\nfunc Slugify(s string) string {\n\treturn s\n}\n"
+	if err := os.MkdirAll(filepath.Join(root, "util"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "util", "strings.go"), []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := repo.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tmpfile, err := os.CreateTemp("", "conductor-plan-patches-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	zero := 0
+	route := config.TierRoute{Primary: "ollama:fast", ValidatorCount: &zero, Fallbacks: []string{}, Escalation: []string{}}
+	planned := route
+	planned.Planner = "claude:opus"
+	cfg := &config.Config{Routing: config.RoutingConfig{
+		"trivial": route, "simple": route, "standard": route, "complex": planned, "critical": route,
+	}}
+	c := NewConductor(cfg, l)
+	defer c.Close()
+	c.SetIndex(idx)
+
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendClaudeOpus,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			return &types.ExecutionResult{Success: true, Output: `{"subtasks": [
+				{"title": "Lower-case slugs"}, {"title": "Document Slugify", "depends_on": [1]}]}`}, nil
+		},
+	})
+	// Both steps change util/strings.go; the second cannot see the first's change
+	c.RegisterWorker(&MockWorker{
+		BackendType: types.BackendOllamaFast,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			diff := "--- a/util/strings.go\n+++ b/util/strings.go\n@@ -4 +4 @@\n-\treturn s\n+\treturn strings.ToLower(s)\n"
+			if task.Title == "Document Slugify" {
+				diff = "--- a/util/strings.go\n+++ b/util/strings.go\n@@ -3 +3,2 @@\n+// Slugify makes s fit for a URL\n func Slugify(s string) string {\n"
+			}
+			return &types.ExecutionResult{Success: true, Output: "```diff\n" + diff + "```\n"}, nil
+		},
+	})
+
+	tier := types.TierComplex
+	result, err := c.Run(context.Background(), "Redesign the slug helpers", "", RunOptions{Tier: &tier})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Subtasks) != 2 || !succeeded(result.Subtasks[0].Status) || !succeeded(result.Subtasks[1].Status) {
+		t.Fatalf("Expected both subtasks to succeed, got %+v (%s)", result.Subtasks, result.Error)
+	}
+	if result.Status != types.StatusFailed || !strings.Contains(result.Error, "both change util/strings.go") {
+		t.Errorf("Expected the overlapping subtasks to fail the task, got %s (%s)", result.Status, result.Error)
+	}
+
+	// Applying the task refuses the combination rather than a hunk
+	patches, err := TaskPatches(l, result.TaskID)
+	if err != nil || len(patches) != 2 {
+		t.Fatalf("Expected 2 patches, got %d (%v)", len(patches), err)
+	}
+	if _, err := StagePatches(root, patches); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("Expected StagePatches to refuse overlapping subtasks, got %v", err)
+	}
+}
+
+func TestConductor_TestValidator(t *testing.T) {
+	root := t.TempDir()
+	original := "package util\n\nfunc Slugify(s string) string {\n\treturn s\n}\n"
+	if err := os.MkdirAll(filepath.Join(root, "util"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "util", "strings.go"), []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := repo.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tmpfile, err := os.CreateTemp("", "conductor-tests-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	diff := func(body string) string {
+		return "```diff\n--- a/util/strings.go\n+++ b/util/strings.go\n@@ -4 +4 @@\n-\treturn s\n+\treturn " + body + "\n```\n"
+	}
+	const command = `grep -q ReplaceAll util/strings.go || { echo "util/strings.go:4: want dashes"; exit 1; }`
+
+	t.Run("veto", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Conductor.MaxRetries = 1
+		cfg.Validators.Tests.Command = command
+		c := NewConductor(cfg, l)
+		defer c.Close()
+		c.SetIndex(idx)
+
+		// The first change fails the tests; the retry is told why
+		var prompts []string
+		for _, b := range []types.Backend{types.BackendOllamaFast, types.BackendOllama} {
+			c.RegisterWorker(&MockWorker{
+				BackendType: b,
+				ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+					prompts = append(prompts, task.Description)
+					body := "strings.ToLower(s)"
+					if len(prompts) > 1 {
+						body = `strings.ReplaceAll(s, " ", "-")`
+					}
+					return &types.ExecutionResult{Success: true, Output: diff(body)}, nil
+				},
+			})
+		}
+
+		result, err := c.Run(context.Background(), "Fix typo in Slugify", "", RunOptions{})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Status != types.StatusApproved || result.Attempts != 2 {
+			t.Fatalf("Expected success on the second attempt, got %s after %d (%s)", result.Status, result.Attempts, result.Error)
+		}
+		if len(prompts) != 2 || !strings.Contains(prompts[1], "util/strings.go:4") || !strings.Contains(prompts[1], "want dashes") {
+			t.Errorf("Expected the retry to see the test failure, got:\n%s", prompts[len(prompts)-1])
+		}
+
+		execs, err := l.GetExecutions(result.TaskID)
+		if err != nil || len(execs) != 2 {
+			t.Fatalf("Expected 2 executions, got %d (%v)", len(execs), err)
+		}
+		for i, verdict := range []string{"rejected", "approved"} {
+			vals, err := l.GetValidations(execs[i].ID)
+			if err != nil || len(vals) != 1 {
+				t.Fatalf("Expected 1 validation for attempt %d, got %d (%v)", i+1, len(vals), err)
+			}
+			if vals[0].ValidatorID != validators.CommandValidatorID || vals[0].Verdict != verdict {
+				t.Errorf("Attempt %d: expected the tests to have %s, got %+v", i+1, verdict, vals[0])
+			}
+		}
+		if data, _ := os.ReadFile(filepath.Join(root, "util", "strings.go")); string(data) != original {
+			t.Errorf("Expected the working tree untouched, got:\n%s", data)
+		}
+	})
+
+	t.Run("vote", func(t *testing.T) {
+		simple := types.TierSimple
+		for _, mode := range []string{config.TestsVote, config.TestsVeto} {
+			cfg := &config.Config{}
+			cfg.Validators.Tests.Command = command
+			cfg.Validators.Tests.Mode = mode
+			c := NewConductor(cfg, l)
+			c.SetIndex(idx)
+
+			// The reviewer rejects a change the tests pass
+			c.RegisterWorker(&MockWorker{
+				BackendType: types.BackendOllama,
+				ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+					if strings.HasPrefix(task.Title, "Review") {
+						return &types.ExecutionResult{Success: true, Output: `{"approved": false}`}, nil
+					}
+					return &types.ExecutionResult{Success: true, Output: diff(`strings.ReplaceAll(s, " ", "-")`)}, nil
+				},
+			})
+
+			result, err := c.Run(context.Background(), "Fix typo in Slugify", "", RunOptions{Tier: &simple})
+			c.Close()
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if mode == config.TestsVote && result.Status != types.StatusApproved {
+				t.Errorf("Expected passing tests to outvote the reviewer, got %s (%s)", result.Status, result.Error)
+			}
+			if mode == config.TestsVeto && result.Status == types.StatusApproved {
+				t.Error("Expected passing tests not to approve on their own in veto mode")
+			}
+		}
+	})
+}
+
+// streamingWorker is a MockWorker that can also stream its output
+type streamingWorker struct {
+	*MockWorker
+	chunks []string
+}
+
+func (w *streamingWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	for _, chunk := range w.chunks {
+		onChunk(chunk)
+	}
+	return &types.ExecutionResult{Success: true, Output: strings.Join(w.chunks, ""), TokensUsed: 7}, nil
+}
+
+func TestConductor_Streaming(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "conductor-streaming-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := ledger.Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Ledger init failed: %v", err)
+	}
+	defer l.Close()
+
+	c := NewConductor(&config.Config{}, l)
+	defer c.Close()
+	c.RegisterWorker(&streamingWorker{
+		MockWorker: &MockWorker{
+			BackendType: types.BackendOllamaFast,
+			ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+				return &types.ExecutionResult{Success: true, Output: "all at once"}, nil
+			},
+		},
+		chunks: []string{"Fixed ", "the ", "typo."},
+	})
+
+	var mu sync.Mutex
+	var chunks []string
+	c.Bus().Subscribe(func(msg types.Message) {
+		if msg.Type != bus.ExecutionChunk {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if msg.Payload["attempt"] != 1 || msg.Payload["backend"] != string(types.BackendOllamaFast) {
+			t.Errorf("Unexpected chunk payload: %v", msg.Payload)
+		}
+		chunks = append(chunks, msg.Payload["text"].(string))
+	})
+
+	// Without Stream the worker runs as usual
+	result, err := c.Run(context.Background(), "Fix typo in README", "", RunOptions{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	c.Bus().Flush()
+	if result.Execution.Output != "all at once" || len(chunks) != 0 {
+		t.Errorf("Expected no streaming, got output %q and %d chunk(s)", result.Execution.Output, len(chunks))
+	}
+
+	result, err = c.Run(context.Background(), "Fix typo in README", "", RunOptions{Stream: true})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	c.Bus().Flush()
+	if result.Status != types.StatusDone || result.Execution.Output != "Fixed the typo." || result.Execution.TokensUsed != 7 {
+		t.Errorf("Unexpected result: %s, %+v", result.Status, result.Execution)
+	}
+	mu.Lock()
+	if strings.Join(chunks, "") != "Fixed the typo." {
+		t.Errorf("Expected the chunks on the bus, got %q", chunks)
+	}
+	mu.Unlock()
+
+	// Chunks stay out of the ledger's event log
+	events, err := l.GetEvents(result.TaskID)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	for _, e := range events {
+		if e.Type == bus.ExecutionChunk {
+			t.Fatal("Expected chunks not to be recorded")
+		}
+	}
+}
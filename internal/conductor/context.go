@@ -0,0 +1,71 @@
+package conductor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cammy/bigo/internal/repo"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// contextSources lists the files to pack into a task's prompt: those given
+// in patterns first, in the order given, then the files the task mentions
+// when auto-detection is enabled, most specific mentions first
+func (c *Conductor) contextSources(title, description string, patterns []string) ([]repo.Source, error) {
+	idx := c.classifier.Index()
+	if idx == nil {
+		if len(patterns) > 0 {
+			return nil, fmt.Errorf("context files were given but no working tree was scanned")
+		}
+		return nil, nil
+	}
+
+	var sources []repo.Source
+	for _, p := range patterns {
+		paths, err := idx.Expand(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid context: %w", err)
+		}
+		for _, path := range paths {
+			sources = append(sources, repo.Source{Path: path})
+		}
+	}
+
+	if c.config.Context.Auto {
+		mentions := idx.Mentions(title + " " + description)
+		sort.SliceStable(mentions, func(i, j int) bool {
+			return mentionRank(mentions[i]) < mentionRank(mentions[j])
+		})
+		for _, m := range mentions {
+			sources = append(sources, repo.Source{Path: m.File.Path, Decl: m.Decl})
+		}
+	}
+	return sources, nil
+}
+
+// mentionRank orders mentions by how precisely they point at a file: by
+// path, then by a declaration in it, then by its package
+func mentionRank(m repo.Mention) int {
+	switch {
+	case m.Decl != nil:
+		return 1
+	case strings.HasPrefix(m.Via, "package "):
+		return 2
+	default:
+		return 0
+	}
+}
+
+// packContext packs the sources into the task's prompt, within the context
+// budget of the backend the task is assigned to, and returns what was packed
+func (c *Conductor) packContext(task *types.Task, sources []repo.Source) *repo.Context {
+	task.Context = ""
+	idx := c.classifier.Index()
+	if len(sources) == 0 || idx == nil {
+		return nil
+	}
+	packed := repo.Pack(idx.Root, sources, c.config.Context.Budget(string(task.Backend)))
+	task.Context = packed.Render()
+	return packed
+}
@@ -0,0 +1,75 @@
+package conductor
+
+import (
+	"fmt"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// escalate moves a task whose last attempt failed or was rejected to the
+// next rung of its tier's escalation ladder that has an available worker
+// within budget. It reports whether the task moved; a task at the top of
+// the ladder, or with no usable rung above it, stays where it is.
+func (c *Conductor) escalate(a *attempts, result *RunResult) bool {
+	from := a.worker.Backend()
+	for _, backend := range rungsAbove(a.tierConfig.Escalation, from) {
+		worker, ok := c.worker(backend)
+		if !ok || !worker.Available() {
+			continue
+		}
+		task := *a.task
+		task.Backend = backend
+		c.packContext(&task, a.sources)
+		if !a.opts.AllowOverspend && c.checkBudget(worker, &task, result.TotalCostUSD, a.opts) != nil {
+			continue
+		}
+
+		a.worker = worker
+		a.task.Backend = backend
+		a.escalated = true
+		result.ActualBackend = backend
+		result.Escalations = append(result.Escalations, backend)
+		if err := c.ledger.RecordEscalation(a.task.ID); err != nil {
+			fmt.Printf("failed to record escalation: %v\n", err)
+		}
+		c.publish(a.task.ID, bus.TaskEscalated, map[string]interface{}{
+			"from": string(from),
+			"to":   string(backend),
+		})
+		return true
+	}
+	return false
+}
+
+// rungsAbove returns the rungs of a ladder above the given backend, or the
+// whole ladder when the backend is not on it
+func rungsAbove(ladder []types.Backend, backend types.Backend) []types.Backend {
+	for i, b := range ladder {
+		if b == backend {
+			return ladder[i+1:]
+		}
+	}
+	var rungs []types.Backend
+	for _, b := range ladder {
+		if b != backend {
+			rungs = append(rungs, b)
+		}
+	}
+	return rungs
+}
+
+// escalatedTier is the tier an escalated task turned out to need: the lowest
+// tier above its own that plans or executes on the backend it succeeded on,
+// or the next tier up when no tier routes to that backend
+func (c *Conductor) escalatedTier(tier types.Tier, backend types.Backend) types.Tier {
+	for t := tier + 1; t <= types.TierCritical; t++ {
+		if tc := c.tiers[t]; tc.PrimaryBackend == backend || tc.PlannerBackend == backend {
+			return t
+		}
+	}
+	if tier < types.TierCritical {
+		return tier + 1
+	}
+	return tier
+}
@@ -0,0 +1,62 @@
+package conductor
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Bus returns the conductor's message bus so callers can subscribe to lifecycle events
+func (c *Conductor) Bus() *bus.Bus {
+	return c.bus
+}
+
+// Close delivers any pending events and stops the message bus
+func (c *Conductor) Close() {
+	c.bus.Close()
+}
+
+// publish sends a lifecycle event for a task
+func (c *Conductor) publish(taskID, eventType string, payload map[string]interface{}) {
+	c.bus.Publish(types.Message{
+		Type:    eventType,
+		TaskID:  taskID,
+		Payload: payload,
+	})
+}
+
+// setStatus updates a task's status in the ledger and announces the change
+func (c *Conductor) setStatus(taskID string, status types.TaskStatus) error {
+	if err := c.ledger.UpdateTaskStatus(taskID, string(status)); err != nil {
+		return err
+	}
+	c.publish(taskID, bus.TaskStatusChanged, map[string]interface{}{
+		"status": string(status),
+	})
+	return nil
+}
+
+// recordEvent is the bus subscriber that appends every event to the ledger's
+// event log. Output chunks are left out; the execution keeps the whole output.
+func (c *Conductor) recordEvent(msg types.Message) {
+	if msg.Type == bus.ExecutionChunk {
+		return
+	}
+
+	payload, err := json.Marshal(msg.Payload)
+	if err != nil {
+		fmt.Printf("failed to encode %s event: %v\n", msg.Type, err)
+		return
+	}
+
+	if err := c.ledger.RecordEvent(&ledger.Event{
+		TaskID:  msg.TaskID,
+		Type:    msg.Type,
+		Payload: string(payload),
+	}); err != nil {
+		fmt.Printf("failed to record %s event: %v\n", msg.Type, err)
+	}
+}
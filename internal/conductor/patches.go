@@ -0,0 +1,108 @@
+package conductor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/patch"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// extractPatch collects the file changes in a successful execution as a diff
+// against the working tree. Changes that do not apply fail the execution,
+// so the next attempt is told why.
+func (c *Conductor) extractPatch(r *types.ExecutionResult) {
+	idx := c.classifier.Index()
+	if idx == nil || !r.Success {
+		return
+	}
+	source := r.Diff
+	if source == "" {
+		source = r.Output
+	}
+	diff, err := patch.Extract(idx.Root, source)
+	if err != nil {
+		r.Success = false
+		r.Error = fmt.Sprintf("patch does not apply: %v", err)
+		return
+	}
+	r.Diff = diff
+}
+
+// TaskPatch is the change one task's execution makes to the working tree
+type TaskPatch struct {
+	TaskID      string
+	ExecutionID string
+	Diff        string
+	AppliedAt   *time.Time
+}
+
+// TaskPatches returns the changes a task makes: the diff of its last
+// completed attempt or, for a planned task, those of its subtasks in order
+func TaskPatches(l *ledger.Ledger, taskID string) ([]*TaskPatch, error) {
+	subtasks, err := l.GetSubtasks(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subtasks) > 0 {
+		var patches []*TaskPatch
+		for _, sub := range subtasks {
+			p, err := TaskPatches(l, sub.ID)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, p...)
+		}
+		return patches, nil
+	}
+
+	executions, err := l.GetExecutions(taskID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(executions) - 1; i >= 0; i-- {
+		e := executions[i]
+		if e.Kind != ledger.KindAttempt || e.Status != "completed" {
+			continue
+		}
+		if e.Diff == "" {
+			return nil, nil
+		}
+		return []*TaskPatch{{TaskID: taskID, ExecutionID: e.ID, Diff: e.Diff, AppliedAt: e.AppliedAt}}, nil
+	}
+	return nil, nil
+}
+
+// StagePatches applies patches in order to an in-memory copy of the tree
+// under root. Nothing is written; a conflict in any patch fails them all, as
+// do patches from different tasks changing the same file.
+func StagePatches(root string, patches []*TaskPatch) (*patch.Tree, error) {
+	if err := checkOverlap(patches); err != nil {
+		return nil, err
+	}
+	tree := patch.NewTree(root)
+	for _, p := range patches {
+		if err := tree.Apply(p.Diff); err != nil {
+			return nil, fmt.Errorf("task %s: %w", p.TaskID, err)
+		}
+	}
+	return tree, nil
+}
+
+// checkOverlap refuses patches from different tasks that change the same
+// file. A plan's subtasks are each made, and validated, against the working
+// tree as it was, so none of them sees another's change to a file they share.
+func checkOverlap(patches []*TaskPatch) error {
+	changedBy := make(map[string]string)
+	for _, p := range patches {
+		for _, stat := range patch.Stats(p.Diff) {
+			if other, ok := changedBy[stat.Path]; ok && other != p.TaskID {
+				return fmt.Errorf("subtasks %s and %s both change %s, each against the original file, so their changes cannot be combined; rerun the task with --tier standard to make the change in one step",
+					other, p.TaskID, stat.Path)
+			}
+			changedBy[stat.Path] = p.TaskID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package conductor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/validators"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// plannerTitle is the title of the request sent to a tier's planner backend
+const plannerTitle = "Break the task below into subtasks and return a JSON plan"
+
+// maxSubtasks caps the size of a plan; larger plans are rejected
+const maxSubtasks = 8
+
+// planStep is one subtask in a planner's response
+type planStep struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	DependsOn   []int  `json:"depends_on"` // 1-based positions of prerequisite steps
+}
+
+// plan asks the planner backend to split a task into subtasks. It records the
+// planner call as an execution of the task and adds its cost to the result.
+func (c *Conductor) plan(ctx context.Context, task *types.Task, backend types.Backend, opts RunOptions, result *RunResult) ([]planStep, error) {
+	planner, ok := c.worker(backend)
+	if !ok || !planner.Available() {
+		return nil, fmt.Errorf("no available planner for backend %s", backend)
+	}
+
+	planTask := &types.Task{
+		ID:          task.ID,
+		Title:       plannerTitle,
+		Description: buildPlanPrompt(task),
+		Tier:        task.Tier,
+		Backend:     backend,
+	}
+	execID := generateID()
+	if err := c.reserve(execID, backend, estimateCost(planner, planTask), result.TotalCostUSD, opts); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	execResult, err := planner.Execute(ctx, planTask)
+	if err != nil {
+		c.release(execID)
+		return nil, err
+	}
+	result.TotalCostUSD += execResult.CostUSD
+
+	exec := &ledger.Execution{
+		ID:           execID,
+		TaskID:       task.ID,
+		WorkerID:     execResult.WorkerID,
+		Kind:         ledger.KindPlanner,
+		Backend:      string(backend),
+		Output:       execResult.Output,
+		TokensUsed:   execResult.TokensUsed,
+		InputTokens:  execResult.Usage.Input(),
+		OutputTokens: execResult.Usage.OutputTokens,
+		CostUSD:      execResult.CostUSD,
+		DurationMs:   int(time.Since(start).Milliseconds()),
+		Status:       "completed",
+	}
+	if !execResult.Success {
+		exec.Status = "failed"
+		exec.ErrorMsg = execResult.Error
+	}
+	if err := c.recordExecution(exec); err != nil {
+		return nil, fmt.Errorf("failed to record plan: %w", err)
+	}
+
+	if !execResult.Success {
+		return nil, fmt.Errorf("planner failed: %s", execResult.Error)
+	}
+
+	steps, err := parsePlan(execResult.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	c.publish(task.ID, bus.TaskPlanned, map[string]interface{}{
+		"backend":  string(backend),
+		"subtasks": len(steps),
+	})
+	return steps, nil
+}
+
+// runPlan splits a task with the planner backend and runs the steps as its
+// subtasks. It reports false, with a warning on the result, when the task
+// should run as a whole instead. A run interrupted while planning returns
+// the context's error and leaves the task in flight, for the sweep to orphan
+// and a later resume to plan again.
+func (c *Conductor) runPlan(ctx context.Context, parent *types.Task, backend types.Backend, opts RunOptions, result *RunResult) (bool, error) {
+	steps, err := c.plan(ctx, parent, backend, opts, result)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return false, ctx.Err()
+	case err != nil:
+		result.Warnings = append(result.Warnings, fmt.Sprintf("planning skipped: %v", err))
+		return false, nil
+	case len(steps) < 2:
+		return false, nil
+	}
+
+	if err := c.setStatus(parent.ID, types.StatusWorking); err != nil {
+		return false, fmt.Errorf("failed to update task status: %w", err)
+	}
+	result.ActualBackend = backend
+	result.PlannerBackend = backend
+	c.runSubtasks(ctx, parent, steps, nil, opts, result)
+	return true, nil
+}
+
+// runSubtasks runs each step as a subtask of parent, starting a step as soon
+// as the steps it depends on have succeeded, and aggregates the outcome into
+// result. existing holds subtasks already created for the steps when a plan
+// is resumed; it may be nil.
+func (c *Conductor) runSubtasks(ctx context.Context, parent *types.Task, steps []planStep, existing []*ledger.Task, opts RunOptions, result *RunResult) {
+	results := make([]*RunResult, len(steps))
+	finished := make([]chan struct{}, len(steps))
+	for i := range finished {
+		finished[i] = make(chan struct{})
+	}
+
+	subOpts := opts
+	subOpts.taskID = ""
+	subOpts.parentID = parent.ID
+	subOpts.Tier = nil
+
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		go func(i int, step planStep) {
+			defer wg.Done()
+			defer close(finished[i])
+
+			// Plans are acyclic, so waiting on prerequisites cannot deadlock
+			var prerequisites []*RunResult
+			for _, d := range step.DependsOn {
+				<-finished[d-1]
+				prerequisites = append(prerequisites, results[d-1])
+			}
+
+			if existing != nil && existing[i] != nil {
+				results[i] = c.continueSubtask(ctx, existing[i], subOpts)
+				return
+			}
+
+			for n, dep := range prerequisites {
+				if !succeeded(dep.Status) {
+					results[i] = c.skipSubtask(parent.ID, step, fmt.Sprintf("skipped: prerequisite subtask %d (%s) did not succeed", step.DependsOn[n], dep.Title))
+					return
+				}
+			}
+
+			stepOpts := subOpts
+			stepOpts.background = buildSubtaskBackground(parent, prerequisites)
+			r, err := c.Run(ctx, step.Title, step.Description, stepOpts)
+			if err != nil {
+				r = &RunResult{
+					Title:    step.Title,
+					ParentID: parent.ID,
+					Status:   types.StatusFailed,
+					Error:    err.Error(),
+				}
+			}
+			results[i] = r
+		}(i, step)
+	}
+	wg.Wait()
+
+	result.Subtasks = results
+
+	failed := 0
+	var tokens int
+	var usage types.Usage
+	for _, r := range results {
+		result.TotalCostUSD += r.TotalCostUSD
+		if r.Execution != nil {
+			tokens += r.Execution.TokensUsed
+			usage = usage.Add(r.Execution.Usage)
+		}
+		if !succeeded(r.Status) {
+			failed++
+		}
+	}
+
+	// Every subtask changed the tree as it was, so their changes only combine
+	// when they touch different files
+	var overlap error
+	if failed == 0 {
+		var patches []*TaskPatch
+		for _, r := range results {
+			if r.Execution != nil && r.Execution.Diff != "" {
+				patches = append(patches, &TaskPatch{TaskID: r.TaskID, Diff: r.Execution.Diff})
+			}
+		}
+		overlap = checkOverlap(patches)
+	}
+
+	result.Execution = &types.ExecutionResult{
+		TaskID:     parent.ID,
+		Success:    failed == 0 && overlap == nil,
+		Output:     buildAggregateOutput(results),
+		TokensUsed: tokens,
+		Usage:      usage,
+		CostUSD:    result.TotalCostUSD,
+	}
+	switch {
+	case failed > 0:
+		result.Status = types.StatusFailed
+		result.Error = fmt.Sprintf("%d of %d subtasks did not succeed", failed, len(results))
+	case overlap != nil:
+		result.Status = types.StatusFailed
+		result.Error = overlap.Error()
+	default:
+		result.Status = types.StatusDone
+	}
+
+	// Keep the combined output on the parent so it can be read back as one result
+	exec := &ledger.Execution{
+		ID:      generateID(),
+		TaskID:  parent.ID,
+		Kind:    ledger.KindAggregate,
+		Backend: "conductor",
+		Output:  result.Execution.Output,
+		Status:  "completed",
+	}
+	if result.Status == types.StatusFailed {
+		exec.Status = "failed"
+		exec.ErrorMsg = result.Error
+	}
+	if err := c.ledger.CreateExecution(exec); err != nil {
+		fmt.Printf("failed to record aggregate output: %v\n", err)
+	}
+}
+
+// skipSubtask records a subtask that was not run because a prerequisite failed
+func (c *Conductor) skipSubtask(parentID string, step planStep, reason string) *RunResult {
+	task := &ledger.Task{
+		ID:          generateID(),
+		ParentID:    &parentID,
+		Title:       step.Title,
+		Description: step.Description,
+		Status:      string(types.StatusFailed),
+	}
+	if err := c.ledger.CreateTask(task); err != nil {
+		fmt.Printf("failed to record skipped subtask: %v\n", err)
+	} else {
+		c.publish(task.ID, bus.TaskCreated, map[string]interface{}{
+			"title":     step.Title,
+			"parent_id": parentID,
+		})
+		c.publish(task.ID, bus.TaskStatusChanged, map[string]interface{}{
+			"status": string(types.StatusFailed),
+		})
+	}
+
+	now := time.Now()
+	return &RunResult{
+		TaskID:    task.ID,
+		Title:     step.Title,
+		ParentID:  parentID,
+		Status:    types.StatusFailed,
+		Error:     reason,
+		StartTime: now,
+		EndTime:   now,
+	}
+}
+
+// succeeded reports whether a task finished with an accepted result
+func succeeded(s types.TaskStatus) bool {
+	return s == types.StatusDone || s == types.StatusApproved
+}
+
+// parsePlan extracts and checks the subtasks in a planner response
+func parsePlan(output string) ([]planStep, error) {
+	text, err := validators.ExtractJSONObject(output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+
+	var resp struct {
+		Subtasks []planStep `json:"subtasks"`
+	}
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return nil, fmt.Errorf("invalid plan JSON: %w", err)
+	}
+
+	steps := resp.Subtasks
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("plan has no subtasks")
+	}
+	if len(steps) > maxSubtasks {
+		return nil, fmt.Errorf("plan has %d subtasks (at most %d allowed)", len(steps), maxSubtasks)
+	}
+
+	for i, step := range steps {
+		if strings.TrimSpace(step.Title) == "" {
+			return nil, fmt.Errorf("subtask %d has no title", i+1)
+		}
+		for _, d := range step.DependsOn {
+			if d < 1 || d > len(steps) || d == i+1 {
+				return nil, fmt.Errorf("subtask %d has an invalid dependency %d", i+1, d)
+			}
+		}
+	}
+	if hasCycle(steps) {
+		return nil, fmt.Errorf("plan dependencies form a cycle")
+	}
+
+	return steps, nil
+}
+
+// hasCycle reports whether the steps' dependencies contain a cycle
+func hasCycle(steps []planStep) bool {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(steps))
+
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch state[i] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[i] = visiting
+		for _, d := range steps[i].DependsOn {
+			if visit(d - 1) {
+				return true
+			}
+		}
+		state[i] = visited
+		return false
+	}
+
+	for i := range steps {
+		if visit(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildPlanPrompt(task *types.Task) string {
+	prompt := fmt.Sprintf(`You are planning a software engineering task. Break it into smaller
+subtasks that can each be completed and reviewed on their own.
+
+## Task
+%s
+
+`, task.Title)
+
+	if task.Description != "" {
+		prompt += fmt.Sprintf(`## Details
+%s
+
+`, task.Description)
+	}
+
+	prompt += fmt.Sprintf(`## Instructions
+- Use between 2 and %d subtasks; return a single subtask if the task cannot be usefully split
+- Give each subtask a short imperative title and describe exactly what it must change
+- List in depends_on the numbers (starting at 1) of the subtasks whose results it needs
+- Subtasks that do not depend on each other may run in parallel
+- Respond with a single JSON object and nothing else, in this format:
+
+{"subtasks": [{"title": "...", "description": "...", "depends_on": []}]}
+`, maxSubtasks)
+
+	return prompt
+}
+
+func buildSubtaskBackground(parent *types.Task, prerequisites []*RunResult) string {
+	var b strings.Builder
+	b.WriteString("## Parent Task\n")
+	fmt.Fprintf(&b, "This is one part of a larger task: %s\n", parent.Title)
+	if parent.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", parent.Description)
+	}
+
+	if len(prerequisites) > 0 {
+		b.WriteString("\n## Results of Prerequisite Subtasks\n")
+		for _, p := range prerequisites {
+			fmt.Fprintf(&b, "\n### %s\n", p.Title)
+			if p.Execution != nil {
+				b.WriteString(p.Execution.Output)
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func buildAggregateOutput(results []*RunResult) string {
+	var b strings.Builder
+	for i, r := range results {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## Subtask %d: %s (%s)\n\n", i+1, r.Title, r.Status)
+		switch {
+		case r.Execution != nil && r.Execution.Output != "":
+			b.WriteString(r.Execution.Output)
+			b.WriteString("\n")
+		case r.Error != "":
+			fmt.Fprintf(&b, "Error: %s\n", r.Error)
+		}
+	}
+	return b.String()
+}
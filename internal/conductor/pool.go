@@ -0,0 +1,85 @@
+package conductor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cammy/bigo/internal/workers"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// pooledWorker runs each call on a worker leased from a backend pool, so
+// concurrent tasks queue instead of exceeding the backend's max_concurrent
+type pooledWorker struct {
+	pool *workers.Pool
+}
+
+// Execute waits for a free worker, runs the task on it and returns it to the pool
+func (p *pooledWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+	w, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for a %s worker: %w", p.pool.Backend(), err)
+	}
+	defer p.pool.Release(w)
+
+	result, err := w.Execute(ctx, task)
+	setWorkerID(result, w)
+	return result, err
+}
+
+// ExecuteStream is Execute for callers that want output as it is generated.
+// Workers that cannot stream run the task as usual and send no chunks.
+func (p *pooledWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	w, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for a %s worker: %w", p.pool.Backend(), err)
+	}
+	defer p.pool.Release(w)
+
+	var result *types.ExecutionResult
+	if s, ok := w.(Streamer); ok {
+		result, err = s.ExecuteStream(ctx, task, onChunk)
+	} else {
+		result, err = w.Execute(ctx, task)
+	}
+	setWorkerID(result, w)
+	return result, err
+}
+
+// setWorkerID records which of the pool's workers produced a result, for
+// workers that have an ID
+func setWorkerID(result *types.ExecutionResult, w workers.Worker) {
+	if id, ok := w.(interface{ ID() string }); ok && result != nil {
+		result.WorkerID = id.ID()
+	}
+}
+
+// Available reports whether the backend has any workers. A saturated pool is
+// still available; callers queue for it rather than falling back.
+func (p *pooledWorker) Available() bool {
+	return p.pool.Size() > 0
+}
+
+// Backend returns the pool's backend type
+func (p *pooledWorker) Backend() types.Backend {
+	return p.pool.Backend()
+}
+
+// CheckQuota checks quota using any worker in the pool
+func (p *pooledWorker) CheckQuota(ctx context.Context) error {
+	ws := p.pool.Workers()
+	if len(ws) == 0 {
+		return fmt.Errorf("no workers registered for %s", p.pool.Backend())
+	}
+	return ws[0].CheckQuota(ctx)
+}
+
+// EstimateCost prices the task using the pool's workers, which share a model
+func (p *pooledWorker) EstimateCost(task *types.Task) float64 {
+	for _, w := range p.pool.Workers() {
+		if e, ok := w.(CostEstimator); ok {
+			return e.EstimateCost(task)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,393 @@
+package conductor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	defaultStaleAfter        = 2 * time.Minute
+)
+
+// startHeartbeat keeps a task's heartbeat fresh until the returned function is called
+func (c *Conductor) startHeartbeat(taskID string) func() {
+	interval := parseDuration(c.config.Conductor.HeartbeatInterval)
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.ledger.Heartbeat(taskID); err != nil {
+					fmt.Printf("failed to record heartbeat: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// Sweep marks in-flight tasks whose status and heartbeat have not changed for
+// the configured stale_after period as orphaned, and returns them
+func (c *Conductor) Sweep() ([]*ledger.Task, error) {
+	staleAfter := parseDuration(c.config.Conductor.StaleAfter)
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+
+	stale, err := c.ledger.GetStaleTasks(time.Now().Add(-staleAfter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stale tasks: %w", err)
+	}
+
+	var orphaned []*ledger.Task
+	for _, task := range stale {
+		// Skip tasks that changed since we looked; their process is alive
+		claimed, err := c.ledger.ClaimTask(task.ID, task.Status, string(types.StatusOrphaned))
+		if err != nil {
+			return orphaned, fmt.Errorf("failed to mark task %s orphaned: %w", task.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+		c.publish(task.ID, bus.TaskStatusChanged, map[string]interface{}{
+			"status":   string(types.StatusOrphaned),
+			"previous": task.Status,
+		})
+		task.Status = string(types.StatusOrphaned)
+		orphaned = append(orphaned, task)
+	}
+	return orphaned, nil
+}
+
+// OrphanedTasks returns the IDs of orphaned tasks to resume, oldest first.
+// Subtasks of an orphaned parent are left out; resuming the parent covers them.
+func (c *Conductor) OrphanedTasks() ([]string, error) {
+	tasks, err := c.ledger.GetTasksByStatus(string(types.StatusOrphaned))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphaned tasks: %w", err)
+	}
+
+	orphaned := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		orphaned[t.ID] = true
+	}
+
+	var ids []string
+	for _, t := range tasks {
+		if t.ParentID != nil && orphaned[*t.ParentID] {
+			continue
+		}
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// Resume picks up an orphaned task from what the ledger recorded. An output
+// that was never reviewed is validated, and one that was keeps its recorded
+// verdict; a rejected or failed task is retried with the attempts left in
+// its retry budget. A planned task resumes its orphaned subtasks and runs the
+// steps of its plan that never started, and one interrupted while planning
+// is planned again.
+func (c *Conductor) Resume(ctx context.Context, taskID string, opts RunOptions) (*RunResult, error) {
+	task, err := c.ledger.GetTask(taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("task %s not found", taskID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	if task.Status != string(types.StatusOrphaned) {
+		return nil, fmt.Errorf("task %s is %s; only orphaned tasks can be resumed", taskID, task.Status)
+	}
+
+	claimed, err := c.ledger.ClaimTask(taskID, string(types.StatusOrphaned), string(types.StatusWorking))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+	if !claimed {
+		return nil, fmt.Errorf("task %s was resumed by another process", taskID)
+	}
+	c.publish(taskID, bus.TaskStatusChanged, map[string]interface{}{
+		"status": string(types.StatusWorking),
+	})
+	defer c.startHeartbeat(taskID)()
+
+	execs, err := c.ledger.GetExecutions(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	tier := types.Tier(task.Tier)
+	tierConfig := c.tiers[tier]
+	result := &RunResult{
+		TaskID: task.ID,
+		Title:  task.Title,
+		Classification: &types.ClassificationResult{
+			Tier:               tier,
+			RecommendedBackend: types.Backend(task.WorkerBackend),
+			Reasoning:          "resumed from ledger",
+		},
+		StartTime: time.Now(),
+	}
+	if task.ParentID != nil {
+		result.ParentID = *task.ParentID
+		// A subtask still counts towards its parent's per-task limit
+		opts.parentID = *task.ParentID
+	}
+
+	var attemptExecs []*ledger.Execution
+	var plan *ledger.Execution
+	for _, e := range execs {
+		switch e.Kind {
+		case ledger.KindPlanner:
+			plan = e
+		case ledger.KindAttempt:
+			attemptExecs = append(attemptExecs, e)
+		}
+		if e.Kind != ledger.KindAggregate {
+			result.TotalCostUSD += e.CostUSD
+		}
+	}
+
+	parent := &types.Task{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Tier:        tier,
+	}
+
+	// A planned task carries on with its plan
+	if plan != nil && plan.Status == "completed" && len(attemptExecs) == 0 {
+		if steps, err := parsePlan(plan.Output); err == nil && len(steps) > 1 {
+			children, err := c.ledger.GetSubtasks(taskID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load subtasks: %w", err)
+			}
+			result.ActualBackend = types.Backend(plan.Backend)
+			result.PlannerBackend = types.Backend(plan.Backend)
+			c.runSubtasks(ctx, parent, steps, matchSubtasks(steps, children), opts, result)
+			return c.finish(result), nil
+		}
+	}
+
+	// One interrupted before its plan was recorded plans again
+	if (plan == nil || plan.Status != "completed") && len(attemptExecs) == 0 &&
+		task.ParentID == nil && tierConfig.PlannerBackend != "" {
+		planned, err := c.runPlan(ctx, parent, tierConfig.PlannerBackend, opts, result)
+		if err != nil {
+			return nil, err
+		}
+		if planned {
+			return c.finish(result), nil
+		}
+	}
+
+	// Otherwise continue on the backend that ran the last attempt
+	backend := types.Backend(task.WorkerBackend)
+	if n := len(attemptExecs); n > 0 {
+		backend = types.Backend(attemptExecs[n-1].Backend)
+	}
+	worker, ok := c.worker(backend)
+	if !ok || !worker.Available() {
+		worker = c.findFallbackWorker(tier)
+		if worker == nil {
+			result.Error = "no available worker for this task tier"
+			result.Status = types.StatusFailed
+			return c.finish(result), nil
+		}
+	}
+	result.ActualBackend = worker.Backend()
+
+	// Subtasks lose their prerequisites' outputs, but keep the parent's context
+	description := task.Description
+	if task.ParentID != nil {
+		if p, err := c.ledger.GetTask(*task.ParentID); err == nil {
+			description += "\n\n" + buildSubtaskBackground(&types.Task{Title: p.Title, Description: p.Description}, nil)
+		}
+	}
+
+	var patterns []string
+	if task.ContextPath != "" {
+		patterns = strings.Split(task.ContextPath, ",")
+	}
+	sources, err := c.contextSources(task.Title, task.Description, patterns)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("context skipped: %v", err))
+	}
+
+	a := &attempts{
+		task: &types.Task{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: description,
+			Tier:        tier,
+			Backend:     result.ActualBackend,
+		},
+		description: description,
+		worker:      worker,
+		tierConfig:  tierConfig,
+		opts:        opts,
+		sources:     sources,
+	}
+	c.packContext(a.task, sources)
+
+	used := len(attemptExecs)
+	if used > 0 && attemptExecs[used-1].Backend != attemptExecs[0].Backend {
+		a.escalated = true
+	}
+	result.Attempts = used
+	if used == 0 {
+		if !opts.AllowOverspend {
+			if err := c.checkBudget(worker, a.task, result.TotalCostUSD, opts); err != nil {
+				result.Error = err.Error() + "; rerun with --allow-overspend to proceed anyway"
+				result.Status = types.StatusFailed
+				return c.finish(result), nil
+			}
+		}
+	} else {
+		last := attemptExecs[used-1]
+		result.Execution = &types.ExecutionResult{
+			TaskID:     task.ID,
+			Backend:    types.Backend(last.Backend),
+			Success:    last.Status == "completed",
+			Output:     last.Output,
+			Diff:       last.Diff,
+			TokensUsed: last.TokensUsed,
+			Usage:      types.Usage{InputTokens: last.InputTokens, OutputTokens: last.OutputTokens},
+			CostUSD:    last.CostUSD,
+			Error:      last.ErrorMsg,
+		}
+
+		if !result.Execution.Success {
+			result.Status = types.StatusFailed
+			result.Error = last.ErrorMsg
+		} else {
+			// A recorded verdict stands; only an output never reviewed is validated
+			verdict, err := c.recordedVerdict(tierConfig, last.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load validations: %w", err)
+			}
+			var accepted bool
+			if verdict != nil {
+				result.ValidationRequired = true
+				accepted = c.settle(a, verdict, result)
+			} else {
+				accepted = c.review(ctx, a, last.ID, result.Execution, result)
+			}
+			if accepted {
+				// The recorded output was accepted; nothing left to run
+				return c.finish(result), nil
+			}
+		}
+	}
+
+	if err := c.runAttempts(ctx, a, used+1, result); err != nil {
+		return nil, err
+	}
+	if result.Attempts == used && !succeeded(result.Status) {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("retry budget already spent (%d attempt(s))", used))
+	}
+
+	return c.finish(result), nil
+}
+
+// matchSubtasks pairs plan steps with the subtasks already created for them,
+// by title and in creation order. Steps that never started map to nil.
+func matchSubtasks(steps []planStep, children []*ledger.Task) []*ledger.Task {
+	matched := make([]*ledger.Task, len(steps))
+	used := make([]bool, len(children))
+	for i, step := range steps {
+		for j, child := range children {
+			if !used[j] && child.Title == step.Title {
+				matched[i] = child
+				used[j] = true
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// continueSubtask resumes an orphaned subtask, or reports the outcome of one that already finished
+func (c *Conductor) continueSubtask(ctx context.Context, task *ledger.Task, opts RunOptions) *RunResult {
+	if task.Status == string(types.StatusOrphaned) {
+		r, err := c.Resume(ctx, task.ID, opts)
+		if err != nil {
+			return &RunResult{
+				TaskID: task.ID,
+				Title:  task.Title,
+				Status: types.StatusFailed,
+				Error:  err.Error(),
+			}
+		}
+		return r
+	}
+
+	result := &RunResult{
+		TaskID: task.ID,
+		Title:  task.Title,
+		Status: types.TaskStatus(task.Status),
+		Classification: &types.ClassificationResult{
+			Tier:               types.Tier(task.Tier),
+			RecommendedBackend: types.Backend(task.WorkerBackend),
+		},
+	}
+	if task.ParentID != nil {
+		result.ParentID = *task.ParentID
+	}
+
+	execs, err := c.ledger.GetExecutions(task.ID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to load executions: %v", err)
+		return result
+	}
+	for _, e := range execs {
+		result.TotalCostUSD += e.CostUSD
+		if e.Kind == ledger.KindAttempt {
+			result.Attempts++
+			result.ActualBackend = types.Backend(e.Backend)
+			result.Execution = &types.ExecutionResult{
+				TaskID:     task.ID,
+				Backend:    types.Backend(e.Backend),
+				Success:    e.Status == "completed",
+				Output:     e.Output,
+				Diff:       e.Diff,
+				TokensUsed: e.TokensUsed,
+				Usage:      types.Usage{InputTokens: e.InputTokens, OutputTokens: e.OutputTokens},
+				CostUSD:    e.CostUSD,
+				Error:      e.ErrorMsg,
+			}
+		}
+	}
+	if !succeeded(result.Status) && result.Execution != nil {
+		result.Error = result.Execution.Error
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package conductor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// buildRetryDescription extends the original description with feedback from the previous attempt
+func buildRetryDescription(description string, previous *types.ExecutionResult, findings []types.Finding) string {
+	var b strings.Builder
+
+	if description != "" {
+		b.WriteString(description)
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("## Previous Attempt\n")
+	b.WriteString("A previous attempt at this task was not accepted. Address the problems below in your new solution.\n")
+
+	if previous != nil && previous.Output != "" {
+		b.WriteString("\n### Previous Output\n")
+		b.WriteString(previous.Output)
+		b.WriteString("\n")
+	}
+
+	if previous != nil && previous.Error != "" {
+		b.WriteString("\n### Error\n")
+		b.WriteString(previous.Error)
+		b.WriteString("\n")
+	}
+
+	if len(findings) > 0 {
+		b.WriteString("\n### Reviewer Findings\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "- [%s] %s: %s", f.Severity, f.Location, f.Message)
+			if f.Suggestion != "" {
+				fmt.Fprintf(&b, " (suggestion: %s)", f.Suggestion)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package conductor
+
+import (
+	"context"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Streamer is implemented by workers that can report output as it is generated
+type Streamer interface {
+	ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error)
+}
+
+// execute runs an attempt on the task's worker. With streaming requested
+// and supported, each fragment of output is published as an ExecutionChunk
+// event; the result is the same either way.
+func (c *Conductor) execute(ctx context.Context, a *attempts, attempt int) (*types.ExecutionResult, error) {
+	s, ok := a.worker.(Streamer)
+	if !a.opts.Stream || !ok {
+		return a.worker.Execute(ctx, a.task)
+	}
+
+	backend := string(a.worker.Backend())
+	return s.ExecuteStream(ctx, a.task, func(text string) {
+		c.publish(a.task.ID, bus.ExecutionChunk, map[string]interface{}{
+			"attempt": attempt,
+			"backend": backend,
+			"text":    text,
+		})
+	})
+}
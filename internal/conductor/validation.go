@@ -0,0 +1,213 @@
+package conductor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cammy/bigo/internal/bus"
+	"github.com/cammy/bigo/internal/config"
+	"github.com/cammy/bigo/internal/ledger"
+	"github.com/cammy/bigo/internal/validators"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// validate runs the tier's validator panel, and the configured test command
+// when the execution changes files, and records each verdict. A passing test
+// run counts as one approval in vote mode; in veto mode a failing one
+// rejects the change whatever the panel decided. In tiers without a panel,
+// the tests alone decide. Each review is recorded as an execution of the
+// task, and its cost is added to the task's on result.
+func (c *Conductor) validate(ctx context.Context, a *attempts, executionID string, execResult *types.ExecutionResult, result *RunResult) (*validators.Verdict, error) {
+	task := &types.Task{
+		ID:          a.task.ID,
+		Title:       a.task.Title,
+		Description: a.description,
+		Tier:        a.task.Tier,
+	}
+	tierConfig := a.tierConfig
+
+	if timeout := parseDuration(c.config.Conductor.ValidationTimeout); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var results []*types.ValidationResult
+	if tierConfig.ValidatorCount > 0 {
+		// No model reviews its own output, even when a fallback or escalation
+		// has put the tier's validator in the producer's place
+		reviewer, ok := c.worker(tierConfig.ValidatorBackend)
+		if !ok || !reviewer.Available() || tierConfig.ValidatorBackend == execResult.Backend {
+			reviewer = c.findIndependentReviewer(task.Tier, execResult.Backend)
+			if reviewer == nil {
+				return nil, fmt.Errorf("no available validator for backend %s other than %s, which produced the output",
+					tierConfig.ValidatorBackend, execResult.Backend)
+			}
+		}
+		// Hold the panel's estimate until every review is recorded
+		holdID := generateID()
+		estimate := float64(tierConfig.ValidatorCount) * estimateCost(reviewer, validators.ReviewTask(task, execResult.Output))
+		if err := c.reserve(holdID, reviewer.Backend(), estimate, result.TotalCostUSD, a.opts); err != nil {
+			return nil, fmt.Errorf("cannot validate: %w", err)
+		}
+		defer c.release(holdID)
+		panel := validators.NewPanel(reviewer, tierConfig.ValidatorCount, parseDuration(c.config.Validators.Timeout))
+		results = panel.Review(ctx, task, executionID, execResult.Output)
+	}
+
+	var tests *types.ValidationResult
+	if c.runsTests(execResult) {
+		cfg := c.config.Validators.Tests
+		tests = validators.NewCommandValidator(c.classifier.Index().Root, cfg.Command, parseDuration(cfg.Timeout)).
+			Validate(ctx, executionID, execResult.Diff)
+	}
+
+	for _, r := range append(results, tests) {
+		if r == nil {
+			continue
+		}
+		if r.Review != nil {
+			result.TotalCostUSD += r.Review.CostUSD
+		}
+		if err := c.recordVerdict(task.ID, executionID, result.Attempts, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.tally(tierConfig, results, tests), nil
+}
+
+// tally combines the panel's verdicts with the test run's, if there was one
+func (c *Conductor) tally(tierConfig types.TierConfig, results []*types.ValidationResult, tests *types.ValidationResult) *validators.Verdict {
+	required := tierConfig.RequiredApprovals
+	if tests == nil {
+		return validators.Tally(results, required)
+	}
+	if c.config.Validators.Tests.Mode == config.TestsVote {
+		if tierConfig.ValidatorCount == 0 {
+			required = 1
+		}
+		return validators.Tally(append(results, tests), required)
+	}
+	verdict := validators.Tally(results, required)
+	verdict.Veto(tests)
+	return verdict
+}
+
+// recordedVerdict rebuilds the verdict on an execution from the validations
+// the ledger holds for it, or returns nil if it was never reviewed
+func (c *Conductor) recordedVerdict(tierConfig types.TierConfig, executionID string) (*validators.Verdict, error) {
+	validations, err := c.ledger.GetValidations(executionID)
+	if err != nil || len(validations) == 0 {
+		return nil, err
+	}
+
+	var results []*types.ValidationResult
+	var tests *types.ValidationResult
+	for _, v := range validations {
+		r := &types.ValidationResult{
+			ExecutionID: executionID,
+			ValidatorID: v.ValidatorID,
+			Backend:     types.Backend(v.Backend),
+			Approved:    v.Verdict == "approved",
+		}
+		if err := json.Unmarshal([]byte(v.Findings), &r.Findings); err != nil {
+			return nil, fmt.Errorf("invalid findings from %s: %w", v.ValidatorID, err)
+		}
+		if v.ValidatorID == validators.CommandValidatorID {
+			tests = r
+			continue
+		}
+		results = append(results, r)
+	}
+	return c.tally(tierConfig, results, tests), nil
+}
+
+// findIndependentReviewer returns the tier's first fallback that did not
+// produce the output under review, so no model is left marking its own work
+func (c *Conductor) findIndependentReviewer(tier types.Tier, producer types.Backend) Worker {
+	for _, w := range c.fallbackWorkers(tier) {
+		if w.Backend() != producer {
+			return w
+		}
+	}
+	return nil
+}
+
+// runsTests reports whether the configured test command should check an
+// execution: there must be a command, a working tree and a change to test
+func (c *Conductor) runsTests(execResult *types.ExecutionResult) bool {
+	return c.config.Validators.Tests.Command != "" && c.classifier.Index() != nil && execResult.Diff != ""
+}
+
+// recordVerdict stores a validator's verdict, and the reviewer's run as an
+// execution of the task, and announces it on the bus
+func (c *Conductor) recordVerdict(taskID, executionID string, attempt int, r *types.ValidationResult) error {
+	var cost float64
+	if review := r.Review; review != nil {
+		exec := &ledger.Execution{
+			ID:           generateID(),
+			TaskID:       taskID,
+			WorkerID:     review.WorkerID,
+			Kind:         ledger.KindValidator,
+			Backend:      string(r.Backend),
+			Output:       review.Output,
+			TokensUsed:   review.TokensUsed,
+			InputTokens:  review.Usage.Input(),
+			OutputTokens: review.Usage.OutputTokens,
+			CostUSD:      review.CostUSD,
+			DurationMs:   int(review.DurationMs),
+			Status:       "completed",
+			Attempt:      attempt,
+		}
+		if !review.Success {
+			exec.Status = "failed"
+			exec.ErrorMsg = review.Error
+		}
+		if err := c.ledger.CreateExecution(exec); err != nil {
+			return fmt.Errorf("failed to record review: %w", err)
+		}
+		cost = review.CostUSD
+	}
+
+	findings, err := json.Marshal(r.Findings)
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %w", err)
+	}
+
+	verdict := "rejected"
+	if r.Approved {
+		verdict = "approved"
+	}
+
+	if err := c.ledger.CreateValidation(&ledger.Validation{
+		ID:          generateID(),
+		ExecutionID: executionID,
+		ValidatorID: r.ValidatorID,
+		Backend:     string(r.Backend),
+		Verdict:     verdict,
+		Findings:    string(findings),
+	}); err != nil {
+		return fmt.Errorf("failed to record validation: %w", err)
+	}
+	c.publish(taskID, bus.ValidationVerdict, map[string]interface{}{
+		"execution_id": executionID,
+		"validator_id": r.ValidatorID,
+		"backend":      string(r.Backend),
+		"verdict":      verdict,
+		"findings":     len(r.Findings),
+		"cost_usd":     cost,
+	})
+	return nil
+}
+
+// parseDuration parses a config duration, treating empty or invalid values as no limit
+func parseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
+}
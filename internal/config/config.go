@@ -3,24 +3,36 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/cammy/bigo/internal/pricing"
+	"github.com/cammy/bigo/pkg/types"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds all BigO configuration
 type Config struct {
 	Conductor  ConductorConfig  `yaml:"conductor"`
+	Classifier ClassifierConfig `yaml:"classifier,omitempty"`
+	Routing    RoutingConfig    `yaml:"routing"`
 	Workers    WorkersConfig    `yaml:"workers"`
+	Pricing    pricing.Table    `yaml:"pricing"` // USD per million tokens by model ID or ID prefix
 	Validators ValidatorsConfig `yaml:"validators"`
+	Context    ContextConfig    `yaml:"context"`
+	Git        GitConfig        `yaml:"git"`
 	Ledger     LedgerConfig     `yaml:"ledger"`
 	Bus        BusConfig        `yaml:"bus"`
 }
 
 // ConductorConfig configures the main orchestrator
 type ConductorConfig struct {
-	ClassifierModel   string `yaml:"classifier_model"`
-	MaxRetries        int    `yaml:"max_retries"`
-	ValidationTimeout string `yaml:"validation_timeout"`
+	ClassifierModel     string  `yaml:"classifier_model"`     // Backend asked when patterns are inconclusive; "none" to disable
+	ClassifierThreshold float64 `yaml:"classifier_threshold"` // Pattern confidence below which the model is asked
+	MaxRetries          int     `yaml:"max_retries"`
+	ValidationTimeout   string  `yaml:"validation_timeout"`
+	HeartbeatInterval   string  `yaml:"heartbeat_interval"` // How often a running task reports it is alive
+	StaleAfter          string  `yaml:"stale_after"`        // When an in-flight task without a heartbeat is orphaned
 }
 
 // WorkersConfig configures all worker backends
@@ -28,6 +40,30 @@ type WorkersConfig struct {
 	Claude ClaudeConfig `yaml:"claude"`
 	Ollama OllamaConfig `yaml:"ollama"`
 	Gemini GeminiConfig `yaml:"gemini"`
+	OpenAI OpenAIConfig `yaml:"openai"`
+}
+
+// BackendPrice returns what a backend charges, from the model configured
+// for it and the pricing table. Ollama backends are free; ok is false for
+// backends with no configured model or no price.
+func (c *Config) BackendPrice(b types.Backend) (price pricing.Price, ok bool) {
+	provider, name, _ := strings.Cut(string(b), ":")
+	var models map[string]string
+	switch provider {
+	case "ollama":
+		return pricing.Price{}, true
+	case "claude":
+		models = c.Workers.Claude.Models
+	case "gemini":
+		models = c.Workers.Gemini.Models
+	case "openai":
+		models = c.Workers.OpenAI.Models
+	}
+	model, ok := models[name]
+	if !ok {
+		return pricing.Price{}, false
+	}
+	return c.Pricing.Lookup(model)
 }
 
 // ClaudeConfig configures the Claude backend
@@ -38,10 +74,10 @@ type ClaudeConfig struct {
 	CostLimits    CostLimits        `yaml:"cost_limits"`
 }
 
-// CostLimits sets spending limits for Claude
+// CostLimits sets spending limits for a paid backend
 type CostLimits struct {
 	DailyUSD   float64 `yaml:"daily_usd"`
-	PerTaskUSD float64 `yaml:"per_task_usd"`
+	PerTaskUSD float64 `yaml:"per_task_usd"` // A planned task's subtasks share their parent's limit
 }
 
 // OllamaConfig configures the Ollama backend
@@ -59,13 +95,74 @@ type GeminiConfig struct {
 	APIKey        string            `yaml:"api_key"`
 	MaxConcurrent int               `yaml:"max_concurrent"`
 	Models        map[string]string `yaml:"models"`
+	CostLimits    CostLimits        `yaml:"cost_limits"`
+}
+
+// OpenAIConfig configures servers speaking the OpenAI chat completions API,
+// such as OpenAI, vLLM, the llama.cpp server or LM Studio. Each model alias
+// becomes the backend openai:<alias>.
+type OpenAIConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	BaseURL       string            `yaml:"base_url"`    // Up to and including the version, e.g. http://localhost:8000/v1
+	APIKeyEnv     string            `yaml:"api_key_env"` // Environment variable holding the API key; unset or empty sends none
+	MaxConcurrent int               `yaml:"max_concurrent"`
+	Models        map[string]string `yaml:"models"`
+	CostLimits    CostLimits        `yaml:"cost_limits"`
 }
 
 // ValidatorsConfig configures the validation system
 type ValidatorsConfig struct {
-	PoolSize int      `yaml:"pool_size"`
-	Timeout  string   `yaml:"timeout"`
-	Backends []string `yaml:"backends"`
+	PoolSize int         `yaml:"pool_size"`
+	Timeout  string      `yaml:"timeout"`
+	Backends []string    `yaml:"backends"`
+	Tests    TestsConfig `yaml:"tests"`
+}
+
+// How a passing or failing test command counts toward a verdict
+const (
+	TestsVote = "vote" // Passing counts as one approval toward the tier's required approvals
+	TestsVeto = "veto" // Failing rejects the change whatever the reviewers say
+)
+
+// TestsConfig runs a project command, such as its test suite, against every
+// change a worker proposes
+type TestsConfig struct {
+	Command string `yaml:"command"` // Run with sh -c in a scratch copy of the working tree; empty disables
+	Timeout string `yaml:"timeout"`
+	Mode    string `yaml:"mode"` // vote or veto
+}
+
+// ContextConfig controls the repository files packed into worker prompts
+type ContextConfig struct {
+	Auto          bool           `yaml:"auto"`           // Pack files the task mentions, after those given with --context
+	DefaultTokens int            `yaml:"default_tokens"` // Budget for backends missing from tokens
+	Tokens        map[string]int `yaml:"tokens"`         // Budget in tokens per backend; 0 packs nothing
+}
+
+// Budget returns the context budget in tokens for a backend
+func (c ContextConfig) Budget(backend string) int {
+	if tokens, ok := c.Tokens[backend]; ok {
+		return tokens
+	}
+	return c.DefaultTokens
+}
+
+// Git modes for committing task changes
+const (
+	GitOff      = "off"      // Changes are only written by 'bigo apply'
+	GitBranch   = "branch"   // Each task commits on its own branch; the user's checkout is untouched
+	GitWorktree = "worktree" // As branch, keeping a checkout of the branch under .bigo/worktrees
+)
+
+// GitConfig controls committing each task's changes to git
+type GitConfig struct {
+	Mode         string `yaml:"mode"`          // off, branch or worktree
+	BranchPrefix string `yaml:"branch_prefix"` // Prepended to the task ID to name its branch
+}
+
+// Enabled reports whether task changes are committed
+func (g GitConfig) Enabled() bool {
+	return g.Mode == GitBranch || g.Mode == GitWorktree
 }
 
 // LedgerConfig configures the SQLite ledger
@@ -82,10 +179,14 @@ type BusConfig struct {
 func Default() *Config {
 	return &Config{
 		Conductor: ConductorConfig{
-			ClassifierModel:   "claude:sonnet",
-			MaxRetries:        3,
-			ValidationTimeout: "300s",
+			ClassifierModel:     "claude:sonnet",
+			ClassifierThreshold: 0.6,
+			MaxRetries:          3,
+			ValidationTimeout:   "300s",
+			HeartbeatInterval:   "15s",
+			StaleAfter:          "2m",
 		},
+		Routing: DefaultRouting(),
 		Workers: WorkersConfig{
 			Claude: ClaudeConfig{
 				Enabled:       true,
@@ -119,8 +220,26 @@ func Default() *Config {
 					"flash": "gemini-1.5-flash",
 					"pro":   "gemini-1.5-pro",
 				},
+				CostLimits: CostLimits{
+					DailyUSD:   20.0,
+					PerTaskUSD: 2.0,
+				},
+			},
+			OpenAI: OpenAIConfig{
+				Enabled:       false,
+				BaseURL:       "https://api.openai.com/v1",
+				APIKeyEnv:     "OPENAI_API_KEY",
+				MaxConcurrent: 4,
+				Models: map[string]string{
+					"default": "gpt-4.1-mini",
+				},
+				CostLimits: CostLimits{
+					DailyUSD:   20.0,
+					PerTaskUSD: 2.0,
+				},
 			},
 		},
+		Pricing: pricing.Defaults(),
 		Validators: ValidatorsConfig{
 			PoolSize: 5,
 			Timeout:  "120s",
@@ -128,6 +247,28 @@ func Default() *Config {
 				"claude:sonnet",
 				"ollama:qwen3:8b",
 			},
+			Tests: TestsConfig{
+				Timeout: "10m",
+				Mode:    TestsVeto,
+			},
+		},
+		Context: ContextConfig{
+			Auto:          true,
+			DefaultTokens: 4000,
+			Tokens: map[string]int{
+				"ollama:fast":      2000,
+				"ollama:default":   3000,
+				"ollama:reasoning": 6000,
+				"claude:haiku":     20000,
+				"claude:sonnet":    40000,
+				"claude:opus":      40000,
+				"gemini:flash":     100000,
+				"gemini:pro":       200000,
+			},
+		},
+		Git: GitConfig{
+			Mode:         GitOff,
+			BranchPrefix: "bigo/",
 		},
 		Ledger: LedgerConfig{
 			Path: ".bigo/ledger.db",
@@ -150,6 +291,37 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := cfg.validateRouting(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	for backend, tokens := range cfg.Context.Tokens {
+		if tokens < 0 {
+			return nil, fmt.Errorf("invalid config: context.tokens.%s must not be negative", backend)
+		}
+	}
+	if err := cfg.Pricing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: pricing.%w", err)
+	}
+	switch cfg.Validators.Tests.Mode {
+	case TestsVote, TestsVeto:
+	default:
+		return nil, fmt.Errorf("invalid config: validators.tests.mode must be %s or %s, not %q", TestsVote, TestsVeto, cfg.Validators.Tests.Mode)
+	}
+	if cfg.Workers.OpenAI.Enabled && cfg.Workers.OpenAI.BaseURL == "" {
+		return nil, fmt.Errorf("invalid config: workers.openai.base_url is required when the openai worker is enabled")
+	}
+	switch cfg.Git.Mode {
+	case GitOff, GitBranch, GitWorktree:
+	default:
+		return nil, fmt.Errorf("invalid config: git.mode must be %s, %s or %s, not %q", GitOff, GitBranch, GitWorktree, cfg.Git.Mode)
+	}
+	if err := cfg.Classifier.Patterns.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: classifier.%w", err)
+	}
+	if err := cfg.LoadPatterns(filepath.Join(filepath.Dir(path), PatternsFile)); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -164,12 +336,19 @@ func WriteDefault(path string) error {
 	header := []byte(`# BigO Configuration
 # Unified Claude + Ollama Agent Orchestrator
 #
-# Tier routing:
+# Tier routing (edit the routing section to change it):
 #   T0 (TRIVIAL)  → Ollama (fast model)
 #   T1 (SIMPLE)   → Ollama (default) + 1 validator
 #   T2 (STANDARD) → Claude Sonnet + 2 validators
 #   T3 (COMPLEX)  → Claude Sonnet + Opus planning + 3 validators
-#   T4 (CRITICAL) → Claude Opus + 5 validators
+#   T4 (CRITICAL) → Claude Opus + Opus planning + 5 validators
+#
+# Failed or rejected attempts are retried one rung up each tier's escalation ladder.
+#
+# Set validators.tests.command (e.g. "go test ./...") to test every proposed change.
+# Enable workers.openai and set its base_url to use an OpenAI-compatible server (vLLM, llama.cpp, LM Studio).
+# Set git.mode to branch or worktree to commit each task's changes on its own branch.
+# Prices are in USD per million tokens; a model matches its ID or the longest prefix of it.
 
 `)
 
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+
+	"github.com/cammy/bigo/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ClassifierConfig tunes the task classifier
+type ClassifierConfig struct {
+	Patterns PatternsConfig `yaml:"patterns,omitempty"`
+}
+
+// PatternsConfig maps tier names to rules applied over the built-in patterns
+type PatternsConfig map[string][]PatternRule
+
+// PatternRule changes the built-in pattern of its tier with the same name, or
+// adds a pattern if there is none. Regexes match case-insensitively; the
+// weight of an added pattern defaults to DefaultPatternWeight.
+type PatternRule struct {
+	Name     string   `yaml:"name"`
+	Regex    string   `yaml:"regex,omitempty"`
+	Weight   *float64 `yaml:"weight,omitempty"`
+	Disabled bool     `yaml:"disabled,omitempty"`
+}
+
+// PatternsFile is the patterns file read from the directory holding config.yaml
+const PatternsFile = "patterns.yaml"
+
+// DefaultPatternWeight is the weight of an added pattern that sets none
+const DefaultPatternWeight = 0.7
+
+// CompilePattern compiles a pattern regex the way the classifier matches it
+func CompilePattern(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + expr)
+}
+
+// LoadPatterns appends the rules in a patterns file, laid out like the
+// classifier.patterns section, to the configured ones. A missing file is
+// not an error.
+func (c *Config) LoadPatterns(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read patterns file: %w", err)
+	}
+
+	var patterns PatternsConfig
+	if err := yaml.Unmarshal(data, &patterns); err != nil {
+		return fmt.Errorf("failed to parse patterns file: %w", err)
+	}
+	if err := patterns.validate(); err != nil {
+		return fmt.Errorf("invalid patterns file: %w", err)
+	}
+
+	if c.Classifier.Patterns == nil {
+		c.Classifier.Patterns = make(PatternsConfig)
+	}
+	for tier, rules := range patterns {
+		c.Classifier.Patterns[tier] = append(c.Classifier.Patterns[tier], rules...)
+	}
+	return nil
+}
+
+// validate checks tier names, and that every rule is named and has a usable regex and weight
+func (p PatternsConfig) validate() error {
+	for name, rules := range p {
+		if _, err := types.ParseTier(name); err != nil {
+			return fmt.Errorf("patterns: %w", err)
+		}
+		for i, rule := range rules {
+			if rule.Name == "" {
+				return fmt.Errorf("patterns.%s[%d]: name is required", name, i)
+			}
+			if rule.Regex != "" {
+				if _, err := CompilePattern(rule.Regex); err != nil {
+					return fmt.Errorf("patterns.%s.%s: invalid regex: %w", name, rule.Name, err)
+				}
+			}
+			if rule.Weight != nil && (*rule.Weight < 0 || *rule.Weight > 1) {
+				return fmt.Errorf("patterns.%s.%s: weight must be between 0 and 1", name, rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// SavePatternWeights sets the weights of patterns in a patterns file,
+// creating it if needed. weights maps tier names to pattern names to
+// weights; rules already in the file keep their other settings.
+func SavePatternWeights(path string, weights map[string]map[string]float64) error {
+	patterns := make(PatternsConfig)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &patterns); err != nil {
+			return fmt.Errorf("failed to parse patterns file: %w", err)
+		}
+		if patterns == nil {
+			patterns = make(PatternsConfig)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to read patterns file: %w", err)
+	}
+
+	for tier, names := range weights {
+		for name, weight := range names {
+			w := weight
+			found := false
+			for i := range patterns[tier] {
+				if patterns[tier][i].Name == name {
+					patterns[tier][i].Weight = &w
+					found = true
+				}
+			}
+			if !found {
+				patterns[tier] = append(patterns[tier], PatternRule{Name: name, Weight: &w})
+			}
+		}
+	}
+	if err := patterns.validate(); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(patterns)
+	if err != nil {
+		return fmt.Errorf("failed to marshal patterns: %w", err)
+	}
+	return os.WriteFile(path, out, 0600)
+}
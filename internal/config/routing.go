@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// RoutingConfig maps tier names (trivial, simple, standard, complex, critical) to routes
+type RoutingConfig map[string]TierRoute
+
+// TierRoute configures which backends plan, execute and validate a tier.
+// Set planner to "none" to run the tier's tasks without decomposition, and
+// escalation to [] to retry failed tasks on the backend that ran them.
+type TierRoute struct {
+	Primary           string   `yaml:"primary,omitempty"`
+	Planner           string   `yaml:"planner,omitempty"`
+	Validator         string   `yaml:"validator,omitempty"`
+	ValidatorCount    *int     `yaml:"validator_count,omitempty"`
+	RequiredApprovals *int     `yaml:"required_approvals,omitempty"`
+	Fallbacks         []string `yaml:"fallbacks,omitempty"`
+	Escalation        []string `yaml:"escalation,omitempty"`
+}
+
+// noPlanner disables decomposition for a tier that plans by default
+const noPlanner = "none"
+
+// DefaultRouting returns the routing table equivalent to types.DefaultTierConfigs
+func DefaultRouting() RoutingConfig {
+	routing := make(RoutingConfig)
+	for tier, tc := range types.DefaultTierConfigs() {
+		validatorCount, requiredApprovals := tc.ValidatorCount, tc.RequiredApprovals
+		route := TierRoute{
+			Primary:           string(tc.PrimaryBackend),
+			Planner:           string(tc.PlannerBackend),
+			Validator:         string(tc.ValidatorBackend),
+			ValidatorCount:    &validatorCount,
+			RequiredApprovals: &requiredApprovals,
+		}
+		for _, b := range tc.Fallbacks {
+			route.Fallbacks = append(route.Fallbacks, string(b))
+		}
+		for _, b := range tc.Escalation {
+			route.Escalation = append(route.Escalation, string(b))
+		}
+		routing[strings.ToLower(tier.String())] = route
+	}
+	return routing
+}
+
+// validateRouting checks tier names and the resulting validator settings
+func (c *Config) validateRouting() error {
+	for name := range c.Routing {
+		if _, err := types.ParseTier(name); err != nil {
+			return fmt.Errorf("routing: %w", err)
+		}
+	}
+
+	for tier, tc := range c.TierConfigs() {
+		name := strings.ToLower(tier.String())
+		if tc.ValidatorCount < 0 || tc.RequiredApprovals < 0 {
+			return fmt.Errorf("routing.%s: validator counts must not be negative", name)
+		}
+		if tc.RequiredApprovals > tc.ValidatorCount {
+			return fmt.Errorf("routing.%s: required_approvals (%d) exceeds validator_count (%d)",
+				name, tc.RequiredApprovals, tc.ValidatorCount)
+		}
+		if tc.ValidatorCount > 0 && tc.ValidatorBackend == "" {
+			return fmt.Errorf("routing.%s: validator_count is set but no validator backend is configured", name)
+		}
+	}
+	return nil
+}
+
+// TierConfigs merges the routing section over the default tier configuration.
+// Fields left out of a tier block keep their default values.
+func (c *Config) TierConfigs() map[types.Tier]types.TierConfig {
+	configs := types.DefaultTierConfigs()
+
+	for name, route := range c.Routing {
+		tier, err := types.ParseTier(name)
+		if err != nil {
+			continue
+		}
+
+		tc := configs[tier]
+		if route.Primary != "" {
+			tc.PrimaryBackend = types.Backend(route.Primary)
+		}
+		switch route.Planner {
+		case "":
+		case noPlanner:
+			tc.PlannerBackend = ""
+		default:
+			tc.PlannerBackend = types.Backend(route.Planner)
+		}
+		if route.Validator != "" {
+			tc.ValidatorBackend = types.Backend(route.Validator)
+		}
+		if route.ValidatorCount != nil {
+			tc.ValidatorCount = *route.ValidatorCount
+		}
+		if route.RequiredApprovals != nil {
+			tc.RequiredApprovals = *route.RequiredApprovals
+		}
+		if route.Fallbacks != nil {
+			tc.Fallbacks = nil
+			for _, b := range route.Fallbacks {
+				tc.Fallbacks = append(tc.Fallbacks, types.Backend(b))
+			}
+		}
+		if route.Escalation != nil {
+			tc.Escalation = nil
+			for _, b := range route.Escalation {
+				tc.Escalation = append(tc.Escalation, types.Backend(b))
+			}
+		}
+		configs[tier] = tc
+	}
+
+	return configs
+}
@@ -0,0 +1,174 @@
+// Package git runs the git commands BigO needs to commit task changes on
+// their own branches and to undo them.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Repo is a git working tree
+type Repo struct {
+	Root string // Top-level directory of the working tree
+}
+
+// Open finds the git working tree containing dir
+func Open(dir string) (*Repo, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", dir, err)
+	}
+	return &Repo{Root: filepath.Clean(out)}, nil
+}
+
+// run executes git in dir and returns its trimmed output. Errors carry git's
+// own message.
+func run(dir string, args ...string) (string, error) {
+	// #nosec G204 -- arguments are built by this package
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func (r *Repo) git(args ...string) (string, error) {
+	return run(r.Root, args...)
+}
+
+// Head returns the commit checked out
+func (r *Repo) Head() (string, error) {
+	return r.git("rev-parse", "HEAD")
+}
+
+// BranchCommit returns the commit a branch points at, or "" if there is no
+// such branch
+func (r *Repo) BranchCommit(branch string) string {
+	sha, err := r.git("rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// AddWorktree checks out a new branch, started at base, in a new working
+// tree at path
+func (r *Repo) AddWorktree(path, branch, base string) (*Repo, error) {
+	if _, err := r.git("worktree", "add", "-b", branch, path, base); err != nil {
+		return nil, err
+	}
+	return &Repo{Root: path}, nil
+}
+
+// RemoveWorktree deletes a working tree made by AddWorktree, discarding any
+// changes in it; the branch is kept
+func (r *Repo) RemoveWorktree(path string) error {
+	_, err := r.git("worktree", "remove", "--force", path)
+	return err
+}
+
+// DeleteBranch deletes a branch whether or not it was merged
+func (r *Repo) DeleteBranch(branch string) error {
+	_, err := r.git("branch", "-D", branch)
+	return err
+}
+
+// Commit stages the given paths, including deletions, and commits them
+func (r *Repo) Commit(message string, paths []string) (string, error) {
+	if _, err := r.git(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	if _, err := r.git("commit", "--quiet", "-m", message); err != nil {
+		return "", err
+	}
+	return r.Head()
+}
+
+// IsAncestor reports whether commit is reachable from of
+func (r *Repo) IsAncestor(commit, of string) (bool, error) {
+	_, err := r.git("merge-base", "--is-ancestor", commit, of)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Revert commits the inverse of a commit on top of the checked-out branch
+func (r *Repo) Revert(commit string) (string, error) {
+	if _, err := r.git("revert", "--no-edit", commit); err != nil {
+		return "", err
+	}
+	return r.Head()
+}
+
+// RemoteURL returns the URL of a remote, or "" if it has none
+func (r *Repo) RemoteURL(remote string) string {
+	u, err := r.git("remote", "get-url", remote)
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// Files lists the files under dir, which must be inside the working tree,
+// that are tracked or untracked but not ignored. Paths are relative to dir
+// and slash-separated, in sorted order.
+func (r *Repo) Files(dir string) ([]string, error) {
+	out, err := run(dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, p := range strings.Split(out, "\x00") {
+		// Unmerged files are listed once per stage
+		if p != "" && !seen[p] {
+			seen[p] = true
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// CommitURL returns the web page of a commit on a hosted remote such as
+// GitHub or GitLab, or "" if the remote URL is not one a browser can open
+func CommitURL(remote, commit string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		if u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "ssh" {
+			return ""
+		}
+		host, path = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+	} else if at := strings.Index(remote, "@"); at >= 0 {
+		// scp-like syntax: git@github.com:owner/repo
+		var ok bool
+		host, path, ok = strings.Cut(remote[at+1:], ":")
+		if !ok {
+			return ""
+		}
+	}
+	if host == "" || path == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/commit/%s", host, path, commit)
+}
@@ -0,0 +1,149 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRepo creates a repository with one commit holding a.txt
+func newRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	dir := t.TempDir()
+	if _, err := run(dir, "init", "--quiet", "-b", "main"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := r.Commit("initial", []string{"a.txt"}); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	return r
+}
+
+func TestWorktreeCommitAndRevert(t *testing.T) {
+	r := newRepo(t)
+	base, err := r.Head()
+	if err != nil {
+		t.Fatalf("Head failed: %v", err)
+	}
+
+	// Commit on a branch in its own worktree
+	path := filepath.Join(t.TempDir(), "wt")
+	wt, err := r.AddWorktree(path, "bigo/t1", "HEAD")
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "a.txt"), []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sha, err := wt.Commit("Change a\n\nBigO-Task: t1\n", []string{"a.txt"})
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if got := r.BranchCommit("bigo/t1"); got != sha {
+		t.Errorf("Expected the branch at %s, got %q", sha, got)
+	}
+	if head, _ := r.Head(); head != base {
+		t.Errorf("Expected the main checkout to stay at %s, got %s", base, head)
+	}
+	if merged, err := r.IsAncestor(sha, "HEAD"); err != nil || merged {
+		t.Errorf("Expected the commit not to be merged, got %v (%v)", merged, err)
+	}
+
+	if err := r.RemoveWorktree(path); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+
+	// Merge it, then revert it
+	if _, err := r.git("merge", "--quiet", "--ff-only", "bigo/t1"); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if merged, err := r.IsAncestor(sha, "HEAD"); err != nil || !merged {
+		t.Fatalf("Expected the commit to be merged, got %v (%v)", merged, err)
+	}
+	if _, err := r.Revert(sha); err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(r.Root, "a.txt"))
+	if err != nil || string(data) != "one\n" {
+		t.Errorf("Expected the change undone, got %q (%v)", data, err)
+	}
+
+	if err := r.DeleteBranch("bigo/t1"); err != nil {
+		t.Fatalf("DeleteBranch failed: %v", err)
+	}
+	if got := r.BranchCommit("bigo/t1"); got != "" {
+		t.Errorf("Expected the branch gone, got %s", got)
+	}
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Expected Open outside a repository to fail")
+	}
+}
+
+func TestFiles(t *testing.T) {
+	r := newRepo(t)
+	for name, content := range map[string]string{
+		".gitignore":        "node_modules/\n*.log\n",
+		"src/b.txt":         "untracked\n",
+		"src/debug.log":     "ignored\n",
+		"node_modules/x.js": "ignored\n",
+	} {
+		p := filepath.Join(r.Root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := r.Files(r.Root)
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+	if got := strings.Join(files, " "); got != ".gitignore a.txt src/b.txt" {
+		t.Errorf("Expected tracked and unignored files, got %q", got)
+	}
+
+	// Paths are relative to the directory listed
+	files, err = r.Files(filepath.Join(r.Root, "src"))
+	if err != nil || len(files) != 1 || files[0] != "b.txt" {
+		t.Errorf("Expected b.txt alone, got %q (%v)", files, err)
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:cammy/bigo.git", "https://github.com/cammy/bigo/commit/abc"},
+		{"https://github.com/cammy/bigo.git", "https://github.com/cammy/bigo/commit/abc"},
+		{"ssh://git@gitlab.example.com:2222/team/bigo", "https://gitlab.example.com/team/bigo/commit/abc"},
+		{"/srv/git/bigo.git", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CommitURL(tt.remote, "abc"); got != tt.want {
+			t.Errorf("CommitURL(%q) = %q, want %q", tt.remote, got, tt.want)
+		}
+	}
+}
@@ -2,12 +2,20 @@ package ledger
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/cammy/bigo/internal/pricing"
+	"github.com/cammy/bigo/pkg/types"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// timestampLayout matches the format SQLite uses for CURRENT_TIMESTAMP (UTC)
+const timestampLayout = "2006-01-02 15:04:05"
+
 // Ledger manages the SQLite database for task state persistence
 type Ledger struct {
 	db   *sql.DB
@@ -19,6 +27,7 @@ type Stats struct {
 	TotalTasks       int
 	PendingTasks     int
 	CompletedTasks   int
+	OrphanedTasks    int
 	TotalExecutions  int
 	ClaudeTasks      int
 	ClaudeCost       float64
@@ -26,8 +35,44 @@ type Stats struct {
 	GeminiCost       float64
 	OllamaTasks      int
 	OllamaCost       float64
-	EstimatedSavings float64
+	OpenAITasks      int
+	OpenAICost       float64
+	PremiumCost      float64 // What the priced executions would have cost on their tiers' premium backends
+	EstimatedSavings float64 // PremiumCost less what the priced executions actually cost
 	SavingsPercent   float64
+
+	// Savings breaks the estimate down by tier and backend
+	Savings []*SavingsLine
+
+	// Unpriced counts executions recorded without an input and output
+	// split, which are left out of the savings estimate
+	Unpriced int
+}
+
+// SavingsLine compares what a tier's executions on one backend cost with
+// what the tier's premium backend would have charged for the same tokens
+type SavingsLine struct {
+	Tier         int
+	Backend      string
+	Executions   int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	PremiumCost  float64
+}
+
+// Savings returns the premium cost less the actual cost
+func (s *SavingsLine) Savings() float64 {
+	return s.PremiumCost - s.Cost
+}
+
+// StatsOptions selects and prices the executions GetStats reports on
+type StatsOptions struct {
+	Since time.Time // Count only tasks and executions created from then on; zero counts everything
+
+	// Premium holds, by tier, the price of the backend the tier's tasks
+	// would otherwise have run on. Tiers without one save nothing.
+	Premium map[int]pricing.Price
 }
 
 // Init creates a new ledger database with the schema
@@ -36,12 +81,19 @@ func Init(path string) (*Ledger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	// SQLite allows a single writer; serialize access from concurrent tasks
+	db.SetMaxOpenConns(1)
 
 	if err := createSchema(db); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
 	return &Ledger{db: db, path: path}, nil
 }
 
@@ -51,6 +103,18 @@ func Open(path string) (*Ledger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(1)
+
+	// Bring ledgers created by older versions up to date
+	if err := createSchema(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
 
 	return &Ledger{db: db, path: path}, nil
 }
@@ -60,65 +124,94 @@ func (l *Ledger) Close() error {
 	return l.db.Close()
 }
 
-// GetStats returns aggregated statistics
-func (l *Ledger) GetStats() (*Stats, error) {
+// GetStats returns aggregated statistics. Savings are counterfactual: each
+// execution's input and output tokens are priced at its tier's premium
+// backend and compared with what it actually cost. Classifier calls have no
+// counterpart without BigO, so they count as pure overhead.
+func (l *Ledger) GetStats(opts StatsOptions) (*Stats, error) {
 	stats := &Stats{}
+	since := opts.Since.UTC().Format(timestampLayout)
 
-	// Total tasks
-	err := l.db.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&stats.TotalTasks)
-	if err != nil {
-		return nil, err
-	}
-
-	// Pending tasks
-	if err := l.db.QueryRow("SELECT COUNT(*) FROM tasks WHERE status IN ('pending', 'assigned', 'working', 'validating')").Scan(&stats.PendingTasks); err != nil {
-		return nil, err
-	}
-
-	// Completed tasks
-	if err := l.db.QueryRow("SELECT COUNT(*) FROM tasks WHERE status = 'done'").Scan(&stats.CompletedTasks); err != nil {
+	// Task counts
+	if err := l.db.QueryRow(`
+		SELECT COUNT(*),
+		       COALESCE(SUM(CASE WHEN status IN ('pending', 'assigned', 'working', 'validating') THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN status = 'orphaned' THEN 1 ELSE 0 END), 0)
+		FROM tasks
+		WHERE created_at >= ?
+	`, since).Scan(&stats.TotalTasks, &stats.PendingTasks, &stats.CompletedTasks, &stats.OrphanedTasks); err != nil {
 		return nil, err
 	}
 
-	// Total executions
-	if err := l.db.QueryRow("SELECT COUNT(*) FROM executions").Scan(&stats.TotalExecutions); err != nil {
+	// Execution costs by tier and backend; executions without an input and
+	// output split are counted but not priced
+	rows, err := l.db.Query(`
+		SELECT t.tier, e.backend, COUNT(*),
+		       COALESCE(SUM(CASE WHEN e.priced THEN e.input_tokens ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN e.priced THEN e.output_tokens ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN e.priced THEN e.cost_usd ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN e.priced AND e.kind != 'classifier' THEN e.input_tokens ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN e.priced AND e.kind != 'classifier' THEN e.output_tokens ELSE 0 END), 0),
+		       COALESCE(SUM(e.cost_usd), 0),
+		       COALESCE(SUM(CASE WHEN e.priced THEN 0 ELSE 1 END), 0)
+		FROM (
+			SELECT task_id, backend, kind, input_tokens, output_tokens, cost_usd,
+			       (input_tokens + output_tokens > 0 OR COALESCE(tokens_used, 0) = 0) AS priced
+			FROM executions
+			WHERE created_at >= ?
+		) e
+		JOIN tasks t ON t.id = e.task_id
+		GROUP BY t.tier, e.backend
+		ORDER BY t.tier, e.backend
+	`, since)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Claude stats
-	if err := l.db.QueryRow(`
-		SELECT COUNT(*), COALESCE(SUM(cost_usd), 0)
-		FROM executions
-		WHERE backend LIKE 'claude:%'
-	`).Scan(&stats.ClaudeTasks, &stats.ClaudeCost); err != nil {
-		return nil, err
-	}
+	var actualCost float64
+	for rows.Next() {
+		line := &SavingsLine{}
+		var premiumInput, premiumOutput, unpriced int
+		var cost float64
+		if err := rows.Scan(&line.Tier, &line.Backend, &line.Executions, &line.InputTokens, &line.OutputTokens,
+			&line.Cost, &premiumInput, &premiumOutput, &cost, &unpriced); err != nil {
+			return nil, err
+		}
+		if price, ok := opts.Premium[line.Tier]; ok {
+			line.PremiumCost = price.Cost(types.Usage{InputTokens: premiumInput, OutputTokens: premiumOutput})
+		} else {
+			line.PremiumCost = line.Cost
+		}
 
-	// Gemini stats
-	if err := l.db.QueryRow(`
-		SELECT COUNT(*), COALESCE(SUM(cost_usd), 0)
-		FROM executions
-		WHERE backend LIKE 'gemini:%'
-	`).Scan(&stats.GeminiTasks, &stats.GeminiCost); err != nil {
-		return nil, err
+		stats.TotalExecutions += line.Executions
+		stats.Unpriced += unpriced
+		switch {
+		case strings.HasPrefix(line.Backend, "claude:"):
+			stats.ClaudeTasks += line.Executions
+			stats.ClaudeCost += cost
+		case strings.HasPrefix(line.Backend, "gemini:"):
+			stats.GeminiTasks += line.Executions
+			stats.GeminiCost += cost
+		case strings.HasPrefix(line.Backend, "ollama:"):
+			stats.OllamaTasks += line.Executions
+			stats.OllamaCost += cost
+		case strings.HasPrefix(line.Backend, "openai:"):
+			stats.OpenAITasks += line.Executions
+			stats.OpenAICost += cost
+		}
+		actualCost += line.Cost
+		stats.PremiumCost += line.PremiumCost
+		stats.Savings = append(stats.Savings, line)
 	}
-
-	// Ollama stats
-	if err := l.db.QueryRow(`
-		SELECT COUNT(*), COALESCE(SUM(cost_usd), 0)
-		FROM executions
-		WHERE backend LIKE 'ollama:%'
-	`).Scan(&stats.OllamaTasks, &stats.OllamaCost); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	// Calculate savings (estimate what Claude would have cost for all tasks)
-	// Assuming average Claude cost per task of $0.05 for simple tasks
-	nonClaudeTasks := stats.OllamaTasks + stats.GeminiTasks
-	stats.EstimatedSavings = float64(nonClaudeTasks)*0.05 - stats.GeminiCost
-	if stats.ClaudeCost+stats.GeminiCost+stats.EstimatedSavings > 0 {
-		totalEstClaudeCost := stats.ClaudeCost + stats.GeminiCost + stats.EstimatedSavings
-		stats.SavingsPercent = (stats.EstimatedSavings / totalEstClaudeCost) * 100
+	stats.EstimatedSavings = stats.PremiumCost - actualCost
+	if stats.PremiumCost > 0 {
+		stats.SavingsPercent = stats.EstimatedSavings / stats.PremiumCost * 100
 	}
 
 	return stats, nil
@@ -137,7 +230,18 @@ func createSchema(db *sql.DB) error {
 		worker_backend TEXT,
 		context_path TEXT,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		heartbeat_at TIMESTAMP,
+		predicted_tier INTEGER,
+		patterns TEXT,
+		forced_tier INTEGER,
+		effective_tier INTEGER,
+		rejections INTEGER DEFAULT 0,
+		escalations INTEGER DEFAULT 0,
+		git_branch TEXT,
+		git_commit TEXT,
+		git_worktree TEXT,
+		reverted_at TIMESTAMP
 	);
 
 	-- Executions table
@@ -145,14 +249,21 @@ func createSchema(db *sql.DB) error {
 		id TEXT PRIMARY KEY,
 		task_id TEXT NOT NULL REFERENCES tasks(id),
 		worker_id TEXT,
+		kind TEXT DEFAULT 'attempt',
 		backend TEXT NOT NULL,
 		input_hash TEXT,
 		output TEXT,
 		tokens_used INTEGER DEFAULT 0,
+		input_tokens INTEGER DEFAULT 0,
+		output_tokens INTEGER DEFAULT 0,
 		cost_usd REAL DEFAULT 0,
 		duration_ms INTEGER DEFAULT 0,
 		status TEXT DEFAULT 'pending',
 		error_msg TEXT,
+		attempt INTEGER DEFAULT 1,
+		context_files TEXT,
+		diff TEXT,
+		applied_at TIMESTAMP,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -167,12 +278,34 @@ func createSchema(db *sql.DB) error {
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Events table (lifecycle events published on the message bus)
+	CREATE TABLE IF NOT EXISTS events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id TEXT,
+		type TEXT NOT NULL,
+		payload TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Classification cache (model verdicts keyed by normalized task text)
+	CREATE TABLE IF NOT EXISTS classification_cache (
+		key TEXT PRIMARY KEY,
+		tier INTEGER NOT NULL,
+		estimated_lines INTEGER DEFAULT 0,
+		estimated_files INTEGER DEFAULT 0,
+		confidence REAL DEFAULT 0,
+		rationale TEXT,
+		model TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
 	-- Indexes for common queries
 	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
 	CREATE INDEX IF NOT EXISTS idx_tasks_tier ON tasks(tier);
 	CREATE INDEX IF NOT EXISTS idx_executions_task ON executions(task_id);
 	CREATE INDEX IF NOT EXISTS idx_executions_backend ON executions(backend);
 	CREATE INDEX IF NOT EXISTS idx_validations_execution ON validations(execution_id);
+	CREATE INDEX IF NOT EXISTS idx_events_task ON events(task_id);
 
 	-- Metadata table for settings
 	CREATE TABLE IF NOT EXISTS metadata (
@@ -190,6 +323,82 @@ func createSchema(db *sql.DB) error {
 	return err
 }
 
+// columnMigrations lists columns added after the initial schema, so that
+// ledgers created by older versions gain them when opened
+var columnMigrations = []struct {
+	table  string
+	column string
+	decl   string
+}{
+	{"executions", "attempt", "INTEGER DEFAULT 1"},
+	{"tasks", "heartbeat_at", "TIMESTAMP"},
+	{"tasks", "predicted_tier", "INTEGER"},
+	{"tasks", "patterns", "TEXT"},
+	{"tasks", "forced_tier", "INTEGER"},
+	{"tasks", "effective_tier", "INTEGER"},
+	{"tasks", "rejections", "INTEGER DEFAULT 0"},
+	{"tasks", "escalations", "INTEGER DEFAULT 0"},
+	{"executions", "context_files", "TEXT"},
+	{"executions", "diff", "TEXT"},
+	{"executions", "applied_at", "TIMESTAMP"},
+	{"tasks", "git_branch", "TEXT"},
+	{"tasks", "git_commit", "TEXT"},
+	{"tasks", "git_worktree", "TEXT"},
+	{"tasks", "reverted_at", "TIMESTAMP"},
+	{"executions", "input_tokens", "INTEGER DEFAULT 0"},
+	{"executions", "output_tokens", "INTEGER DEFAULT 0"},
+	{"executions", "kind", "TEXT DEFAULT 'attempt'"},
+}
+
+// columnBackfills fill in migrated columns for the rows that predate them,
+// keyed by table and column
+var columnBackfills = map[string]string{
+	// Older ledgers marked every execution other than an attempt by its worker ID
+	"executions.kind": "UPDATE executions SET kind = worker_id, worker_id = NULL WHERE worker_id IN ('planner', 'aggregate', 'classifier')",
+}
+
+func migrate(db *sql.DB) error {
+	for _, m := range columnMigrations {
+		exists, err := hasColumn(db, m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		// #nosec G201 -- identifiers come from the static list above
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.decl)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", m.table, m.column, err)
+		}
+		if backfill, ok := columnBackfills[m.table+"."+m.column]; ok {
+			if _, err := db.Exec(backfill); err != nil {
+				return fmt.Errorf("failed to fill in %s.%s: %w", m.table, m.column, err)
+			}
+		}
+	}
+	return nil
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	// #nosec G202 -- table comes from the static migration list
+	rows, err := db.Query("SELECT name FROM pragma_table_info('" + table + "')")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
 // Task represents a task in the ledger
 type Task struct {
 	ID            string
@@ -235,11 +444,260 @@ func (l *Ledger) GetTask(id string) (*Task, error) {
 	return task, nil
 }
 
+// ClaimTask moves a task from one status to another, reporting false if the
+// task was not in the expected status (for example, another process claimed it)
+func (l *Ledger) ClaimTask(id, from, to string) (bool, error) {
+	res, err := l.db.Exec(`
+		UPDATE tasks SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = ?
+	`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Heartbeat records that the process running a task is still alive
+func (l *Ledger) Heartbeat(id string) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET heartbeat_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, id)
+	return err
+}
+
+// GetStaleTasks returns in-flight tasks (assigned, working or validating) whose
+// status and heartbeat have both not changed since the given time
+func (l *Ledger) GetStaleTasks(since time.Time) ([]*Task, error) {
+	rows, err := l.db.Query(`
+		SELECT id, parent_id, title, description, tier, status, worker_backend, context_path, created_at, updated_at
+		FROM tasks
+		WHERE status IN ('assigned', 'working', 'validating')
+			AND MAX(updated_at, COALESCE(heartbeat_at, updated_at)) < ?
+		ORDER BY created_at, rowid
+	`, since.UTC().Format(timestampLayout))
+	if err != nil {
+		return nil, err
+	}
+	return scanTasks(rows)
+}
+
+// GetTasksByStatus returns the tasks in a status, oldest first
+func (l *Ledger) GetTasksByStatus(status string) ([]*Task, error) {
+	rows, err := l.db.Query(`
+		SELECT id, parent_id, title, description, tier, status, worker_backend, context_path, created_at, updated_at
+		FROM tasks WHERE status = ?
+		ORDER BY created_at, rowid
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	return scanTasks(rows)
+}
+
+// ErrAmbiguousID is returned when a task ID prefix matches more than one task
+var ErrAmbiguousID = errors.New("ambiguous task ID")
+
+// ResolveTaskID expands a unique prefix of a task ID to the full ID
+func (l *Ledger) ResolveTaskID(prefix string) (string, error) {
+	rows, err := l.db.Query(`
+		SELECT id FROM tasks WHERE id = ? OR id LIKE ? ESCAPE '\' ORDER BY id = ? DESC, id LIMIT 2
+	`, prefix, escapeLike(prefix)+"%", prefix)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(ids) == 0:
+		return "", sql.ErrNoRows
+	case ids[0] == prefix || len(ids) == 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s matches %s, %s, ...", ErrAmbiguousID, prefix, ids[0], ids[1])
+	}
+}
+
+// TaskFilter selects tasks for ListTasks. Zero values match everything.
+type TaskFilter struct {
+	Status  string
+	Tier    *int
+	Backend string // A provider ("ollama") or backend ("ollama:fast") that was routed or ran the task
+	Since   time.Time
+	Until   time.Time
+	Search  string // Matched against title and description
+	Limit   int
+}
+
+// TaskSummary is a task with totals over its executions
+type TaskSummary struct {
+	Task
+	Executions int
+	TokensUsed int
+	CostUSD    float64
+}
+
+// ListTasks returns the tasks matching the filter, newest first
+func (l *Ledger) ListTasks(f TaskFilter) ([]*TaskSummary, error) {
+	var where []string
+	var args []interface{}
+
+	if f.Status != "" {
+		where = append(where, "t.status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Tier != nil {
+		where = append(where, "t.tier = ?")
+		args = append(args, *f.Tier)
+	}
+	if f.Backend != "" {
+		pattern := escapeLike(f.Backend)
+		if !strings.Contains(f.Backend, ":") {
+			pattern += ":%"
+		}
+		where = append(where, `(t.worker_backend LIKE ? ESCAPE '\' OR EXISTS (
+			SELECT 1 FROM executions x WHERE x.task_id = t.id AND x.backend LIKE ? ESCAPE '\'))`)
+		args = append(args, pattern, pattern)
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "t.created_at >= ?")
+		args = append(args, f.Since.UTC().Format(timestampLayout))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "t.created_at < ?")
+		args = append(args, f.Until.UTC().Format(timestampLayout))
+	}
+	if f.Search != "" {
+		pattern := "%" + escapeLike(f.Search) + "%"
+		where = append(where, `(t.title LIKE ? ESCAPE '\' OR t.description LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+
+	query := `
+		SELECT t.id, t.parent_id, t.title, COALESCE(t.description, ''), t.tier, t.status,
+			COALESCE(t.worker_backend, ''), COALESCE(t.context_path, ''), t.created_at, t.updated_at,
+			COUNT(e.id), COALESCE(SUM(e.tokens_used), 0), COALESCE(SUM(e.cost_usd), 0)
+		FROM tasks t
+		LEFT JOIN executions e ON e.task_id = t.id`
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t\tGROUP BY t.id\n\t\tORDER BY t.created_at DESC, t.rowid DESC"
+	if f.Limit > 0 {
+		query += "\n\t\tLIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*TaskSummary
+	for rows.Next() {
+		t := &TaskSummary{}
+		if err := rows.Scan(&t.ID, &t.ParentID, &t.Title, &t.Description, &t.Tier, &t.Status,
+			&t.WorkerBackend, &t.ContextPath, &t.CreatedAt, &t.UpdatedAt,
+			&t.Executions, &t.TokensUsed, &t.CostUSD); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// escapeLike escapes LIKE wildcards so that s matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetSubtasks returns the direct subtasks of a task in creation order
+func (l *Ledger) GetSubtasks(parentID string) ([]*Task, error) {
+	rows, err := l.db.Query(`
+		SELECT id, parent_id, title, description, tier, status, worker_backend, context_path, created_at, updated_at
+		FROM tasks WHERE parent_id = ?
+		ORDER BY created_at, rowid
+	`, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTasks(rows)
+}
+
+func scanTasks(rows *sql.Rows) ([]*Task, error) {
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		task := &Task{}
+		if err := rows.Scan(&task.ID, &task.ParentID, &task.Title, &task.Description, &task.Tier, &task.Status,
+			&task.WorkerBackend, &task.ContextPath, &task.CreatedAt, &task.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// TaskNode is a task together with its subtasks
+type TaskNode struct {
+	Task     *Task
+	Children []*TaskNode
+}
+
+// GetTaskTree returns a task and all of its descendants
+func (l *Ledger) GetTaskTree(id string) (*TaskNode, error) {
+	task, err := l.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	return l.buildTree(task)
+}
+
+func (l *Ledger) buildTree(task *Task) (*TaskNode, error) {
+	node := &TaskNode{Task: task}
+
+	children, err := l.GetSubtasks(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childNode, err := l.buildTree(child)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+// Execution kinds: what a recorded model call was for
+const (
+	KindAttempt    = "attempt"    // A worker's attempt at the task itself
+	KindPlanner    = "planner"    // Breaking the task into subtasks
+	KindAggregate  = "aggregate"  // The combined output of the task's subtasks
+	KindClassifier = "classifier" // Choosing the task's tier
+	KindValidator  = "validator"  // Reviewing an attempt's output
+)
+
 // Execution represents a task execution attempt
 type Execution struct {
 	ID         string
 	TaskID     string
-	WorkerID   string
+	WorkerID   string // The pool worker that ran it, if any
+	Kind       string // One of the Kind constants; KindAttempt when empty
 	Backend    string
 	InputHash  string
 	Output     string
@@ -248,15 +706,501 @@ type Execution struct {
 	DurationMs int
 	Status     string
 	ErrorMsg   string
+	Attempt    int
 	CreatedAt  time.Time
+
+	// InputTokens and OutputTokens split TokensUsed where the backend
+	// reports usage; cached input counts as input
+	InputTokens  int
+	OutputTokens int
+
+	// ContextFiles lists the repository files packed into the prompt, with
+	// a line range for files that were cut down to fit
+	ContextFiles []string
+
+	// Diff is the change the execution makes to the working tree, as a
+	// unified diff; AppliedAt is set once it has been written
+	Diff      string
+	AppliedAt *time.Time
 }
 
 // CreateExecution records a new execution attempt
 func (l *Ledger) CreateExecution(exec *Execution) error {
+	attempt := exec.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+	kind := exec.Kind
+	if kind == "" {
+		kind = KindAttempt
+	}
+
+	var contextFiles *string
+	if len(exec.ContextFiles) > 0 {
+		data, err := json.Marshal(exec.ContextFiles)
+		if err != nil {
+			return err
+		}
+		s := string(data)
+		contextFiles = &s
+	}
+
+	_, err := l.db.Exec(`
+		INSERT INTO executions (id, task_id, worker_id, kind, backend, input_hash, output, tokens_used, input_tokens, output_tokens, cost_usd, duration_ms, status, error_msg, attempt, context_files, diff)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+	`, exec.ID, exec.TaskID, exec.WorkerID, kind, exec.Backend, exec.InputHash, exec.Output,
+		exec.TokensUsed, exec.InputTokens, exec.OutputTokens, exec.CostUSD, exec.DurationMs, exec.Status, exec.ErrorMsg, attempt, contextFiles, exec.Diff)
+	return err
+}
+
+// GetExecutions returns every execution attempt for a task in order
+func (l *Ledger) GetExecutions(taskID string) ([]*Execution, error) {
+	rows, err := l.db.Query(`
+		SELECT id, task_id, COALESCE(worker_id, ''), kind, backend, COALESCE(input_hash, ''), COALESCE(output, ''),
+			tokens_used, input_tokens, output_tokens, cost_usd, duration_ms, status, COALESCE(error_msg, ''), attempt, created_at,
+			COALESCE(context_files, '[]'), COALESCE(diff, ''), applied_at
+		FROM executions WHERE task_id = ?
+		ORDER BY attempt, created_at
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*Execution
+	for rows.Next() {
+		e := &Execution{}
+		var contextFiles string
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.WorkerID, &e.Kind, &e.Backend, &e.InputHash, &e.Output,
+			&e.TokensUsed, &e.InputTokens, &e.OutputTokens, &e.CostUSD, &e.DurationMs, &e.Status, &e.ErrorMsg, &e.Attempt, &e.CreatedAt,
+			&contextFiles, &e.Diff, &appliedAt); err != nil {
+			return nil, err
+		}
+		if appliedAt.Valid {
+			e.AppliedAt = &appliedAt.Time
+		}
+		if err := json.Unmarshal([]byte(contextFiles), &e.ContextFiles); err != nil {
+			return nil, fmt.Errorf("execution %s has unreadable context files: %w", e.ID, err)
+		}
+		executions = append(executions, e)
+	}
+	return executions, rows.Err()
+}
+
+// MarkApplied records that an execution's diff was written to the working tree
+func (l *Ledger) MarkApplied(executionID string) error {
+	res, err := l.db.Exec(`UPDATE executions SET applied_at = CURRENT_TIMESTAMP WHERE id = ?`, executionID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("execution %s not found", executionID)
+	}
+	return nil
+}
+
+// Validation represents a single validator's verdict on an execution
+type Validation struct {
+	ID          string
+	ExecutionID string
+	ValidatorID string
+	Backend     string
+	Verdict     string
+	Findings    string
+	CreatedAt   time.Time
+}
+
+// CreateValidation records a validator verdict
+func (l *Ledger) CreateValidation(v *Validation) error {
+	_, err := l.db.Exec(`
+		INSERT INTO validations (id, execution_id, validator_id, backend, verdict, findings)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, v.ID, v.ExecutionID, v.ValidatorID, v.Backend, v.Verdict, v.Findings)
+	return err
+}
+
+// GetValidations returns all verdicts recorded for an execution
+func (l *Ledger) GetValidations(executionID string) ([]*Validation, error) {
+	rows, err := l.db.Query(`
+		SELECT id, execution_id, validator_id, backend, verdict, findings, created_at
+		FROM validations WHERE execution_id = ?
+		ORDER BY validator_id
+	`, executionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var validations []*Validation
+	for rows.Next() {
+		v := &Validation{}
+		if err := rows.Scan(&v.ID, &v.ExecutionID, &v.ValidatorID, &v.Backend, &v.Verdict, &v.Findings, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		validations = append(validations, v)
+	}
+	return validations, rows.Err()
+}
+
+// GetSpendSince totals execution cost since the given time for backends with the given prefix
+func (l *Ledger) GetSpendSince(since time.Time, backendPrefix string) (float64, error) {
+	var total float64
+	err := l.db.QueryRow(`
+		SELECT COALESCE(SUM(cost_usd), 0)
+		FROM executions
+		WHERE created_at >= ? AND backend LIKE ?
+	`, since.UTC().Format(timestampLayout), backendPrefix+"%").Scan(&total)
+	return total, err
+}
+
+// GetPlanSpend returns the total cost of a task's executions and those of its subtasks
+func (l *Ledger) GetPlanSpend(taskID string) (float64, error) {
+	var total float64
+	err := l.db.QueryRow(`
+		SELECT COALESCE(SUM(e.cost_usd), 0)
+		FROM executions e JOIN tasks t ON t.id = e.task_id
+		WHERE t.id = ? OR t.parent_id = ?
+	`, taskID, taskID).Scan(&total)
+	return total, err
+}
+
+// ExecutionFilter selects executions for ListExecutions. Zero values match everything.
+type ExecutionFilter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ExecutionRecord is an execution, without its output, alongside the task it ran for
+type ExecutionRecord struct {
+	ID           string
+	TaskID       string
+	WorkerID     string
+	Kind         string
+	Backend      string
+	Status       string
+	TokensUsed   int
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+	DurationMs   int
+	CreatedAt    time.Time
+
+	TaskTitle       string
+	TaskTier        int
+	TaskStatus      string
+	TaskEscalations int
+}
+
+// ListExecutions returns the executions created in the filter's window, oldest first
+func (l *Ledger) ListExecutions(f ExecutionFilter) ([]*ExecutionRecord, error) {
+	query := `
+		SELECT e.id, e.task_id, COALESCE(e.worker_id, ''), e.kind, e.backend, e.status,
+			e.tokens_used, e.input_tokens, e.output_tokens, e.cost_usd, e.duration_ms, e.created_at,
+			t.title, t.tier, t.status, COALESCE(t.escalations, 0)
+		FROM executions e
+		JOIN tasks t ON t.id = e.task_id`
+	var where []string
+	var args []interface{}
+	if !f.Since.IsZero() {
+		where = append(where, "e.created_at >= ?")
+		args = append(args, f.Since.UTC().Format(timestampLayout))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "e.created_at < ?")
+		args = append(args, f.Until.UTC().Format(timestampLayout))
+	}
+	if len(where) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\n\t\tORDER BY e.created_at, e.rowid"
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*ExecutionRecord
+	for rows.Next() {
+		r := &ExecutionRecord{}
+		if err := rows.Scan(&r.ID, &r.TaskID, &r.WorkerID, &r.Kind, &r.Backend, &r.Status,
+			&r.TokensUsed, &r.InputTokens, &r.OutputTokens, &r.CostUSD, &r.DurationMs, &r.CreatedAt,
+			&r.TaskTitle, &r.TaskTier, &r.TaskStatus, &r.TaskEscalations); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// Event represents a lifecycle event recorded from the message bus
+type Event struct {
+	ID        int64
+	TaskID    string
+	Type      string
+	Payload   string
+	CreatedAt time.Time
+}
+
+// RecordEvent appends an event to the event log
+func (l *Ledger) RecordEvent(e *Event) error {
 	_, err := l.db.Exec(`
-		INSERT INTO executions (id, task_id, worker_id, backend, input_hash, output, tokens_used, cost_usd, duration_ms, status, error_msg)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, exec.ID, exec.TaskID, exec.WorkerID, exec.Backend, exec.InputHash, exec.Output,
-		exec.TokensUsed, exec.CostUSD, exec.DurationMs, exec.Status, exec.ErrorMsg)
+		INSERT INTO events (task_id, type, payload)
+		VALUES (?, ?, ?)
+	`, e.TaskID, e.Type, e.Payload)
 	return err
 }
+
+// GetEvents returns the events recorded for a task in order
+func (l *Ledger) GetEvents(taskID string) ([]*Event, error) {
+	rows, err := l.db.Query(`
+		SELECT id, task_id, type, COALESCE(payload, ''), created_at
+		FROM events WHERE task_id = ?
+		ORDER BY id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Type, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CachedClassification is a model's classification of a task text
+type CachedClassification struct {
+	Key            string
+	Tier           int
+	EstimatedLines int
+	EstimatedFiles int
+	Confidence     float64
+	Rationale      string
+	Model          string
+	CreatedAt      time.Time
+}
+
+// GetCachedClassification returns the cached classification for a key, or nil if there is none
+func (l *Ledger) GetCachedClassification(key string) (*CachedClassification, error) {
+	c := &CachedClassification{}
+	err := l.db.QueryRow(`
+		SELECT key, tier, estimated_lines, estimated_files, confidence, COALESCE(rationale, ''), COALESCE(model, ''), created_at
+		FROM classification_cache WHERE key = ?
+	`, key).Scan(&c.Key, &c.Tier, &c.EstimatedLines, &c.EstimatedFiles, &c.Confidence, &c.Rationale, &c.Model, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CacheClassification stores a classification, replacing any previous one for the key
+func (l *Ledger) CacheClassification(c *CachedClassification) error {
+	_, err := l.db.Exec(`
+		INSERT OR REPLACE INTO classification_cache (key, tier, estimated_lines, estimated_files, confidence, rationale, model)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, c.Key, c.Tier, c.EstimatedLines, c.EstimatedFiles, c.Confidence, c.Rationale, c.Model)
+	return err
+}
+
+// PatternHit is a classifier pattern that matched a task, and the tier it scored for
+type PatternHit struct {
+	Tier int    `json:"tier"`
+	Name string `json:"name"`
+}
+
+// RecordPrediction stores the tier the classifier chose for a task and the
+// patterns that matched it, for later calibration against the outcome
+func (l *Ledger) RecordPrediction(taskID string, tier int, hits []PatternHit) error {
+	if hits == nil {
+		hits = []PatternHit{}
+	}
+	data, err := json.Marshal(hits)
+	if err != nil {
+		return err
+	}
+	_, err = l.db.Exec(`
+		UPDATE tasks SET predicted_tier = ?, patterns = ? WHERE id = ?
+	`, tier, string(data), taskID)
+	return err
+}
+
+// RecordRejection counts an attempt at a task that validators rejected
+func (l *Ledger) RecordRejection(taskID string) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET rejections = rejections + 1 WHERE id = ?
+	`, taskID)
+	return err
+}
+
+// RecordEscalation counts a move of a task up its tier's escalation ladder
+func (l *Ledger) RecordEscalation(taskID string) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET escalations = escalations + 1 WHERE id = ?
+	`, taskID)
+	return err
+}
+
+// TaskCommit is the git commit holding a task's changes
+type TaskCommit struct {
+	Branch     string
+	SHA        string
+	Worktree   string     // Checkout of the branch kept for the task; empty if none
+	RevertedAt *time.Time // When the commit was reverted or its branch deleted
+}
+
+// SetCommit records the commit a task's changes were committed as
+func (l *Ledger) SetCommit(taskID string, c *TaskCommit) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET git_branch = ?, git_commit = ?, git_worktree = NULLIF(?, ''), reverted_at = NULL WHERE id = ?
+	`, c.Branch, c.SHA, c.Worktree, taskID)
+	return err
+}
+
+// GetCommit returns the commit recorded for a task, or nil if there is none
+func (l *Ledger) GetCommit(taskID string) (*TaskCommit, error) {
+	c := &TaskCommit{}
+	var revertedAt sql.NullTime
+	err := l.db.QueryRow(`
+		SELECT COALESCE(git_branch, ''), COALESCE(git_commit, ''), COALESCE(git_worktree, ''), reverted_at
+		FROM tasks WHERE id = ?
+	`, taskID).Scan(&c.Branch, &c.SHA, &c.Worktree, &revertedAt)
+	if err != nil {
+		return nil, err
+	}
+	if c.SHA == "" {
+		return nil, nil
+	}
+	if revertedAt.Valid {
+		c.RevertedAt = &revertedAt.Time
+	}
+	return c, nil
+}
+
+// MarkReverted records that a task's commit was undone
+func (l *Ledger) MarkReverted(taskID string) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET reverted_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, taskID)
+	return err
+}
+
+// TierEscalation counts how many tasks of a tier had to escalate
+type TierEscalation struct {
+	Tier      int
+	Tasks     int // Tasks that ran at least one attempt
+	Escalated int
+	Rescued   int // Escalated tasks that went on to succeed
+}
+
+// GetEscalationStats returns escalation counts for every tier that has run
+// tasks created since the given time
+func (l *Ledger) GetEscalationStats(since time.Time) ([]*TierEscalation, error) {
+	rows, err := l.db.Query(`
+		SELECT t.tier, COUNT(*),
+		       COALESCE(SUM(CASE WHEN t.escalations > 0 THEN 1 ELSE 0 END), 0),
+		       COALESCE(SUM(CASE WHEN t.escalations > 0 AND t.status IN ('done', 'approved') THEN 1 ELSE 0 END), 0)
+		FROM tasks t
+		WHERE t.created_at >= ? AND EXISTS (
+			SELECT 1 FROM executions e WHERE e.task_id = t.id AND e.kind = 'attempt'
+		)
+		GROUP BY t.tier
+		ORDER BY t.tier
+	`, since.UTC().Format(timestampLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*TierEscalation
+	for rows.Next() {
+		s := &TierEscalation{}
+		if err := rows.Scan(&s.Tier, &s.Tasks, &s.Escalated, &s.Rescued); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// SetForcedTier records that the user overrode the classifier's tier for a task
+func (l *Ledger) SetForcedTier(taskID string, tier int) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET forced_tier = ? WHERE id = ?
+	`, tier, taskID)
+	return err
+}
+
+// SetEffectiveTier records the tier a task turned out to need, such as the
+// tier of the backend it finally succeeded on
+func (l *Ledger) SetEffectiveTier(taskID string, tier int) error {
+	_, err := l.db.Exec(`
+		UPDATE tasks SET effective_tier = ? WHERE id = ?
+	`, tier, taskID)
+	return err
+}
+
+// ClassificationOutcome pairs a task's predicted tier with what happened to it
+type ClassificationOutcome struct {
+	TaskID         string
+	PredictedTier  int
+	Patterns       []PatternHit
+	ForcedTier     *int
+	EffectiveTier  *int
+	Status         string
+	Attempts       int // Worker attempts, not counting planning or classification
+	FailedAttempts int
+	Rejections     int
+}
+
+// GetClassificationOutcomes returns every task with a recorded prediction, oldest first
+func (l *Ledger) GetClassificationOutcomes() ([]*ClassificationOutcome, error) {
+	rows, err := l.db.Query(`
+		SELECT t.id, t.predicted_tier, COALESCE(t.patterns, '[]'), t.forced_tier, t.effective_tier, t.status,
+		       COALESCE(t.rejections, 0),
+		       COUNT(e.id),
+		       COALESCE(SUM(CASE WHEN e.status = 'failed' THEN 1 ELSE 0 END), 0)
+		FROM tasks t
+		LEFT JOIN executions e ON e.task_id = t.id AND e.kind = 'attempt'
+		WHERE t.predicted_tier IS NOT NULL
+		GROUP BY t.id
+		ORDER BY t.created_at, t.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outcomes []*ClassificationOutcome
+	for rows.Next() {
+		o := &ClassificationOutcome{}
+		var patterns string
+		var forced, effective sql.NullInt64
+		if err := rows.Scan(&o.TaskID, &o.PredictedTier, &patterns, &forced, &effective, &o.Status,
+			&o.Rejections, &o.Attempts, &o.FailedAttempts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(patterns), &o.Patterns); err != nil {
+			return nil, fmt.Errorf("task %s has unreadable patterns: %w", o.TaskID, err)
+		}
+		if forced.Valid {
+			t := int(forced.Int64)
+			o.ForcedTier = &t
+		}
+		if effective.Valid {
+			t := int(effective.Int64)
+			o.EffectiveTier = &t
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, rows.Err()
+}
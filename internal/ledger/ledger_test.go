@@ -1,8 +1,16 @@
 package ledger
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/cammy/bigo/internal/pricing"
 )
 
 func TestLedger_Init(t *testing.T) {
@@ -30,6 +38,51 @@ func TestLedger_Init(t *testing.T) {
 	}
 }
 
+func TestLedger_MigrateExecutionKinds(t *testing.T) {
+	path := t.TempDir() + "/ledger.db"
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Before the kind column, anything but an attempt was marked by its worker ID
+	if _, err := db.Exec(`
+		CREATE TABLE executions (
+			id TEXT PRIMARY KEY, task_id TEXT NOT NULL, worker_id TEXT, backend TEXT NOT NULL,
+			input_hash TEXT, output TEXT, tokens_used INTEGER DEFAULT 0, cost_usd REAL DEFAULT 0,
+			duration_ms INTEGER DEFAULT 0, status TEXT DEFAULT 'pending', error_msg TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO executions (id, task_id, worker_id, backend) VALUES
+			('e1', 't1', 'classifier', 'claude:haiku'),
+			('e2', 't1', 'planner', 'claude:opus'),
+			('e3', 't1', '', 'ollama:default'),
+			('e4', 't1', 'aggregate', 'conductor');
+	`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer l.Close()
+
+	execs, err := l.GetExecutions("t1")
+	if err != nil {
+		t.Fatalf("GetExecutions failed: %v", err)
+	}
+	want := []string{KindClassifier, KindPlanner, KindAttempt, KindAggregate}
+	if len(execs) != len(want) {
+		t.Fatalf("Expected %d executions, got %d", len(want), len(execs))
+	}
+	for i, e := range execs {
+		if e.Kind != want[i] || e.WorkerID != "" {
+			t.Errorf("Expected execution %s to be %q without a worker ID, got %q (%q)", e.ID, want[i], e.Kind, e.WorkerID)
+		}
+	}
+}
+
 func TestLedger_Operations(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "ledger-ops-*.db")
 	if err != nil {
@@ -80,18 +133,54 @@ func TestLedger_Operations(t *testing.T) {
 
 	// Create Execution
 	exec := &Execution{
-		ID:         "exec-1",
-		TaskID:     "task-1",
-		Backend:    "gemini:pro",
-		TokensUsed: 100,
-		CostUSD:    0.01,
+		ID:           "exec-1",
+		TaskID:       "task-1",
+		Backend:      "gemini:pro",
+		TokensUsed:   100,
+		InputTokens:  80,
+		OutputTokens: 20,
+		CostUSD:      0.01,
+		Diff:         "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\n",
 	}
 	if execErr := l.CreateExecution(exec); execErr != nil {
 		t.Fatalf("CreateExecution failed: %v", execErr)
 	}
 
+	// Mark its diff applied
+	if applyErr := l.MarkApplied("exec-1"); applyErr != nil {
+		t.Fatalf("MarkApplied failed: %v", applyErr)
+	}
+	execs, err := l.GetExecutions("task-1")
+	if err != nil {
+		t.Fatalf("GetExecutions failed: %v", err)
+	}
+	if len(execs) != 1 || execs[0].Diff != exec.Diff || execs[0].AppliedAt == nil {
+		t.Errorf("Expected the applied diff back, got %+v", execs)
+	}
+	if len(execs) == 1 && (execs[0].InputTokens != 80 || execs[0].OutputTokens != 20) {
+		t.Errorf("Expected 80 input and 20 output tokens, got %d and %d", execs[0].InputTokens, execs[0].OutputTokens)
+	}
+	if l.MarkApplied("missing") == nil {
+		t.Error("Expected marking an unknown execution to fail")
+	}
+
+	// Record and revert a commit
+	if c, commitErr := l.GetCommit("task-1"); commitErr != nil || c != nil {
+		t.Fatalf("Expected no commit yet, got %+v (%v)", c, commitErr)
+	}
+	if commitErr := l.SetCommit("task-1", &TaskCommit{Branch: "bigo/task-1", SHA: "abc123"}); commitErr != nil {
+		t.Fatalf("SetCommit failed: %v", commitErr)
+	}
+	if revertErr := l.MarkReverted("task-1"); revertErr != nil {
+		t.Fatalf("MarkReverted failed: %v", revertErr)
+	}
+	c, err := l.GetCommit("task-1")
+	if err != nil || c == nil || c.SHA != "abc123" || c.Branch != "bigo/task-1" || c.Worktree != "" || c.RevertedAt == nil {
+		t.Errorf("Expected the reverted commit back, got %+v (%v)", c, err)
+	}
+
 	// Check Stats
-	stats, err := l.GetStats()
+	stats, err := l.GetStats(StatsOptions{})
 	if err != nil {
 		t.Fatalf("GetStats failed: %v", err)
 	}
@@ -111,3 +200,454 @@ func TestLedger_Operations(t *testing.T) {
 		t.Errorf("Expected Gemini cost 0.01, got %f", stats.GeminiCost)
 	}
 }
+
+func TestLedger_Validations(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-val-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if err := l.CreateTask(&Task{ID: "task-1", Title: "Test Task", Status: "validating"}); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if err := l.CreateExecution(&Execution{ID: "exec-1", TaskID: "task-1", Backend: "ollama:default"}); err != nil {
+		t.Fatalf("CreateExecution failed: %v", err)
+	}
+
+	for _, v := range []*Validation{
+		{ID: "val-1", ExecutionID: "exec-1", ValidatorID: "validator-1", Backend: "claude:sonnet", Verdict: "approved", Findings: "[]"},
+		{ID: "val-2", ExecutionID: "exec-1", ValidatorID: "validator-2", Backend: "claude:sonnet", Verdict: "rejected", Findings: `[{"Severity":"error"}]`},
+	} {
+		if err := l.CreateValidation(v); err != nil {
+			t.Fatalf("CreateValidation failed: %v", err)
+		}
+	}
+
+	got, err := l.GetValidations("exec-1")
+	if err != nil {
+		t.Fatalf("GetValidations failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 validations, got %d", len(got))
+	}
+	if got[1].Verdict != "rejected" {
+		t.Errorf("Expected second verdict rejected, got %s", got[1].Verdict)
+	}
+}
+
+func TestLedger_GetSpendSince(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-spend-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if err := l.CreateTask(&Task{ID: "task-1", Title: "Test Task"}); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	for i, e := range []*Execution{
+		{ID: "exec-1", TaskID: "task-1", Backend: "claude:sonnet", CostUSD: 0.25},
+		{ID: "exec-2", TaskID: "task-1", Backend: "claude:opus", CostUSD: 1.00},
+		{ID: "exec-3", TaskID: "task-1", Backend: "gemini:pro", CostUSD: 0.50},
+	} {
+		if err := l.CreateExecution(e); err != nil {
+			t.Fatalf("CreateExecution %d failed: %v", i, err)
+		}
+	}
+
+	spent, err := l.GetSpendSince(time.Now().Add(-time.Hour), "claude:")
+	if err != nil {
+		t.Fatalf("GetSpendSince failed: %v", err)
+	}
+	if spent != 1.25 {
+		t.Errorf("Expected $1.25 Claude spend, got %f", spent)
+	}
+
+	spent, err = l.GetSpendSince(time.Now().Add(time.Hour), "claude:")
+	if err != nil {
+		t.Fatalf("GetSpendSince failed: %v", err)
+	}
+	if spent != 0 {
+		t.Errorf("Expected no spend in the future, got %f", spent)
+	}
+}
+
+func TestLedger_Savings(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-savings-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for _, task := range []*Task{
+		{ID: "simple", Title: "Simple task", Tier: 1, Status: "done"},
+		{ID: "complex", Title: "Complex task", Tier: 3, Status: "done"},
+	} {
+		if err := l.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+	for i, e := range []*Execution{
+		{ID: "exec-1", TaskID: "simple", Backend: "ollama:default", TokensUsed: 1_000_000, InputTokens: 1_000_000},
+		{ID: "exec-2", TaskID: "simple", Kind: KindClassifier, Backend: "ollama:default", TokensUsed: 1000, InputTokens: 1000},
+		{ID: "exec-3", TaskID: "simple", Backend: "claude:haiku", TokensUsed: 600_000, InputTokens: 500_000, OutputTokens: 100_000, CostUSD: 0.9},
+		{ID: "exec-4", TaskID: "complex", Backend: "claude:opus", TokensUsed: 500, CostUSD: 0.2}, // Recorded before the token split
+	} {
+		if err := l.CreateExecution(e); err != nil {
+			t.Fatalf("CreateExecution %d failed: %v", i, err)
+		}
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	stats, err := l.GetStats(StatsOptions{Premium: map[int]pricing.Price{1: {Input: 3, Output: 15}}})
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalExecutions != 4 || stats.Unpriced != 1 || !near(stats.ClaudeCost, 1.1) {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	// Ollama would have cost $3 on the premium backend; the classifier call has no counterpart
+	if !near(stats.PremiumCost, 6) || !near(stats.EstimatedSavings, 5.1) || !near(stats.SavingsPercent, 85) {
+		t.Errorf("Expected $5.10 saved of $6 (85%%), got $%f of $%f (%f%%)",
+			stats.EstimatedSavings, stats.PremiumCost, stats.SavingsPercent)
+	}
+
+	want := []SavingsLine{
+		{Tier: 1, Backend: "claude:haiku", Executions: 1, InputTokens: 500_000, OutputTokens: 100_000, Cost: 0.9, PremiumCost: 3},
+		{Tier: 1, Backend: "ollama:default", Executions: 2, InputTokens: 1_001_000, PremiumCost: 3},
+		{Tier: 3, Backend: "claude:opus", Executions: 1},
+	}
+	if len(stats.Savings) != len(want) {
+		t.Fatalf("Expected %d savings lines, got %d", len(want), len(stats.Savings))
+	}
+	for i, w := range want {
+		got := *stats.Savings[i]
+		if got.Tier != w.Tier || got.Backend != w.Backend || got.Executions != w.Executions ||
+			got.InputTokens != w.InputTokens || got.OutputTokens != w.OutputTokens ||
+			!near(got.Cost, w.Cost) || !near(got.PremiumCost, w.PremiumCost) {
+			t.Errorf("Savings line %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	stats, err = l.GetStats(StatsOptions{Since: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalTasks != 0 || stats.TotalExecutions != 0 || len(stats.Savings) != 0 {
+		t.Errorf("Expected nothing in the future, got %+v", stats)
+	}
+}
+
+func TestLedger_ListExecutions(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-list-execs-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if err := l.CreateTask(&Task{ID: "task-1", Title: "Test Task", Tier: 2, Status: "done"}); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if err := l.RecordEscalation("task-1"); err != nil {
+		t.Fatalf("RecordEscalation failed: %v", err)
+	}
+	for i, e := range []*Execution{
+		{ID: "exec-1", TaskID: "task-1", Backend: "ollama:default", Status: "failed", DurationMs: 120, Output: "long output"},
+		{ID: "exec-2", TaskID: "task-1", Kind: KindPlanner, Backend: "claude:opus", Status: "completed", InputTokens: 10, OutputTokens: 5, CostUSD: 0.5},
+	} {
+		if err := l.CreateExecution(e); err != nil {
+			t.Fatalf("CreateExecution %d failed: %v", i, err)
+		}
+	}
+
+	records, err := l.ListExecutions(ExecutionFilter{Since: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("ListExecutions failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 executions, got %d", len(records))
+	}
+	if r := records[0]; r.ID != "exec-1" || r.Kind != KindAttempt || r.Status != "failed" || r.DurationMs != 120 ||
+		r.TaskTitle != "Test Task" || r.TaskTier != 2 || r.TaskStatus != "done" || r.TaskEscalations != 1 {
+		t.Errorf("Unexpected first record: %+v", r)
+	}
+	if r := records[1]; r.Kind != KindPlanner || r.InputTokens != 10 || r.OutputTokens != 5 || r.CostUSD != 0.5 {
+		t.Errorf("Unexpected second record: %+v", r)
+	}
+
+	records, err = l.ListExecutions(ExecutionFilter{Until: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("ListExecutions failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected no executions before the window, got %d", len(records))
+	}
+}
+
+func TestLedger_StaleTasks(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-stale-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for _, task := range []*Task{
+		{ID: "silent", Title: "Working with no heartbeat", Status: "working"},
+		{ID: "alive", Title: "Working with a recent heartbeat", Status: "working"},
+		{ID: "finished", Title: "Already done", Status: "done"},
+	} {
+		if err := l.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+	if _, err := l.db.Exec(`UPDATE tasks SET updated_at = datetime('now', '-10 minutes')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Heartbeat("alive"); err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+
+	stale, err := l.GetStaleTasks(time.Now().Add(-5 * time.Minute))
+	if err != nil {
+		t.Fatalf("GetStaleTasks failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0].ID != "silent" {
+		t.Fatalf("Expected only the silent task to be stale, got %d", len(stale))
+	}
+
+	claimed, err := l.ClaimTask("silent", "working", "orphaned")
+	if err != nil || !claimed {
+		t.Fatalf("Expected to claim the stale task: %v", err)
+	}
+	claimed, err = l.ClaimTask("silent", "working", "orphaned")
+	if err != nil || claimed {
+		t.Errorf("Expected a second claim to fail: %v", err)
+	}
+
+	orphaned, err := l.GetTasksByStatus("orphaned")
+	if err != nil {
+		t.Fatalf("GetTasksByStatus failed: %v", err)
+	}
+	if len(orphaned) != 1 || orphaned[0].ID != "silent" {
+		t.Errorf("Expected the silent task to be orphaned, got %d tasks", len(orphaned))
+	}
+}
+
+func TestLedger_ListTasks(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-list-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	parent := "abc123"
+	for _, task := range []*Task{
+		{ID: "abc123", Title: "Refactor the parser", Tier: 3, Status: "done", WorkerBackend: "claude:sonnet"},
+		{ID: "abc456", ParentID: &parent, Title: "Fix typo in parser docs", Description: "100% wrong", Tier: 0, Status: "done", WorkerBackend: "ollama:fast"},
+		{ID: "def789", Title: "Add login form", Tier: 2, Status: "failed", WorkerBackend: "claude:sonnet"},
+	} {
+		if err := l.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+	for _, e := range []*Execution{
+		{ID: "e1", TaskID: "def789", Backend: "claude:sonnet", CostUSD: 0.10, TokensUsed: 100},
+		{ID: "e2", TaskID: "def789", Backend: "ollama:reasoning", CostUSD: 0, TokensUsed: 50, Attempt: 2},
+	} {
+		if err := l.CreateExecution(e); err != nil {
+			t.Fatalf("CreateExecution failed: %v", err)
+		}
+	}
+	if _, err := l.db.Exec(`UPDATE tasks SET created_at = datetime('now', '-3 days') WHERE id = 'abc123'`); err != nil {
+		t.Fatal(err)
+	}
+
+	tier := 0
+	tests := []struct {
+		name   string
+		filter TaskFilter
+		want   []string
+	}{
+		{"all, newest first", TaskFilter{}, []string{"def789", "abc456", "abc123"}},
+		{"status", TaskFilter{Status: "done"}, []string{"abc456", "abc123"}},
+		{"tier", TaskFilter{Tier: &tier}, []string{"abc456"}},
+		{"provider matches executions", TaskFilter{Backend: "ollama"}, []string{"def789", "abc456"}},
+		{"exact backend", TaskFilter{Backend: "ollama:fast"}, []string{"abc456"}},
+		{"since", TaskFilter{Since: time.Now().Add(-24 * time.Hour)}, []string{"def789", "abc456"}},
+		{"until", TaskFilter{Until: time.Now().Add(-24 * time.Hour)}, []string{"abc123"}},
+		{"search title", TaskFilter{Search: "PARSER"}, []string{"abc456", "abc123"}},
+		{"search is literal", TaskFilter{Search: "100%"}, []string{"abc456"}},
+		{"limit", TaskFilter{Limit: 1}, []string{"def789"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := l.ListTasks(tt.filter)
+			if err != nil {
+				t.Fatalf("ListTasks failed: %v", err)
+			}
+			var got []string
+			for _, task := range tasks {
+				got = append(got, task.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	tasks, err := l.ListTasks(TaskFilter{Status: "failed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].Executions != 2 || tasks[0].TokensUsed != 150 || tasks[0].CostUSD != 0.10 {
+		t.Errorf("Unexpected totals: %d executions, %d tokens, $%.2f", tasks[0].Executions, tasks[0].TokensUsed, tasks[0].CostUSD)
+	}
+
+	if id, err := l.ResolveTaskID("def"); err != nil || id != "def789" {
+		t.Errorf("Expected def to resolve to def789, got %q (%v)", id, err)
+	}
+	if _, err := l.ResolveTaskID("abc"); !errors.Is(err, ErrAmbiguousID) {
+		t.Errorf("Expected an ambiguous ID error, got %v", err)
+	}
+	if _, err := l.ResolveTaskID("zzz"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected no rows, got %v", err)
+	}
+}
+
+func TestLedger_ClassificationCache(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-cache-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer l.Close()
+
+	if c, err := l.GetCachedClassification("missing"); err != nil || c != nil {
+		t.Fatalf("Expected no cached classification, got %+v (%v)", c, err)
+	}
+
+	entry := &CachedClassification{Key: "k", Tier: 3, EstimatedLines: 400, EstimatedFiles: 6, Confidence: 0.7, Rationale: "spans services", Model: "claude:haiku"}
+	if err := l.CacheClassification(entry); err != nil {
+		t.Fatalf("CacheClassification failed: %v", err)
+	}
+	entry.Tier = 2
+	if err := l.CacheClassification(entry); err != nil {
+		t.Fatalf("CacheClassification replace failed: %v", err)
+	}
+
+	got, err := l.GetCachedClassification("k")
+	if err != nil {
+		t.Fatalf("GetCachedClassification failed: %v", err)
+	}
+	if got.Tier != 2 || got.EstimatedLines != 400 || got.EstimatedFiles != 6 || got.Rationale != "spans services" || got.Model != "claude:haiku" {
+		t.Errorf("Unexpected cached classification: %+v", got)
+	}
+}
+
+func TestLedger_ClassificationOutcomes(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "ledger-outcomes-*.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Close()
+
+	l, err := Init(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer l.Close()
+
+	for _, id := range []string{"t1", "t2"} {
+		if err := l.CreateTask(&Task{ID: id, Title: id, Tier: 1, Status: "done"}); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+	// t2 has no prediction and is left out
+	if err := l.RecordPrediction("t1", 1, []PatternHit{{Tier: 1, Name: "add_field"}}); err != nil {
+		t.Fatalf("RecordPrediction failed: %v", err)
+	}
+	if err := l.RecordRejection("t1"); err != nil {
+		t.Fatalf("RecordRejection failed: %v", err)
+	}
+	if err := l.SetForcedTier("t1", 3); err != nil {
+		t.Fatalf("SetForcedTier failed: %v", err)
+	}
+	for i, status := range []string{"failed", "completed"} {
+		exec := &Execution{ID: fmt.Sprintf("e%d", i), TaskID: "t1", Backend: "ollama:default", Status: status, Attempt: i + 1}
+		if err := l.CreateExecution(exec); err != nil {
+			t.Fatalf("CreateExecution failed: %v", err)
+		}
+	}
+	if err := l.CreateExecution(&Execution{ID: "p", TaskID: "t1", Kind: KindPlanner, Backend: "claude:opus", Status: "completed"}); err != nil {
+		t.Fatalf("CreateExecution failed: %v", err)
+	}
+
+	outcomes, err := l.GetClassificationOutcomes()
+	if err != nil {
+		t.Fatalf("GetClassificationOutcomes failed: %v", err)
+	}
+	if len(outcomes) != 1 {
+		t.Fatalf("Expected 1 outcome, got %d", len(outcomes))
+	}
+	o := outcomes[0]
+	if o.PredictedTier != 1 || len(o.Patterns) != 1 || o.Patterns[0].Name != "add_field" {
+		t.Errorf("Unexpected prediction: %+v", o)
+	}
+	if o.Attempts != 2 || o.FailedAttempts != 1 || o.Rejections != 1 {
+		t.Errorf("Expected 2 attempts, 1 failed, 1 rejection; got %d, %d, %d", o.Attempts, o.FailedAttempts, o.Rejections)
+	}
+	if o.ForcedTier == nil || *o.ForcedTier != 3 || o.EffectiveTier != nil {
+		t.Errorf("Expected forced tier 3 and no effective tier, got %v, %v", o.ForcedTier, o.EffectiveTier)
+	}
+}
@@ -0,0 +1,172 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// contextLines is how much unchanged text surrounds each hunk
+	contextLines = 3
+
+	// maxDiffCells bounds the line comparison; larger files are diffed as a
+	// whole-file replacement
+	maxDiffCells = 4_000_000
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind     opKind
+	old, new int // 0-based line in each file; the other side is the position before which it falls
+}
+
+// Unified returns a unified diff that turns old into new for the file at
+// path. A nil old creates the file and a nil new deletes it.
+func Unified(path string, old, new *string) string {
+	var a, b []string
+	oldName, newName := "a/"+path, "b/"+path
+	if old != nil {
+		a = splitLines(*old)
+	} else {
+		oldName = "/dev/null"
+	}
+	if new != nil {
+		b = splitLines(*new)
+	} else {
+		newName = "/dev/null"
+	}
+
+	ops := lineDiff(a, b)
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		first := ops[h[0]]
+		oldCount, newCount := 0, 0
+		for _, o := range ops[h[0]:h[1]] {
+			if o.kind != opInsert {
+				oldCount++
+			}
+			if o.kind != opDelete {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(first.old, oldCount), hunkRange(first.new, newCount))
+		for _, o := range ops[h[0]:h[1]] {
+			line := ""
+			switch o.kind {
+			case opInsert:
+				line = b[o.new]
+			default:
+				line = a[o.old]
+			}
+			out.WriteByte(byte(o.kind))
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// hunkRange formats the start and length of one side of a hunk; an empty
+// range names the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// hunks groups changes with their surrounding context, returning the
+// [start, end) of each hunk in ops
+func hunks(ops []op) [][2]int {
+	var groups [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start := max(0, i-contextLines)
+		if n := len(groups); n > 0 && start <= groups[n-1][1] {
+			start = groups[n-1][0]
+			groups = groups[:n-1]
+		}
+		end := i + 1
+		for end < len(ops) && ops[end].kind != opEqual {
+			end++
+		}
+		i = end - 1
+		groups = append(groups, [2]int{start, min(len(ops), end+contextLines)})
+	}
+	return groups
+}
+
+// lineDiff finds a shortest edit turning a into b from their longest common
+// subsequence of lines
+func lineDiff(a, b []string) []op {
+	var ops []op
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, op{opEqual, prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(am)*len(bm) > maxDiffCells {
+		for i := range am {
+			ops = append(ops, op{opDelete, prefix + i, prefix})
+		}
+		for j := range bm {
+			ops = append(ops, op{opInsert, prefix + len(am), prefix + j})
+		}
+	} else {
+		// lcs[i][j] is the length of the common subsequence of am[i:] and bm[j:]
+		lcs := make([][]int, len(am)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(bm)+1)
+		}
+		for i := len(am) - 1; i >= 0; i-- {
+			for j := len(bm) - 1; j >= 0; j-- {
+				if am[i] == bm[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(am) || j < len(bm) {
+			switch {
+			case i < len(am) && j < len(bm) && am[i] == bm[j]:
+				ops = append(ops, op{opEqual, prefix + i, prefix + j})
+				i++
+				j++
+			case j == len(bm) || (i < len(am) && lcs[i+1][j] >= lcs[i][j+1]):
+				ops = append(ops, op{opDelete, prefix + i, prefix + j})
+				i++
+			default:
+				ops = append(ops, op{opInsert, prefix + i, prefix + j})
+				j++
+			}
+		}
+	}
+
+	for k := suffix; k > 0; k-- {
+		ops = append(ops, op{opEqual, len(a) - k, len(b) - k})
+	}
+	return ops
+}
@@ -0,0 +1,177 @@
+package patch
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// lineRange marks a label naming part of a file, such as a context excerpt
+var lineRange = regexp.MustCompile(`:\d+(-\d+)?$`)
+
+// block is a fenced code block in model output
+type block struct {
+	info  string // Text after the opening fence
+	label string // The line before the opening fence
+	body  string
+}
+
+// Extract collects the file changes in model output and returns them as one
+// unified diff against the tree under root, or "" if the output changes
+// nothing. Changes come from unified diffs, fenced or not, and from fenced
+// blocks labelled with a file path, which replace that file whole. Blocks
+// labelled with a line range, and blocks much shorter than the file they
+// name, are taken to be excerpts and skipped. A diff that does not apply
+// cleanly is an error.
+func Extract(root, output string) (string, error) {
+	t := NewTree(root)
+	blocks := fencedBlocks(output)
+
+	diffs := 0
+	for _, b := range blocks {
+		if isDiff(b) {
+			diffs++
+			if err := t.Apply(b.body); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		p := blockPath(b)
+		if p == "" || lineRange.MatchString(p) {
+			continue
+		}
+		if _, err := cleanPath(p); err != nil {
+			continue
+		}
+		current, exists, err := t.Read(p)
+		if err != nil {
+			return "", err
+		}
+		if exists && len(b.body) < len(current)/2 {
+			continue
+		}
+		if err := t.Set(p, b.body); err != nil {
+			return "", err
+		}
+	}
+
+	if diffs == 0 && looksLikeDiff(output) {
+		// A bare diff outside any fence
+		if err := t.Apply(output); err != nil {
+			return "", err
+		}
+	}
+	return t.Diff(), nil
+}
+
+// fencedBlocks finds the fenced code blocks in Markdown text. A block closes
+// at a fence of the same character at least as long as the one opening it.
+func fencedBlocks(text string) []block {
+	lines := strings.SplitAfter(text, "\n")
+	var blocks []block
+	for i := 0; i < len(lines); i++ {
+		fence := fenceOf(lines[i])
+		if fence == "" {
+			continue
+		}
+		b := block{info: strings.TrimSpace(strings.TrimLeft(lines[i], " \t")[len(fence):])}
+		for j := i - 1; j >= 0; j-- {
+			if s := strings.TrimSpace(lines[j]); s != "" {
+				b.label = s
+				break
+			}
+		}
+
+		var body strings.Builder
+		j := i + 1
+		for ; j < len(lines); j++ {
+			if f := fenceOf(lines[j]); f != "" && f[0] == fence[0] && len(f) >= len(fence) &&
+				strings.TrimSpace(strings.TrimLeft(lines[j], " \t")[len(f):]) == "" {
+				break
+			}
+			body.WriteString(terminate(lines[j]))
+		}
+		if j == len(lines) {
+			// Unclosed; treat the rest as prose
+			continue
+		}
+		b.body = body.String()
+		blocks = append(blocks, b)
+		i = j
+	}
+	return blocks
+}
+
+// fenceOf returns the fence a line opens or closes with, if any
+func fenceOf(line string) string {
+	s := strings.TrimLeft(line, " ")
+	if len(line)-len(s) > 3 || len(s) < 3 || (s[0] != '`' && s[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return s[:n]
+}
+
+// isDiff reports whether a block holds a unified diff
+func isDiff(b block) bool {
+	lang, _, _ := strings.Cut(b.info, " ")
+	if lang == "diff" || lang == "patch" {
+		return true
+	}
+	return looksLikeDiff(b.body)
+}
+
+// looksLikeDiff reports whether text contains a file header followed by a hunk
+func looksLikeDiff(text string) bool {
+	minus := strings.Index(text, "--- ")
+	if minus < 0 || (minus > 0 && text[minus-1] != '\n') {
+		return false
+	}
+	rest := text[minus:]
+	return strings.Contains(rest, "\n+++ ") && strings.Contains(rest, "\n@@")
+}
+
+// blockPath finds the file a block is labelled with, either in its info
+// string ("go:main.go", "go title=main.go", "main.go") or on the line before
+// it ("main.go:", "**File: `main.go`**")
+func blockPath(b block) string {
+	for _, field := range strings.Fields(b.info) {
+		if _, value, ok := strings.Cut(field, "="); ok {
+			field = value
+		} else if _, value, ok := strings.Cut(field, ":"); ok && !lineRange.MatchString(field) {
+			field = value
+		}
+		if p := strings.Trim(field, `"'`); isPath(p) {
+			return p
+		}
+	}
+
+	label := strings.Trim(b.label, "#*_` \t")
+	for _, prefix := range []string{"File:", "file:", "Path:", "path:"} {
+		label = strings.TrimPrefix(label, prefix)
+	}
+	label = strings.Trim(label, "*_` \t")
+	label = strings.TrimSuffix(label, ":")
+	label = strings.Trim(label, "*_` \t")
+	if isPath(label) {
+		return label
+	}
+	return ""
+}
+
+// isPath reports whether s looks like a relative file path rather than a
+// language name or prose
+func isPath(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t<>|\"'") || strings.Contains(s, "://") {
+		return false
+	}
+	p := lineRange.ReplaceAllString(s, "")
+	return path.Ext(p) != "" && !strings.HasSuffix(p, ".")
+}
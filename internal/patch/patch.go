@@ -0,0 +1,178 @@
+// Package patch turns the changes described in model output into unified
+// diffs and applies them to a working tree.
+package patch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FileDiff is the change a diff makes to one file
+type FileDiff struct {
+	OldPath string // Empty when the file is created
+	NewPath string // Empty when the file is deleted
+	Hunks   []*Hunk
+}
+
+// Path returns the path of the file the diff changes
+func (f *FileDiff) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// Hunk is a run of changed lines with their context. Lines keep their
+// prefix (' ', '-' or '+') and their line terminator, if any.
+type Hunk struct {
+	OldStart int // 1-based line the hunk starts at in the old file; 0 if unknown
+	Lines    []string
+}
+
+// old returns the lines the hunk expects to find, without prefixes
+func (h *Hunk) old() []string {
+	var lines []string
+	for _, l := range h.Lines {
+		if l[0] != '+' {
+			lines = append(lines, l[1:])
+		}
+	}
+	return lines
+}
+
+// new returns the lines the hunk leaves in place, without prefixes
+func (h *Hunk) new() []string {
+	var lines []string
+	for _, l := range h.Lines {
+		if l[0] != '-' {
+			lines = append(lines, l[1:])
+		}
+	}
+	return lines
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// Parse reads a unified diff. Line counts in hunk headers are not trusted,
+// since models rarely get them right: a hunk runs until the next header or
+// the first line that is not part of a hunk.
+func Parse(diff string) ([]*FileDiff, error) {
+	lines := strings.SplitAfter(diff, "\n")
+	var files []*FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			file = &FileDiff{
+				OldPath: diffPath(line[4:]),
+				NewPath: diffPath(lines[i+1][4:]),
+			}
+			if file.Path() == "" {
+				return nil, fmt.Errorf("diff header without a file path: %q", strings.TrimSpace(line))
+			}
+			files = append(files, file)
+			hunk = nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if file == nil {
+				return nil, fmt.Errorf("hunk before any file header")
+			}
+			hunk = &Hunk{}
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				hunk.OldStart, _ = strconv.Atoi(m[1])
+			}
+			file.Hunks = append(file.Hunks, hunk)
+		case hunk != nil && line != "" && strings.ContainsRune(" -+", rune(line[0])):
+			hunk.Lines = append(hunk.Lines, terminate(line))
+		case hunk != nil && strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the line before
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1] = strings.TrimSuffix(hunk.Lines[n-1], "\n")
+			}
+		case hunk != nil && strings.TrimRight(line, "\r\n") == "" && continuesHunk(lines[i+1:]):
+			// A blank context line that lost its leading space
+			hunk.Lines = append(hunk.Lines, " "+terminate(line))
+		default:
+			hunk = nil
+		}
+	}
+
+	for _, f := range files {
+		if len(f.Hunks) == 0 && f.OldPath != "" && f.NewPath != "" {
+			return nil, fmt.Errorf("%s: no hunks", f.Path())
+		}
+	}
+	return files, nil
+}
+
+// terminate ends a line with a newline; only an explicit "\ No newline"
+// marker leaves a line unterminated
+func terminate(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return line
+	}
+	return line + "\n"
+}
+
+// continuesHunk reports whether more hunk lines follow a run of blank lines
+func continuesHunk(rest []string) bool {
+	for _, l := range rest {
+		if strings.TrimRight(l, "\r\n") == "" {
+			continue
+		}
+		return strings.ContainsRune(" -+", rune(l[0])) && !strings.HasPrefix(l, "--- ") && !strings.HasPrefix(l, "+++ ")
+	}
+	return false
+}
+
+// diffPath extracts the path from a ---/+++ header, dropping the a/ or b/
+// prefix and any timestamp; /dev/null becomes ""
+func diffPath(s string) string {
+	s = strings.TrimRight(s, "\r\n")
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		s = s[2:]
+	}
+	return s
+}
+
+// Stat is the size of the change to one file
+type Stat struct {
+	Path    string
+	Added   int
+	Removed int
+}
+
+// Stats summarizes a unified diff per file
+func Stats(diff string) []Stat {
+	files, err := Parse(diff)
+	if err != nil {
+		return nil
+	}
+	stats := make([]Stat, len(files))
+	for i, f := range files {
+		stats[i].Path = f.Path()
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				switch l[0] {
+				case '+':
+					stats[i].Added++
+				case '-':
+					stats[i].Removed++
+				}
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,175 @@
+package patch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readFile(t *testing.T, root, p string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const mainGo = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello")
+}
+`
+
+func TestParse(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\t2024-01-01\n+++ b/main.go\n@@ -5,3 +5,3 @@ func main() {\n func main() {\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n\n }\n--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+new\n\\ No newline at end of file\n"
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Path() != "main.go" || files[1].OldPath != "" || files[1].Path() != "new.txt" {
+		t.Fatalf("Unexpected files: %+v", files)
+	}
+	h := files[0].Hunks[0]
+	if h.OldStart != 5 || len(h.Lines) != 5 || h.Lines[3] != " \n" {
+		t.Errorf("Expected a blank context line to be kept, got %q", h.Lines)
+	}
+	if got := files[1].Hunks[0].Lines[0]; got != "+new" {
+		t.Errorf("Expected the marker to drop the newline, got %q", got)
+	}
+
+	stats := Stats(diff)
+	if len(stats) != 2 || stats[0].Added != 1 || stats[0].Removed != 1 || stats[1].Added != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	for _, bad := range []string{"@@ -1 +1 @@\n-a\n+b\n", "--- a/x.go\n+++ b/x.go\nnot a hunk\n"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestTreeApply(t *testing.T) {
+	root := writeTree(t, map[string]string{"main.go": mainGo, "old.txt": "gone\n"})
+
+	// Line numbers are off by two and the counts are wrong; the hunk is found by its content
+	tree := NewTree(root)
+	err := tree.Apply("--- a/main.go\n+++ b/main.go\n@@ -7,9 +7,9 @@\n func main() {\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n }\n--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-gone\n--- /dev/null\n+++ b/pkg/new.go\n@@ -0,0 +1,1 @@\n+package pkg\n")
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got := strings.Join(tree.Changed(), ","); got != "main.go,old.txt,pkg/new.go" {
+		t.Errorf("Unexpected changed files: %s", got)
+	}
+	if err := tree.Write(); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := readFile(t, root, "main.go"); got != strings.Replace(mainGo, "hello", "bye", 1) {
+		t.Errorf("Unexpected main.go:\n%s", got)
+	}
+	if got := readFile(t, root, "pkg/new.go"); got != "package pkg\n" {
+		t.Errorf("Unexpected new file: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected old.txt to be deleted")
+	}
+
+	// A hunk whose lines are not in the file is a conflict
+	tree = NewTree(root)
+	err = tree.Apply("--- a/main.go\n+++ b/main.go\n@@ -6 +6 @@\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"again\")\n")
+	if err == nil || !strings.Contains(err.Error(), "main.go: hunk 1 does not match") {
+		t.Errorf("Expected a conflict, got %v", err)
+	}
+
+	for _, p := range []string{"../escape.go", "/etc/passwd", ".git/config", ".bigo/ledger.db"} {
+		if err := NewTree(root).Apply("--- /dev/null\n+++ b/" + p + "\n@@ -0,0 +1 @@\n+x\n"); err == nil {
+			t.Errorf("Expected %s to be refused", p)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	old := strings.Repeat("line\n", 20) + "end"
+	new := "first\n" + strings.Repeat("line\n", 20) + "end\n"
+	diff := Unified("f.txt", &old, &new)
+	want := "--- a/f.txt\n+++ b/f.txt\n@@ -1,3 +1,4 @@\n+first\n line\n line\n line\n@@ -18,4 +19,4 @@\n line\n line\n line\n-end\n\\ No newline at end of file\n+end\n"
+	if diff != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", diff, want)
+	}
+
+	// The diff applies back to the original
+	root := writeTree(t, map[string]string{"f.txt": old})
+	tree := NewTree(root)
+	if err := tree.Apply(diff); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if got, _, _ := tree.Read("f.txt"); got != new {
+		t.Errorf("Round trip gave %q", got)
+	}
+
+	if got := Unified("x", nil, &new); !strings.HasPrefix(got, "--- /dev/null\n+++ b/x\n@@ -0,0 +1,22 @@\n") {
+		t.Errorf("Unexpected creation diff:\n%s", got)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	root := writeTree(t, map[string]string{"main.go": mainGo, "README.md": strings.Repeat("docs\n", 40)})
+
+	t.Run("fenced diff and file block", func(t *testing.T) {
+		output := "Here is the fix:\n\n```diff\n--- a/main.go\n+++ b/main.go\n@@ -6 +6 @@\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"bye\")\n```\n\nAnd a new helper:\n\n**File: `util/util.go`**\n```go\npackage util\n```\n\nUsage:\n```bash\ngo run .\n```\n"
+		diff, err := Extract(root, output)
+		if err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		stats := Stats(diff)
+		if len(stats) != 2 || stats[0].Path != "main.go" || stats[1].Path != "util/util.go" || stats[1].Added != 1 {
+			t.Errorf("Unexpected changes %+v from:\n%s", stats, diff)
+		}
+	})
+
+	t.Run("bare diff", func(t *testing.T) {
+		diff, err := Extract(root, "--- main.go\n+++ main.go\n@@ -1 +1 @@\n-package main\n+package app\n")
+		if err != nil || !strings.Contains(diff, "+package app\n") {
+			t.Errorf("Expected the bare diff, got %q (%v)", diff, err)
+		}
+	})
+
+	t.Run("excerpts are skipped", func(t *testing.T) {
+		output := "```go:main.go:5-7\nfunc main() {}\n```\n\nREADME.md\n```markdown\n# Short\n```\n"
+		diff, err := Extract(root, output)
+		if err != nil || diff != "" {
+			t.Errorf("Expected no changes, got %q (%v)", diff, err)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := Extract(root, "```diff\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-package other\n+package app\n```\n")
+		if err == nil {
+			t.Error("Expected a conflicting diff to fail")
+		}
+	})
+
+	if got := readFile(t, root, "main.go"); got != mainGo {
+		t.Errorf("Extract wrote to the tree:\n%s", got)
+	}
+}
@@ -0,0 +1,264 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// protectedDirs are never written, whatever a patch says
+var protectedDirs = []string{".git", ".bigo"}
+
+// Tree stages changes to the files under a root in memory, so a set of
+// patches can be checked as a whole before anything is written
+type Tree struct {
+	root     string
+	original map[string]*string // Content on disk when first read; nil if missing
+	current  map[string]*string // Staged content; nil if deleted
+}
+
+// NewTree stages changes to the working tree under root
+func NewTree(root string) *Tree {
+	return &Tree{
+		root:     root,
+		original: make(map[string]*string),
+		current:  make(map[string]*string),
+	}
+}
+
+// cleanPath checks that a patch path stays inside the tree and returns it in
+// slash-separated form
+func cleanPath(p string) (string, error) {
+	p = path.Clean(filepath.ToSlash(p))
+	if p == "." || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || filepath.IsAbs(p) {
+		return "", fmt.Errorf("%s is outside the project", p)
+	}
+	for _, dir := range protectedDirs {
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			return "", fmt.Errorf("%s is in a protected directory", p)
+		}
+	}
+	return p, nil
+}
+
+// Read returns the staged content of a file and whether it exists
+func (t *Tree) Read(p string) (string, bool, error) {
+	p, err := cleanPath(p)
+	if err != nil {
+		return "", false, err
+	}
+	if content, ok := t.current[p]; ok {
+		if content == nil {
+			return "", false, nil
+		}
+		return *content, true, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.root, filepath.FromSlash(p)))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		t.original[p] = nil
+		t.current[p] = nil
+		return "", false, nil
+	case err != nil:
+		return "", false, err
+	}
+	content := string(data)
+	t.original[p] = &content
+	t.current[p] = &content
+	return content, true, nil
+}
+
+// Set stages new content for a file, creating it if needed
+func (t *Tree) Set(p, content string) error {
+	if _, _, err := t.Read(p); err != nil {
+		return err
+	}
+	p, _ = cleanPath(p)
+	t.current[p] = &content
+	return nil
+}
+
+// remove stages the deletion of a file
+func (t *Tree) remove(p string) {
+	p, _ = cleanPath(p)
+	t.current[p] = nil
+}
+
+// Apply stages every file change in a unified diff. A hunk is placed where
+// its context and removed lines are found, nearest the line its header
+// names; a hunk that matches nowhere is a conflict and fails the whole diff.
+func (t *Tree) Apply(diff string) error {
+	files, err := Parse(diff)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := t.applyFile(f); err != nil {
+			return fmt.Errorf("%s: %w", f.Path(), err)
+		}
+	}
+	return nil
+}
+
+func (t *Tree) applyFile(f *FileDiff) error {
+	content, exists, err := t.Read(f.Path())
+	if err != nil {
+		return err
+	}
+	if f.OldPath != "" && f.NewPath != "" && f.OldPath != f.NewPath {
+		return fmt.Errorf("renames are not supported")
+	}
+
+	switch {
+	case f.OldPath == "" && exists && content != "":
+		return fmt.Errorf("file already exists")
+	case f.OldPath != "" && !exists:
+		return fmt.Errorf("file does not exist")
+	case f.NewPath == "":
+		t.remove(f.Path())
+		return nil
+	}
+
+	lines := splitLines(content)
+	offset := 0 // Lines added minus removed by earlier hunks
+	for i, h := range f.Hunks {
+		hint := h.OldStart - 1
+		if len(h.old()) == 0 {
+			// A pure addition's header names the line it follows
+			hint = h.OldStart
+		}
+		at, ok := locate(lines, h.old(), hint+offset)
+		if !ok {
+			return fmt.Errorf("hunk %d does not match the current file", i+1)
+		}
+		replacement := h.new()
+		// Keep context lines as they are on disk
+		for j, k := 0, 0; j < len(h.Lines); j++ {
+			switch h.Lines[j][0] {
+			case ' ':
+				replacement[k] = lines[at+oldIndex(h, j)]
+				k++
+			case '+':
+				k++
+			}
+		}
+		old := len(h.old())
+		lines = append(lines[:at], append(replacement, lines[at+old:]...)...)
+		offset += len(replacement) - old
+	}
+
+	for i := 0; i < len(lines)-1; i++ {
+		// A last line without a newline that is no longer last
+		lines[i] = terminate(lines[i])
+	}
+	return t.Set(f.Path(), strings.Join(lines, ""))
+}
+
+// oldIndex returns the position among the hunk's old lines of its jth line
+func oldIndex(h *Hunk, j int) int {
+	n := 0
+	for _, l := range h.Lines[:j] {
+		if l[0] != '+' {
+			n++
+		}
+	}
+	return n
+}
+
+// locate finds where want occurs in lines, trying positions nearest hint
+// first. Lines are compared without trailing whitespace.
+func locate(lines, want []string, hint int) (int, bool) {
+	hint = max(0, min(hint, len(lines)))
+	if len(want) == 0 {
+		return hint, true
+	}
+	for d := 0; d <= len(lines); d++ {
+		for _, at := range []int{hint - d, hint + d} {
+			if at >= 0 && at+len(want) <= len(lines) && matches(lines[at:at+len(want)], want) {
+				return at, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func matches(lines, want []string) bool {
+	for i := range want {
+		if strings.TrimRight(lines[i], " \t\r\n") != strings.TrimRight(want[i], " \t\r\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits content into lines that keep their terminators
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Changed lists the files whose staged content differs from the disk, in path order
+func (t *Tree) Changed() []string {
+	var paths []string
+	for p, content := range t.current {
+		if !sameContent(t.original[p], content) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Diff returns the staged changes as a unified diff against the disk
+func (t *Tree) Diff() string {
+	var b strings.Builder
+	for _, p := range t.Changed() {
+		b.WriteString(Unified(p, t.original[p], t.current[p]))
+	}
+	return b.String()
+}
+
+// Write writes the staged changes to disk
+func (t *Tree) Write() error {
+	for _, p := range t.Changed() {
+		full := filepath.Join(t.root, filepath.FromSlash(p))
+		content := t.current[p]
+		if content == nil {
+			if err := os.Remove(full); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+
+		mode := fs.FileMode(0o644)
+		if info, err := os.Stat(full); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(full, []byte(*content), mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameContent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,96 @@
+// Package pricing prices executions from the tokens they used.
+package pricing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Price is what a model charges, in USD per million tokens
+type Price struct {
+	Input       float64 `yaml:"input"`
+	Output      float64 `yaml:"output"`
+	CachedInput float64 `yaml:"cached_input"`          // Input read from a prompt cache
+	CacheWrite  float64 `yaml:"cache_write,omitempty"` // Input written to a prompt cache; charged as input when zero
+}
+
+// Cost returns the price of the given usage in USD
+func (p Price) Cost(u types.Usage) float64 {
+	cacheWrite := p.CacheWrite
+	if cacheWrite == 0 {
+		cacheWrite = p.Input
+	}
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheReadTokens)*p.CachedInput +
+		float64(u.CacheWriteTokens)*cacheWrite) / 1_000_000
+}
+
+func (p Price) validate() error {
+	if p.Input < 0 || p.Output < 0 || p.CachedInput < 0 || p.CacheWrite < 0 {
+		return fmt.Errorf("prices must not be negative")
+	}
+	return nil
+}
+
+// Table maps model IDs, or prefixes of them, to prices
+type Table map[string]Price
+
+// Defaults returns list prices for the models BigO configures out of the
+// box and their families. Short names such as "sonnet" are the Claude CLI's
+// model aliases.
+func Defaults() Table {
+	return Table{
+		"claude-opus-4-5":       {Input: 5, Output: 25, CachedInput: 0.5, CacheWrite: 6.25},
+		"claude-opus-4":         {Input: 15, Output: 75, CachedInput: 1.5, CacheWrite: 18.75},
+		"claude-sonnet-4":       {Input: 3, Output: 15, CachedInput: 0.3, CacheWrite: 3.75},
+		"claude-haiku-4-5":      {Input: 1, Output: 5, CachedInput: 0.1, CacheWrite: 1.25},
+		"claude-haiku-3-5":      {Input: 0.8, Output: 4, CachedInput: 0.08, CacheWrite: 1},
+		"claude-3-5-haiku":      {Input: 0.8, Output: 4, CachedInput: 0.08, CacheWrite: 1},
+		"opus":                  {Input: 5, Output: 25, CachedInput: 0.5, CacheWrite: 6.25},
+		"sonnet":                {Input: 3, Output: 15, CachedInput: 0.3, CacheWrite: 3.75},
+		"haiku":                 {Input: 1, Output: 5, CachedInput: 0.1, CacheWrite: 1.25},
+		"gemini-1.5-flash":      {Input: 0.075, Output: 0.3, CachedInput: 0.01875},
+		"gemini-1.5-pro":        {Input: 1.25, Output: 5, CachedInput: 0.3125},
+		"gemini-2.0-flash":      {Input: 0.1, Output: 0.4, CachedInput: 0.025},
+		"gemini-2.5-flash":      {Input: 0.3, Output: 2.5, CachedInput: 0.075},
+		"gemini-2.5-pro":        {Input: 1.25, Output: 10, CachedInput: 0.3125},
+		"gemini-2.5-flash-lite": {Input: 0.1, Output: 0.4, CachedInput: 0.025},
+		"gpt-4o":                {Input: 2.5, Output: 10, CachedInput: 1.25},
+		"gpt-4o-mini":           {Input: 0.15, Output: 0.6, CachedInput: 0.075},
+		"gpt-4.1":               {Input: 2, Output: 8, CachedInput: 0.5},
+		"gpt-4.1-mini":          {Input: 0.4, Output: 1.6, CachedInput: 0.1},
+		"gpt-4.1-nano":          {Input: 0.1, Output: 0.4, CachedInput: 0.025},
+	}
+}
+
+// Lookup returns the price of a model: the entry for its exact ID, or else
+// the one for the longest prefix of it, so that "claude-sonnet-4" prices
+// every dated release of the model
+func (t Table) Lookup(model string) (Price, bool) {
+	if p, ok := t[model]; ok {
+		return p, true
+	}
+	best := ""
+	for key := range t {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return t[best], true
+}
+
+// Validate checks that no price is negative
+func (t Table) Validate() error {
+	for model, p := range t {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("%s: %w", model, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+func TestTable_Lookup(t *testing.T) {
+	table := Defaults()
+	tests := []struct {
+		model string
+		want  float64 // Input price
+		ok    bool
+	}{
+		{"claude-sonnet-4-20250514", 3, true},
+		{"claude-opus-4-5-20251101", 5, true}, // Longest prefix wins over claude-opus-4
+		{"claude-opus-4-1-20250805", 15, true},
+		{"sonnet", 3, true},
+		{"gemini-2.5-flash-lite", 0.1, true},
+		{"gemini-2.5-flash-preview-05-20", 0.3, true},
+		{"gpt-4o-2024-08-06", 2.5, true},
+		{"gpt-4o-mini-2024-07-18", 0.15, true},
+		{"qwen3:8b", 0, false},
+	}
+	for _, tt := range tests {
+		p, ok := table.Lookup(tt.model)
+		if ok != tt.ok || p.Input != tt.want {
+			t.Errorf("Lookup(%q) = %v, %v; want input %v, %v", tt.model, p.Input, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrice_Cost(t *testing.T) {
+	p := Price{Input: 3, Output: 15, CachedInput: 0.3, CacheWrite: 3.75}
+	u := types.Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 1_000_000, CacheWriteTokens: 200_000}
+	want := 3 + 1.5 + 0.3 + 0.75
+	if got := p.Cost(u); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	// Without a cache write price, writes are charged as input
+	p.CacheWrite = 0
+	want = 3 + 1.5 + 0.3 + 0.6
+	if got := p.Cost(u); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	if err := (Table{"m": {Input: -1}}).Validate(); err == nil {
+		t.Error("Expected a negative price to be rejected")
+	}
+	if err := Defaults().Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// CharsPerToken is the rough size of a token used to budget context
+	CharsPerToken = 4
+
+	// minPartialTokens is the least budget worth spending on part of a file
+	minPartialTokens = 200
+
+	// fileOverhead is the budget taken by the heading and fence around a file
+	fileOverhead = 16
+)
+
+// Source is a file to pack into a task's context
+type Source struct {
+	Path string // Slash-separated, relative to the root
+	Decl *Decl  // When set, a file too big to pack whole is cut down to the declaration
+}
+
+// PackedFile is a file, or the part of it, packed into a context
+type PackedFile struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Partial   bool // Only lines StartLine to EndLine were packed
+	Content   string
+}
+
+// Label names the file, with its line range when it was cut down
+func (f PackedFile) Label() string {
+	if f.Partial {
+		return fmt.Sprintf("%s:%d-%d", f.Path, f.StartLine, f.EndLine)
+	}
+	return f.Path
+}
+
+// Context is repository content packed into a task's prompt
+type Context struct {
+	Files   []PackedFile
+	Tokens  int
+	Omitted []string // Sources left out for lack of budget
+}
+
+// Labels lists the packed files, with line ranges for partial ones
+func (c *Context) Labels() []string {
+	labels := make([]string, len(c.Files))
+	for i, f := range c.Files {
+		labels[i] = f.Label()
+	}
+	return labels
+}
+
+// Render formats the packed files as fenced Markdown sections
+func (c *Context) Render() string {
+	var b strings.Builder
+	for _, f := range c.Files {
+		fence := "```"
+		for strings.Contains(f.Content, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&b, "### %s\n%s%s\n%s\n%s\n\n", f.Label(), fence, language(f.Path), strings.TrimRight(f.Content, "\n"), fence)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Pack reads sources in order of relevance and packs as many as fit in
+// budget tokens. A file that does not fit whole is cut down to its named
+// declaration, or to its first lines, if enough budget is left for that to
+// be worthwhile. Files that cannot be read are skipped.
+func Pack(root string, sources []Source, budget int) *Context {
+	c := &Context{}
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if seen[src.Path] {
+			continue
+		}
+		seen[src.Path] = true
+
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(src.Path)))
+		if err != nil || isBinary(data) {
+			continue
+		}
+
+		left := budget - c.Tokens - fileOverhead - len(src.Path)/CharsPerToken
+		if left < 1 {
+			c.Omitted = append(c.Omitted, src.Path)
+			continue
+		}
+
+		lines := strings.SplitAfter(string(data), "\n")
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		f := PackedFile{Path: src.Path, StartLine: 1, EndLine: len(lines), Content: string(data)}
+		if len(data)/CharsPerToken > left {
+			if left < minPartialTokens {
+				c.Omitted = append(c.Omitted, src.Path)
+				continue
+			}
+			start, end := 1, len(lines)
+			if src.Decl != nil && src.Decl.EndLine <= len(lines) {
+				start, end = src.Decl.StartLine, src.Decl.EndLine
+			}
+			f = cut(lines, src.Path, start, end, left*CharsPerToken)
+		}
+
+		c.Files = append(c.Files, f)
+		c.Tokens += len(f.Content)/CharsPerToken + fileOverhead + len(src.Path)/CharsPerToken
+	}
+	return c
+}
+
+// cut packs lines start to end of a file, stopping before maxChars
+func cut(lines []string, p string, start, end, maxChars int) PackedFile {
+	var b strings.Builder
+	last := start - 1
+	for i := start; i <= end; i++ {
+		if b.Len()+len(lines[i-1]) > maxChars {
+			break
+		}
+		b.WriteString(lines[i-1])
+		last = i
+	}
+	if last < start {
+		// A single line longer than the budget
+		b.WriteString(strings.ToValidUTF8(lines[start-1][:maxChars], ""))
+		last = start
+	}
+	return PackedFile{Path: p, StartLine: start, EndLine: last, Partial: true, Content: b.String()}
+}
+
+// language returns the Markdown fence language for a file
+func language(p string) string {
+	switch path.Ext(p) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".js", ".jsx", ".mjs":
+		return "javascript"
+	case ".rs":
+		return "rust"
+	case ".sh":
+		return "bash"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".md":
+		return "markdown"
+	case ".sql":
+		return "sql"
+	default:
+		return ""
+	}
+}
+
+// Expand resolves a file, directory or glob relative to the root into the
+// files it covers, in path order. Directories cover the indexed files under
+// them; a file named explicitly is included even if the scan skipped it.
+func (idx *Index) Expand(pattern string) ([]string, error) {
+	full := pattern
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(idx.Root, filepath.FromSlash(pattern))
+	}
+	matches, err := filepath.Glob(full)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, m := range matches {
+		rel, err := filepath.Rel(idx.Root, m)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the project", m)
+		}
+		rel = filepath.ToSlash(rel)
+
+		info, err := os.Stat(m)
+		switch {
+		case err != nil:
+			continue
+		case info.IsDir():
+			prefix := rel + "/"
+			if rel == "." {
+				prefix = ""
+			}
+			for _, f := range idx.Files {
+				if strings.HasPrefix(f.Path, prefix) {
+					add(f.Path)
+				}
+			}
+		case info.Mode().IsRegular():
+			add(rel)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
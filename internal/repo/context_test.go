@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	long := "package big\n\n" + strings.Repeat("// filler line for the budget\n", 200) + "func Target() {\n\treturn\n}\n"
+	root := writeTree(t, map[string]string{
+		"a.go":       "package a\n\nfunc A() {}\n",
+		"big/big.go": long,
+		"b.md":       "uses ``` fences\n",
+	})
+
+	target := &Decl{Name: "Target", StartLine: 203, EndLine: 205}
+	sources := []Source{{Path: "a.go"}, {Path: "missing.go"}, {Path: "big/big.go", Decl: target}, {Path: "a.go"}, {Path: "b.md"}}
+
+	// Everything fits a large budget
+	c := Pack(root, sources, 100000)
+	if got := strings.Join(c.Labels(), ","); got != "a.go,big/big.go,b.md" || len(c.Omitted) != 0 {
+		t.Fatalf("Expected every readable file once, got %s (omitted %v)", got, c.Omitted)
+	}
+	rendered := c.Render()
+	if !strings.Contains(rendered, "### a.go\n```go\npackage a") || !strings.Contains(rendered, "````markdown\nuses ``` fences\n````") {
+		t.Errorf("Unexpected rendering:\n%s", rendered)
+	}
+
+	// A tight budget cuts the big file down to the declaration
+	c = Pack(root, sources, 300)
+	if got := strings.Join(c.Labels(), ","); got != "a.go,big/big.go:203-205,b.md" {
+		t.Errorf("Expected the big file cut to its declaration, got %s", got)
+	}
+	if c.Tokens > 300 {
+		t.Errorf("Packed %d tokens over a budget of 300", c.Tokens)
+	}
+
+	// Too little budget to be worth cutting a file down skips it
+	c = Pack(root, []Source{{Path: "big/big.go"}, {Path: "b.md"}}, 100)
+	if got := strings.Join(c.Labels(), ","); got != "b.md" || len(c.Omitted) != 1 || c.Omitted[0] != "big/big.go" {
+		t.Errorf("Expected big.go to be omitted, got %s (omitted %v)", got, c.Omitted)
+	}
+
+	// Without a declaration the head of the file is kept
+	c = Pack(root, []Source{{Path: "big/big.go"}}, 250)
+	if len(c.Files) != 1 || !c.Files[0].Partial || c.Files[0].StartLine != 1 || !strings.HasPrefix(c.Files[0].Content, "package big") {
+		t.Errorf("Expected the head of big.go, got %+v", c.Labels())
+	}
+
+	if c := Pack(root, sources, 0); len(c.Files) != 0 {
+		t.Errorf("Expected nothing packed without a budget, got %v", c.Labels())
+	}
+}
+
+func TestExpand(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"internal/ledger/ledger.go": "package ledger\n",
+		"internal/ledger/events.go": "package ledger\n",
+		"internal/cli/run.go":       "package cli\n",
+		".env":                      "SECRET=1\n",
+	})
+	idx, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"internal/cli/run.go", "internal/cli/run.go"},
+		{"internal/ledger", "internal/ledger/events.go,internal/ledger/ledger.go"},
+		{"internal/*/r*.go", "internal/cli/run.go"},
+		{".env", ".env"},
+	}
+	for _, tt := range tests {
+		paths, err := idx.Expand(tt.pattern)
+		if err != nil {
+			t.Errorf("Expand(%q) failed: %v", tt.pattern, err)
+			continue
+		}
+		if got := strings.Join(paths, ","); got != tt.want {
+			t.Errorf("Expand(%q) = %s, want %s", tt.pattern, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"nothing/*.go", "../outside", "["} {
+		if _, err := idx.Expand(pattern); err == nil {
+			t.Errorf("Expected Expand(%q) to fail", pattern)
+		}
+	}
+}
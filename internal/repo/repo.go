@@ -0,0 +1,494 @@
+// Package repo indexes a project's working tree so tasks can be related to
+// the files, packages and identifiers they mention.
+package repo
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cammy/bigo/internal/git"
+)
+
+const (
+	// MaxFiles caps how many files a scan indexes
+	MaxFiles = 20000
+
+	// MaxFileSize is the largest file indexed; bigger files are usually generated
+	MaxFileSize = 1 << 20
+)
+
+// skipDirs are directories never indexed, in addition to hidden ones
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// File is an indexed file in the working tree
+type File struct {
+	Path    string // Slash-separated, relative to the root
+	Lines   int
+	Package string // Go package or Python package the file belongs to, if any
+	Decls   []Decl // Top-level declarations, for Go, TypeScript/JavaScript and Python
+}
+
+// Decl is a top-level declaration and the lines it spans
+type Decl struct {
+	Name      string
+	StartLine int
+	EndLine   int
+}
+
+// Lines returns the number of lines the declaration spans
+func (d Decl) Lines() int {
+	return d.EndLine - d.StartLine + 1
+}
+
+// Index is a read-only snapshot of a working tree
+type Index struct {
+	Root      string
+	Files     []*File
+	Truncated bool // The tree had more than MaxFiles files
+
+	decls map[string][]int // Identifier to the files declaring it
+	refs  map[string][]int // Identifier to the files mentioning it
+}
+
+// Scan indexes the working tree under root. In a git repository it indexes
+// the files git tracks or would track, so ignored files are left out.
+// Contents are read once to parse them and again to count references, rather
+// than being held for the whole scan.
+func Scan(root string) (*Index, error) {
+	idx := &Index{
+		Root:  root,
+		decls: make(map[string][]int),
+		refs:  make(map[string][]int),
+	}
+
+	paths, err := listFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range paths {
+		if len(idx.Files) >= MaxFiles {
+			idx.Truncated = true
+			break
+		}
+		data, ok := readFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if !ok {
+			continue
+		}
+
+		f := &File{
+			Path:  rel,
+			Lines: bytes.Count(data, []byte("\n")),
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			f.Lines++
+		}
+		parseSource(f, data)
+		idx.Files = append(idx.Files, f)
+	}
+
+	pythonPackages := make(map[string]bool)
+	for _, f := range idx.Files {
+		if path.Base(f.Path) == "__init__.py" {
+			pythonPackages[path.Dir(f.Path)] = true
+		}
+	}
+	for i, f := range idx.Files {
+		if strings.HasSuffix(f.Path, ".py") && pythonPackages[path.Dir(f.Path)] {
+			f.Package = path.Base(path.Dir(f.Path))
+		}
+		for _, d := range f.Decls {
+			if files := idx.decls[d.Name]; len(files) == 0 || files[len(files)-1] != i {
+				idx.decls[d.Name] = append(files, i)
+			}
+		}
+	}
+
+	for i, f := range idx.Files {
+		if f.Lines == 0 || !isSource(f.Path) {
+			continue
+		}
+		data, ok := readFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if !ok {
+			// Changed since the first pass; its references go uncounted
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, tok := range identifierToken.FindAll(data, -1) {
+			name := string(tok)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if _, ok := idx.decls[name]; ok {
+				idx.refs[name] = append(idx.refs[name], i)
+			}
+		}
+	}
+
+	return idx, nil
+}
+
+// listFiles returns the slash-separated paths, relative to root, of the
+// files to index: those git lists when root is in a repository, else every
+// file found. Hidden directories and skipDirs are left out either way.
+func listFiles(root string) ([]string, error) {
+	if r, err := git.Open(root); err == nil {
+		if files, err := r.Files(root); err == nil {
+			var paths []string
+			for _, p := range files {
+				if !skipped(path.Dir(p)) {
+					paths = append(paths, p)
+				}
+			}
+			return paths, nil
+		}
+	}
+
+	var paths []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are left out rather than failing the scan
+			if d != nil && d.IsDir() && p != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if p != root && skipped(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, p); err == nil {
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// skipped reports whether a directory, given as a slash-separated path,
+// is or lies under a hidden directory or one of skipDirs
+func skipped(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for _, name := range strings.Split(dir, "/") {
+		if strings.HasPrefix(name, ".") || skipDirs[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// readFile returns the contents of a regular file to index, reporting false
+// for one that is missing, too big or binary
+func readFile(p string) ([]byte, bool) {
+	info, err := os.Lstat(p)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > MaxFileSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil || isBinary(data) {
+		return nil, false
+	}
+	return data, true
+}
+
+// DeclaredIn returns the files declaring an identifier
+func (idx *Index) DeclaredIn(name string) []*File {
+	var files []*File
+	for _, i := range idx.decls[name] {
+		files = append(files, idx.Files[i])
+	}
+	return files
+}
+
+// Dependents counts the source files that mention an identifier without declaring it
+func (idx *Index) Dependents(name string) int {
+	declared := make(map[int]bool)
+	for _, i := range idx.decls[name] {
+		declared[i] = true
+	}
+	n := 0
+	for _, i := range idx.refs[name] {
+		if !declared[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// FileDependents counts the other source files that mention any identifier
+// declared only in f. Names declared in several files, like String or init,
+// say nothing about who depends on f and are ignored.
+func (idx *Index) FileDependents(f *File) int {
+	self := -1
+	for i, g := range idx.Files {
+		if g == f {
+			self = i
+			break
+		}
+	}
+
+	dependents := make(map[int]bool)
+	for _, d := range f.Decls {
+		if len(d.Name) < 4 || len(idx.decls[d.Name]) != 1 {
+			continue
+		}
+		for _, i := range idx.refs[d.Name] {
+			if i != self {
+				dependents[i] = true
+			}
+		}
+	}
+	return len(dependents)
+}
+
+// FindPath returns the files a path mentioned in a task refers to: the file
+// itself, else the files whose path ends with it, else the files under a directory
+func (idx *Index) FindPath(p string) []*File {
+	p = strings.Trim(filepath.ToSlash(p), "/")
+	p = strings.TrimPrefix(p, "./")
+	if p == "" {
+		return nil
+	}
+
+	var files []*File
+	for _, f := range idx.Files {
+		if f.Path == p {
+			return []*File{f}
+		}
+		if strings.HasSuffix(f.Path, "/"+p) {
+			files = append(files, f)
+		}
+	}
+	if len(files) > 0 {
+		return files
+	}
+
+	for _, f := range idx.Files {
+		if strings.HasPrefix(f.Path, p+"/") || strings.Contains(f.Path, "/"+p+"/") {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// FindPackage returns the files of the Go or Python packages with the given name
+func (idx *Index) FindPackage(name string) []*File {
+	var files []*File
+	for _, f := range idx.Files {
+		if f.Package == name {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+var identifierToken = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+var (
+	goPackage = regexp.MustCompile(`(?m)^package\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	goDecl    = regexp.MustCompile(`^(?:func\s+(?:\([^)]*\)\s*)?|type\s+|var\s+|const\s+)([A-Za-z_][A-Za-z0-9_]*)`)
+	tsDecl    = regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?(?:async\s+)?(?:function\*?|class|interface|type|enum|const|let|var)\s+([A-Za-z_$][A-Za-z0-9_$]*)`)
+	pyDecl    = regexp.MustCompile(`^(?:async\s+)?(?:def|class)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// parseSource fills in the package and top-level declarations of source files
+func parseSource(f *File, data []byte) {
+	var decl *regexp.Regexp
+	braces := true
+	switch path.Ext(f.Path) {
+	case ".go":
+		decl = goDecl
+		if m := goPackage.FindSubmatch(data); m != nil {
+			f.Package = strings.TrimSuffix(string(m[1]), "_test")
+		}
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		decl = tsDecl
+	case ".py":
+		decl = pyDecl
+		braces = false
+	default:
+		return
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		// Top-level declarations start in the first column
+		m := decl.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		end := indentedBlockEnd(lines, i)
+		if braces {
+			end = bracedBlockEnd(lines, i)
+		}
+		f.Decls = append(f.Decls, Decl{Name: m[1], StartLine: i + 1, EndLine: end})
+	}
+}
+
+// bracedBlockEnd returns the 1-based line closing a block opened on line
+// start, which in formatted code is the next line starting with } or )
+func bracedBlockEnd(lines []string, start int) int {
+	opener := strings.TrimSpace(lines[start])
+	if !strings.HasSuffix(opener, "{") && !strings.HasSuffix(opener, "(") {
+		return start + 1
+	}
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "}") || strings.HasPrefix(lines[i], ")") {
+			return i + 1
+		}
+	}
+	return len(lines)
+}
+
+// indentedBlockEnd returns the 1-based last line of the indented block
+// following line start
+func indentedBlockEnd(lines []string, start int) int {
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			break
+		}
+		end = i
+	}
+	return end + 1
+}
+
+// isSource reports whether declarations and references are tracked for a file
+func isSource(p string) bool {
+	switch path.Ext(p) {
+	case ".go", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".py":
+		return true
+	}
+	return false
+}
+
+// isBinary reports whether data looks like a binary file
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// Mention is a file a task refers to, and how
+type Mention struct {
+	File       *File
+	Via        string // The path, package or identifier that matched
+	Decl       *Decl  // The declaration mentioned, if the task named one
+	Dependents int    // Files depending on the declaration, or on the file
+}
+
+var (
+	pathMention    = regexp.MustCompile(`[\w.-]*[\w-](?:/[\w.-]+)+/?|[\w-][\w.-]*\.[A-Za-z0-9]{1,8}\b`)
+	packageMention = regexp.MustCompile(`(?i)\b(?:package|module)\s+([A-Za-z_]\w*)|\b([A-Za-z_]\w*)\s+(?:package|module)\b`)
+)
+
+// Mentions returns the files a task refers to by path, by Go or Python
+// package, or by an identifier declared in the tree, in the order mentioned
+func (idx *Index) Mentions(text string) []Mention {
+	var mentions []Mention
+	seen := make(map[string]bool)
+	add := func(f *File, via string, d *Decl, dependents int) {
+		key := f.Path + "\x00" + via
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		mentions = append(mentions, Mention{File: f, Via: via, Decl: d, Dependents: dependents})
+	}
+	addDecls := func(name string, files []*File) {
+		dependents := idx.Dependents(name)
+		for _, f := range files {
+			for i := range f.Decls {
+				if f.Decls[i].Name == name {
+					add(f, name, &f.Decls[i], dependents)
+					break
+				}
+			}
+		}
+	}
+
+	for _, p := range pathMention.FindAllString(text, -1) {
+		p = strings.TrimRight(p, ".")
+		if files := idx.FindPath(p); len(files) > 0 {
+			for _, f := range files {
+				add(f, p, nil, idx.FileDependents(f))
+			}
+			continue
+		}
+
+		// A qualified identifier such as ledger.Open
+		if pkg, name, ok := strings.Cut(p, "."); ok && !strings.Contains(name, ".") {
+			var declaring []*File
+			for _, f := range idx.FindPackage(pkg) {
+				for _, d := range f.Decls {
+					if d.Name == name {
+						declaring = append(declaring, f)
+						break
+					}
+				}
+			}
+			addDecls(name, declaring)
+		}
+	}
+
+	for _, m := range packageMention.FindAllStringSubmatch(text, -1) {
+		name := m[1] + m[2]
+		for _, f := range idx.FindPackage(name) {
+			add(f, "package "+name, nil, idx.FileDependents(f))
+		}
+	}
+
+	// Paths and qualified identifiers were resolved above
+	words := pathMention.ReplaceAllString(text, " ")
+	first := true
+	for _, tok := range identifierToken.FindAllString(words, -1) {
+		if looksLikeIdentifier(tok, first) {
+			addDecls(tok, idx.DeclaredIn(tok))
+		}
+		first = false
+	}
+
+	return mentions
+}
+
+// looksLikeIdentifier tells code identifiers like Handler, parseConfig or
+// max_retries apart from ordinary words. A capitalised first word of a
+// sentence-like task is taken for a word.
+func looksLikeIdentifier(tok string, first bool) bool {
+	if len(tok) < 3 {
+		return false
+	}
+	if strings.Contains(tok, "_") {
+		return true
+	}
+	for _, r := range tok[1:] {
+		if r >= 'A' && r <= 'Z' {
+			return true
+		}
+	}
+	return !first && tok[0] >= 'A' && tok[0] <= 'Z'
+}
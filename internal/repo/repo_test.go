@@ -0,0 +1,170 @@
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestScan(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"server/server.go": `package server
+
+// Handler serves requests
+type Handler struct {
+	name string
+}
+
+func (h *Handler) ServeHTTP() {
+	h.name = "x"
+}
+
+func NewHandler() *Handler { return &Handler{} }
+`,
+		"main.go":                 "package main\n\nfunc main() {\n\tserver.NewHandler()\n}\n",
+		"app/util/__init__.py":    "",
+		"app/util/strings.py":     "import os\n\ndef slugify(s):\n    s = s.lower()\n\n    return s\n\nclass Slugger:\n    pass\n",
+		"web/src/api.ts":          "export async function fetchUser(id: string) {\n  return id\n}\n",
+		".git/config":             "[core]\n",
+		"node_modules/x/index.js": "function x() {}\n",
+		"logo.png":                "\x89PNG\x00\x00",
+	})
+
+	idx, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	paths := make(map[string]*File)
+	for _, f := range idx.Files {
+		paths[f.Path] = f
+	}
+	for _, skipped := range []string{".git/config", "node_modules/x/index.js", "logo.png"} {
+		if paths[skipped] != nil {
+			t.Errorf("Expected %s to be skipped", skipped)
+		}
+	}
+
+	server := paths["server/server.go"]
+	if server == nil || server.Package != "server" || server.Lines != 12 {
+		t.Fatalf("Unexpected server.go entry: %+v", server)
+	}
+	want := map[string]int{"Handler": 3, "ServeHTTP": 3, "NewHandler": 1}
+	for _, d := range server.Decls {
+		if n, ok := want[d.Name]; !ok || d.Lines() != n {
+			t.Errorf("Declaration %s spans %d lines, want %d", d.Name, d.Lines(), n)
+		}
+	}
+
+	py := paths["app/util/strings.py"]
+	if py == nil || py.Package != "util" || len(py.Decls) != 2 || py.Decls[0].Lines() != 4 {
+		t.Errorf("Unexpected Python entry: %+v", py)
+	}
+	if ts := paths["web/src/api.ts"]; ts == nil || len(ts.Decls) != 1 || ts.Decls[0].Name != "fetchUser" || ts.Decls[0].Lines() != 3 {
+		t.Errorf("Unexpected TypeScript entry: %+v", ts)
+	}
+
+	if n := idx.Dependents("NewHandler"); n != 1 {
+		t.Errorf("Expected NewHandler to have 1 dependent, got %d", n)
+	}
+	if n := idx.FileDependents(server); n != 1 {
+		t.Errorf("Expected server.go to have 1 dependent, got %d", n)
+	}
+}
+
+func TestScan_GitIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	root := writeTree(t, map[string]string{
+		".gitignore":          "gen/\n",
+		"lib/lib.go":          "package lib\n\nfunc Parse() {}\n",
+		"cmd/main.go":         "package main\n\nfunc main() {\n\tlib.Parse()\n}\n",
+		"gen/parser.go":       "package gen\n\nfunc Generated() {\n\tlib.Parse()\n}\n",
+		"third_party/x/x.go":  "package x\n",
+		"third_party/x/.keep": "",
+	})
+	if out, err := exec.Command("git", "-C", root, "init", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	idx, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	var paths []string
+	for _, f := range idx.Files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, " "); got != ".gitignore cmd/main.go lib/lib.go third_party/x/.keep third_party/x/x.go" {
+		t.Errorf("Expected the ignored directory to be left out, got %q", got)
+	}
+	if n := idx.Dependents("Parse"); n != 1 {
+		t.Errorf("Expected Parse to have 1 dependent outside ignored files, got %d", n)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"internal/ledger/ledger.go": "package ledger\n\nfunc Open(path string) error {\n\treturn nil\n}\n\nfunc ResolveTaskID(id string) string {\n\treturn id\n}\n",
+		"internal/ledger/events.go": "package ledger\n\nconst eventBuffer = 10\n",
+		"internal/cli/run.go":       "package cli\n\nfunc run() {\n\tledger.Open(\"x\")\n\tledger.ResolveTaskID(\"y\")\n}\n",
+		"README.md":                 "# Project\n",
+		"docs/README.md":            "# Docs\n",
+	})
+	idx, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	tests := []struct {
+		text  string
+		files []string
+		via   string
+	}{
+		{"fix typo in README.md", []string{"README.md"}, "README.md"},
+		{"tidy run.go", []string{"internal/cli/run.go"}, "run.go"},
+		{"split the ledger package", []string{"internal/ledger/events.go", "internal/ledger/ledger.go"}, "package ledger"},
+		{"make ledger.Open retry", []string{"internal/ledger/ledger.go"}, "Open"},
+		{"Rename ResolveTaskID to Resolve", []string{"internal/ledger/ledger.go"}, "ResolveTaskID"},
+		{"Open the door and update things", nil, ""},
+	}
+	for _, tt := range tests {
+		mentions := idx.Mentions(tt.text)
+		var got []string
+		for _, m := range mentions {
+			got = append(got, m.File.Path)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.files, ",") {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.text, got, tt.files)
+			continue
+		}
+		if len(mentions) > 0 && mentions[0].Via != tt.via {
+			t.Errorf("Mentions(%q) matched via %q, want %q", tt.text, mentions[0].Via, tt.via)
+		}
+	}
+
+	m := idx.Mentions("Rename ResolveTaskID to Resolve")
+	if m[0].Decl == nil || m[0].Decl.Lines() != 3 || m[0].Dependents != 1 {
+		t.Errorf("Expected a 3-line declaration with 1 dependent, got %+v", m[0])
+	}
+}
@@ -0,0 +1,191 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Format is an output format for a report
+type Format string
+
+const (
+	Table    Format = "table"
+	CSV      Format = "csv"
+	JSON     Format = "json"
+	Markdown Format = "markdown"
+)
+
+// ParseFormat converts a format name, or "md" for Markdown, to a Format
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "table", "csv", "json", "markdown":
+		return Format(s), nil
+	case "md":
+		return Markdown, nil
+	}
+	return "", fmt.Errorf("invalid format %q (use table, csv, json or markdown)", s)
+}
+
+// Write renders the report in the given format. CSV output has one block
+// per section, separated by blank lines, each with its own header row and
+// the section's title in the first column.
+func Write(w io.Writer, r *Report, f Format) error {
+	switch f {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case CSV:
+		return writeCSV(w, r)
+	case Markdown:
+		return writeMarkdown(w, r)
+	case Table, "":
+		return writeTable(w, r)
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+// section is one titled table of a report
+type section struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+func sections(r *Report) []section {
+	label := "Day"
+	if r.Period == Week {
+		label = "Week of"
+	}
+	costs := section{
+		title:  fmt.Sprintf("Cost by %s", r.Period),
+		header: []string{label, "Backend", "Tier", "Runs", "Input tokens", "Output tokens", "Cost (USD)"},
+	}
+	for _, c := range r.Costs {
+		costs.rows = append(costs.rows, []string{c.Start.Format(time.DateOnly), c.Backend, tierName(c.Tier),
+			itoa(c.Executions), itoa(c.InputTokens), itoa(c.OutputTokens), usd(c.CostUSD)})
+	}
+
+	backends := section{
+		title: "Backends",
+		header: []string{"Backend", "Runs", "Failed", "Failure %", "Input tokens", "Output tokens", "Cost (USD)",
+			"Avg ms", "p95 ms"},
+	}
+	for _, b := range r.Backends {
+		backends.rows = append(backends.rows, []string{b.Backend, itoa(b.Executions), itoa(b.Failed), pct(b.FailureRate),
+			itoa(b.InputTokens), itoa(b.OutputTokens), usd(b.CostUSD), itoa(b.AvgLatencyMs), itoa(b.P95LatencyMs)})
+	}
+
+	tiers := section{
+		title:  "Tiers",
+		header: []string{"Tier", "Tasks", "Escalated", "Escalation %", "Attempts", "Failed", "Failure %", "Cost (USD)"},
+	}
+	for _, t := range r.Tiers {
+		tiers.rows = append(tiers.rows, []string{tierName(t.Tier), itoa(t.Tasks), itoa(t.Escalated), pct(t.EscalationRate),
+			itoa(t.Attempts), itoa(t.Failed), pct(t.FailureRate), usd(t.CostUSD)})
+	}
+
+	top := section{
+		title:  "Most expensive tasks",
+		header: []string{"Task", "Tier", "Status", "Runs", "Cost (USD)", "Title"},
+	}
+	for _, t := range r.TopTasks {
+		top.rows = append(top.rows, []string{t.TaskID, tierName(t.Tier), t.Status, itoa(t.Executions), usd(t.CostUSD), t.Title})
+	}
+
+	return []section{costs, backends, tiers, top}
+}
+
+// summary is the one-line description of the report's window and totals
+func summary(r *Report) string {
+	return fmt.Sprintf("%s to %s: %d tasks, %d executions, %d tokens (%d in, %d out), $%.4f",
+		r.From.Local().Format(time.DateOnly), r.To.Local().Format(time.DateOnly), r.Totals.Tasks, r.Totals.Executions,
+		r.Totals.Tokens, r.Totals.InputTokens, r.Totals.OutputTokens, r.Totals.CostUSD)
+}
+
+func writeTable(w io.Writer, r *Report) error {
+	fmt.Fprintln(w, "BigO Report")
+	fmt.Fprintln(w, summary(r))
+	for _, s := range sections(r) {
+		fmt.Fprintln(w, "───────────────────────────────────────")
+		fmt.Fprintf(w, "%s:\n", s.title)
+		if len(s.rows) == 0 {
+			fmt.Fprintln(w, "  (none)")
+			continue
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  "+strings.ToUpper(strings.Join(s.header, "\t")))
+		for _, row := range s.rows {
+			fmt.Fprintln(tw, "  "+strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdown(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "# BigO Report\n\n%s\n", summary(r))
+	for _, s := range sections(r) {
+		fmt.Fprintf(w, "\n## %s\n\n", s.title)
+		if len(s.rows) == 0 {
+			fmt.Fprintln(w, "None.")
+			continue
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(s.header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(s.header)))
+		for _, row := range s.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	for i, s := range sections(r) {
+		if i > 0 {
+			cw.Flush()
+			fmt.Fprintln(w)
+		}
+		if err := cw.Write(append([]string{"Section"}, s.header...)); err != nil {
+			return err
+		}
+		for _, row := range s.rows {
+			if err := cw.Write(append([]string{s.title}, row...)); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func tierName(tier int) string {
+	return fmt.Sprintf("T%d %s", tier, types.Tier(tier))
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+func usd(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func pct(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	r := Build(records(), Options{Period: Week, Top: 5, Location: time.UTC})
+	r.TopTasks[0].Title = "Split | the parser"
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r, JSON); err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON: %v", err)
+	}
+	if decoded.Totals.Executions != 5 || len(decoded.Backends) != 4 || decoded.Backends[0].P95LatencyMs != 2000 {
+		t.Errorf("Unexpected decoded report: %+v", decoded)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, r, CSV); err != nil {
+		t.Fatalf("CSV failed: %v", err)
+	}
+	blocks := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+	if len(blocks) != 4 {
+		t.Fatalf("Expected 4 CSV blocks, got %d:\n%s", len(blocks), buf.String())
+	}
+	rows, err := csv.NewReader(strings.NewReader(blocks[1])).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV: %v", err)
+	}
+	if len(rows) != 5 || rows[0][1] != "Backend" || rows[1][0] != "Backends" || rows[1][1] != "claude:haiku" || rows[1][7] != "0.5000" {
+		t.Errorf("Unexpected backend rows: %v", rows)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, r, Markdown); err != nil {
+		t.Fatalf("Markdown failed: %v", err)
+	}
+	md := buf.String()
+	for _, want := range []string{"## Cost by week", "| Week of | Backend |", "| 2026-03-02 | claude:haiku | T1 SIMPLE | 1 |", `Split \| the parser`} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected Markdown to contain %q:\n%s", want, md)
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, Build(nil, Options{}), Table); err != nil {
+		t.Fatalf("Table failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "0 executions") || !strings.Contains(buf.String(), "(none)") {
+		t.Errorf("Unexpected empty table:\n%s", buf.String())
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an unknown format to fail")
+	}
+	if f, err := ParseFormat("md"); err != nil || f != Markdown {
+		t.Errorf("Expected md to mean Markdown, got %q (%v)", f, err)
+	}
+}
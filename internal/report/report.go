@@ -0,0 +1,290 @@
+// Package report summarises cost, token usage and reliability from the
+// executions recorded in the ledger.
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+)
+
+// Period is the length of the windows costs are grouped into
+type Period string
+
+const (
+	Day  Period = "day"
+	Week Period = "week" // Weeks start on Monday
+)
+
+// ParsePeriod converts "day", "daily", "week" or "weekly" to a Period
+func ParsePeriod(s string) (Period, error) {
+	switch s {
+	case "day", "daily":
+		return Day, nil
+	case "week", "weekly":
+		return Week, nil
+	}
+	return "", fmt.Errorf("invalid period %q (use day or week)", s)
+}
+
+// Options controls how a report is built
+type Options struct {
+	Since    time.Time // Start of the reported window; the first execution when zero
+	Until    time.Time // End of the reported window; the last execution when zero
+	Period   Period
+	Top      int            // How many of the most expensive tasks to list
+	Location *time.Location // Where days start; time.Local when nil
+}
+
+// Report is the summary of a window of executions
+type Report struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Period   Period          `json:"period"`
+	Totals   Totals          `json:"totals"`
+	Costs    []*PeriodCost   `json:"costs"`
+	Backends []*BackendStats `json:"backends"`
+	Tiers    []*TierStats    `json:"tiers"`
+	TopTasks []*TaskCost     `json:"top_tasks"`
+}
+
+// Totals sums every execution in the window
+type Totals struct {
+	Tasks        int     `json:"tasks"`
+	Executions   int     `json:"executions"`
+	Tokens       int     `json:"tokens"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// PeriodCost is what one backend cost for one tier's tasks in one period
+type PeriodCost struct {
+	Start        time.Time `json:"start"`
+	Backend      string    `json:"backend"`
+	Tier         int       `json:"tier"`
+	Executions   int       `json:"executions"`
+	Tokens       int       `json:"tokens"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	CostUSD      float64   `json:"cost_usd"`
+}
+
+// BackendStats describes the usage and reliability of one backend
+type BackendStats struct {
+	Backend      string  `json:"backend"`
+	Executions   int     `json:"executions"`
+	Failed       int     `json:"failed"`
+	FailureRate  float64 `json:"failure_rate"` // Percent of executions that failed
+	Tokens       int     `json:"tokens"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	AvgLatencyMs int     `json:"avg_latency_ms"`
+	P95LatencyMs int     `json:"p95_latency_ms"`
+
+	latencies []int
+}
+
+// TierStats describes how one tier's tasks fared. Attempts count worker
+// executions, leaving out planning, aggregation and classification.
+type TierStats struct {
+	Tier           int     `json:"tier"`
+	Tasks          int     `json:"tasks"` // Tasks with at least one attempt
+	Escalated      int     `json:"escalated"`
+	EscalationRate float64 `json:"escalation_rate"` // Percent of tasks that escalated
+	Attempts       int     `json:"attempts"`
+	Failed         int     `json:"failed"`
+	FailureRate    float64 `json:"failure_rate"` // Percent of attempts that failed
+	CostUSD        float64 `json:"cost_usd"`
+
+	tasks map[string]bool
+}
+
+// TaskCost is the total cost of one task's executions
+type TaskCost struct {
+	TaskID     string  `json:"task_id"`
+	Title      string  `json:"title"`
+	Tier       int     `json:"tier"`
+	Status     string  `json:"status"`
+	Executions int     `json:"executions"`
+	CostUSD    float64 `json:"cost_usd"`
+}
+
+// Build summarises the given executions, which are expected oldest first
+func Build(records []*ledger.ExecutionRecord, opts Options) *Report {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	period := opts.Period
+	if period == "" {
+		period = Week
+	}
+
+	r := &Report{From: opts.Since, To: opts.Until, Period: period}
+	if n := len(records); n > 0 {
+		if r.From.IsZero() {
+			r.From = records[0].CreatedAt
+		}
+		if r.To.IsZero() {
+			r.To = records[n-1].CreatedAt
+		}
+	}
+
+	type costKey struct {
+		start   time.Time
+		backend string
+		tier    int
+	}
+	costs := make(map[costKey]*PeriodCost)
+	backends := make(map[string]*BackendStats)
+	tiers := make(map[int]*TierStats)
+	tasks := make(map[string]*TaskCost)
+
+	for _, e := range records {
+		input, output := e.InputTokens, e.OutputTokens
+		failed := e.Status == "failed"
+
+		r.Totals.Executions++
+		r.Totals.Tokens += e.TokensUsed
+		r.Totals.InputTokens += input
+		r.Totals.OutputTokens += output
+		r.Totals.CostUSD += e.CostUSD
+
+		key := costKey{periodStart(e.CreatedAt.In(loc), period), e.Backend, e.TaskTier}
+		c, ok := costs[key]
+		if !ok {
+			c = &PeriodCost{Start: key.start, Backend: key.backend, Tier: key.tier}
+			costs[key] = c
+		}
+		c.Executions++
+		c.Tokens += e.TokensUsed
+		c.InputTokens += input
+		c.OutputTokens += output
+		c.CostUSD += e.CostUSD
+
+		b, ok := backends[e.Backend]
+		if !ok {
+			b = &BackendStats{Backend: e.Backend}
+			backends[e.Backend] = b
+		}
+		b.Executions++
+		if failed {
+			b.Failed++
+		}
+		b.Tokens += e.TokensUsed
+		b.InputTokens += input
+		b.OutputTokens += output
+		b.CostUSD += e.CostUSD
+		b.latencies = append(b.latencies, e.DurationMs)
+
+		t, ok := tiers[e.TaskTier]
+		if !ok {
+			t = &TierStats{Tier: e.TaskTier, tasks: make(map[string]bool)}
+			tiers[e.TaskTier] = t
+		}
+		t.CostUSD += e.CostUSD
+		if e.Kind == ledger.KindAttempt {
+			t.Attempts++
+			if failed {
+				t.Failed++
+			}
+			if !t.tasks[e.TaskID] {
+				t.tasks[e.TaskID] = true
+				t.Tasks++
+				if e.TaskEscalations > 0 {
+					t.Escalated++
+				}
+			}
+		}
+
+		tc, ok := tasks[e.TaskID]
+		if !ok {
+			tc = &TaskCost{TaskID: e.TaskID, Title: e.TaskTitle, Tier: e.TaskTier, Status: e.TaskStatus}
+			tasks[e.TaskID] = tc
+		}
+		tc.Executions++
+		tc.CostUSD += e.CostUSD
+	}
+	r.Totals.Tasks = len(tasks)
+
+	for _, c := range costs {
+		r.Costs = append(r.Costs, c)
+	}
+	sort.Slice(r.Costs, func(i, j int) bool {
+		a, b := r.Costs[i], r.Costs[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		if a.Backend != b.Backend {
+			return a.Backend < b.Backend
+		}
+		return a.Tier < b.Tier
+	})
+
+	for _, b := range backends {
+		b.FailureRate = percent(b.Failed, b.Executions)
+		b.AvgLatencyMs, b.P95LatencyMs = latency(b.latencies)
+		r.Backends = append(r.Backends, b)
+	}
+	sort.Slice(r.Backends, func(i, j int) bool { return r.Backends[i].Backend < r.Backends[j].Backend })
+
+	for _, t := range tiers {
+		t.EscalationRate = percent(t.Escalated, t.Tasks)
+		t.FailureRate = percent(t.Failed, t.Attempts)
+		r.Tiers = append(r.Tiers, t)
+	}
+	sort.Slice(r.Tiers, func(i, j int) bool { return r.Tiers[i].Tier < r.Tiers[j].Tier })
+
+	for _, tc := range tasks {
+		r.TopTasks = append(r.TopTasks, tc)
+	}
+	sort.Slice(r.TopTasks, func(i, j int) bool {
+		a, b := r.TopTasks[i], r.TopTasks[j]
+		if a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		return a.TaskID < b.TaskID
+	})
+	if len(r.TopTasks) > opts.Top {
+		r.TopTasks = r.TopTasks[:max(opts.Top, 0)]
+	}
+
+	return r
+}
+
+// periodStart returns midnight at the start of the day or week holding t
+func periodStart(t time.Time, p Period) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if p == Week {
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// latency returns the mean and the nearest-rank 95th percentile of the
+// given durations
+func latency(ms []int) (avg, p95 int) {
+	if len(ms) == 0 {
+		return 0, 0
+	}
+	sorted := append([]int(nil), ms...)
+	sort.Ints(sorted)
+	var sum int
+	for _, d := range sorted {
+		sum += d
+	}
+	rank := int(math.Ceil(0.95 * float64(len(sorted))))
+	return sum / len(sorted), sorted[rank-1]
+}
+
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of) * 100
+}
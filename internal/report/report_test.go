@@ -0,0 +1,108 @@
+package report
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cammy/bigo/internal/ledger"
+)
+
+// records returns executions over two weeks: a simple task on Ollama that
+// failed once and escalated to Haiku, a complex task on Sonnet with a
+// planner, and a cheap trivial task
+func records() []*ledger.ExecutionRecord {
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC) }
+	attempt := func(r *ledger.ExecutionRecord) *ledger.ExecutionRecord {
+		if r.Kind == "" {
+			r.Kind = ledger.KindAttempt
+		}
+		return r
+	}
+	simple := func(r *ledger.ExecutionRecord) *ledger.ExecutionRecord {
+		r.TaskID, r.TaskTitle, r.TaskTier, r.TaskStatus, r.TaskEscalations = "simple", "Simple task", 1, "done", 1
+		return attempt(r)
+	}
+	complexTask := func(r *ledger.ExecutionRecord) *ledger.ExecutionRecord {
+		r.TaskID, r.TaskTitle, r.TaskTier, r.TaskStatus = "complex", "Complex task", 3, "done"
+		return attempt(r)
+	}
+	return []*ledger.ExecutionRecord{
+		// Friday
+		simple(&ledger.ExecutionRecord{Backend: "ollama:default", Status: "failed", DurationMs: 100, CreatedAt: day(6, 9)}),
+		simple(&ledger.ExecutionRecord{Backend: "claude:haiku", Status: "completed", TokensUsed: 120, InputTokens: 100, OutputTokens: 20, CostUSD: 0.5, DurationMs: 2000, CreatedAt: day(6, 10)}),
+		// Monday
+		complexTask(&ledger.ExecutionRecord{Kind: ledger.KindPlanner, Backend: "claude:opus", Status: "completed", CostUSD: 1.5, DurationMs: 9000, CreatedAt: day(9, 9)}),
+		complexTask(&ledger.ExecutionRecord{Backend: "claude:sonnet", Status: "completed", CostUSD: 1, DurationMs: 4000, CreatedAt: day(9, 10)}),
+		attempt(&ledger.ExecutionRecord{TaskID: "trivial", TaskTitle: "Trivial task", Backend: "ollama:default", Status: "completed", DurationMs: 300, CreatedAt: day(10, 9)}),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := Build(records(), Options{Period: Week, Top: 2, Location: time.UTC})
+
+	if r.Totals.Tasks != 3 || r.Totals.Executions != 5 || r.Totals.InputTokens != 100 || math.Abs(r.Totals.CostUSD-3) > 1e-9 {
+		t.Errorf("Unexpected totals: %+v", r.Totals)
+	}
+	if !r.From.Equal(records()[0].CreatedAt) || !r.To.Equal(records()[4].CreatedAt) {
+		t.Errorf("Expected the window to span the executions, got %s to %s", r.From, r.To)
+	}
+
+	// Weeks start on Monday
+	if len(r.Costs) != 5 {
+		t.Fatalf("Expected 5 cost rows, got %d", len(r.Costs))
+	}
+	if first := r.Costs[0]; first.Start.Format(time.DateOnly) != "2026-03-02" || first.Backend != "claude:haiku" {
+		t.Errorf("Unexpected first cost row: %+v", first)
+	}
+	if last := r.Costs[4]; last.Start.Format(time.DateOnly) != "2026-03-09" || last.Backend != "ollama:default" || last.Tier != 0 {
+		t.Errorf("Unexpected last cost row: %+v", last)
+	}
+
+	var ollama *BackendStats
+	for _, b := range r.Backends {
+		if b.Backend == "ollama:default" {
+			ollama = b
+		}
+	}
+	if ollama == nil || ollama.Executions != 2 || ollama.Failed != 1 || ollama.FailureRate != 50 ||
+		ollama.AvgLatencyMs != 200 || ollama.P95LatencyMs != 300 {
+		t.Errorf("Unexpected Ollama stats: %+v", ollama)
+	}
+
+	// The planner is not an attempt
+	if len(r.Tiers) != 3 {
+		t.Fatalf("Expected 3 tiers, got %d", len(r.Tiers))
+	}
+	if s := r.Tiers[1]; s.Tier != 1 || s.Tasks != 1 || s.Escalated != 1 || s.EscalationRate != 100 || s.Attempts != 2 || s.FailureRate != 50 {
+		t.Errorf("Unexpected simple tier stats: %+v", s)
+	}
+	if c := r.Tiers[2]; c.Tier != 3 || c.Attempts != 1 || c.CostUSD != 2.5 {
+		t.Errorf("Unexpected complex tier stats: %+v", c)
+	}
+
+	if len(r.TopTasks) != 2 || r.TopTasks[0].TaskID != "complex" || r.TopTasks[0].Executions != 2 || r.TopTasks[1].TaskID != "simple" {
+		t.Errorf("Unexpected top tasks: %+v", r.TopTasks)
+	}
+
+	daily := Build(records(), Options{Period: Day, Location: time.UTC})
+	if len(daily.Costs) != 5 || daily.Costs[0].Start.Format(time.DateOnly) != "2026-03-06" || len(daily.TopTasks) != 0 {
+		t.Errorf("Unexpected daily report: %+v", daily)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	var ms []int
+	for i := 1; i <= 100; i++ {
+		ms = append(ms, 101-i)
+	}
+	if avg, p95 := latency(ms); avg != 50 || p95 != 95 {
+		t.Errorf("Expected 50 and 95, got %d and %d", avg, p95)
+	}
+	if avg, p95 := latency([]int{7}); avg != 7 || p95 != 7 {
+		t.Errorf("Expected a single sample to be its own p95, got %d and %d", avg, p95)
+	}
+	if avg, p95 := latency(nil); avg != 0 || p95 != 0 {
+		t.Errorf("Expected zeros without samples, got %d and %d", avg, p95)
+	}
+}
@@ -0,0 +1,252 @@
+package validators
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cammy/bigo/internal/git"
+	"github.com/cammy/bigo/internal/patch"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+const (
+	// CommandValidatorID names command validators in the ledger
+	CommandValidatorID = "tests"
+
+	// CommandBackend stands in for a backend on command validator results
+	CommandBackend types.Backend = "command"
+
+	// maxLocatedFindings caps the file:line findings taken from a failing run
+	maxLocatedFindings = 20
+
+	// outputTailLines is how much of a failing run's output is reported
+	outputTailLines = 40
+)
+
+// copySkipDirs are never copied into the scratch tree
+var copySkipDirs = map[string]bool{".git": true, ".bigo": true}
+
+// CommandValidator applies a candidate diff to a scratch copy of the working
+// tree and runs a command there, such as the project's test suite. The
+// command passing is an approval; its failures become findings.
+type CommandValidator struct {
+	root    string
+	command string
+	timeout time.Duration
+}
+
+// NewCommandValidator creates a validator that runs command, through the
+// shell, against changes to the tree under root
+func NewCommandValidator(root, command string, timeout time.Duration) *CommandValidator {
+	return &CommandValidator{root: root, command: command, timeout: timeout}
+}
+
+// Validate runs the command with the diff applied
+func (v *CommandValidator) Validate(ctx context.Context, executionID, diff string) *types.ValidationResult {
+	result := &types.ValidationResult{
+		ExecutionID: executionID,
+		ValidatorID: CommandValidatorID,
+		Backend:     CommandBackend,
+	}
+	fail := func(format string, args ...interface{}) *types.ValidationResult {
+		result.Findings = append(result.Findings, types.Finding{
+			Severity: "error",
+			Location: "general",
+			Message:  fmt.Sprintf(format, args...),
+		})
+		return result
+	}
+
+	scratch, err := os.MkdirTemp("", "bigo-validate-*")
+	if err != nil {
+		return fail("failed to create scratch tree: %v", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := copyTree(v.root, scratch); err != nil {
+		return fail("failed to copy the working tree: %v", err)
+	}
+	tree := patch.NewTree(scratch)
+	if err := tree.Apply(diff); err != nil {
+		return fail("change does not apply: %v", err)
+	}
+	if err := tree.Write(); err != nil {
+		return fail("failed to write change: %v", err)
+	}
+
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	// #nosec G204 -- the command comes from the project's own config
+	cmd := exec.CommandContext(ctx, "sh", "-c", v.command)
+	cmd.Dir = scratch
+	cmd.WaitDelay = 5 * time.Second // Don't wait forever on children holding the output open
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+
+	switch {
+	case err == nil:
+		result.Approved = true
+		return result
+	case ctx.Err() != nil:
+		return fail("`%s` did not finish within %s:\n%s", v.command, v.timeout, tail(out.String(), outputTailLines))
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fail("failed to run `%s`: %v", v.command, err)
+	}
+	result.Findings = append(ParseFailures(out.String(), maxLocatedFindings),
+		types.Finding{
+			Severity: "error",
+			Location: "general",
+			Message:  fmt.Sprintf("`%s` exited with status %d:\n%s", v.command, exitErr.ExitCode(), tail(out.String(), outputTailLines)),
+		})
+	return result
+}
+
+// failureLine matches compiler and test output that names a source
+// location, such as "./main.go:12:5: undefined: x" or
+// "    parse_test.go:40: got 3, want 4"
+var failureLine = regexp.MustCompile(`^\s*([\w./\\-]+\.\w+):(\d+)(?::\d+)?:\s+(.+)$`)
+
+// ParseFailures extracts findings with file:line locations from command
+// output, keeping at most limit of them
+func ParseFailures(output string, limit int) []types.Finding {
+	var findings []types.Finding
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		m := failureLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		location := strings.TrimPrefix(m[1], "./") + ":" + m[2]
+		key := location + " " + m[3]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		findings = append(findings, types.Finding{
+			Severity: "error",
+			Location: location,
+			Message:  strings.TrimSpace(m[3]),
+		})
+		if len(findings) == limit {
+			break
+		}
+	}
+	return findings
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d lines omitted)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// copyTree copies the files under src into dst, keeping modes and
+// symlinks, and skipping version control and BigO's own state. In a git
+// repository only the files git tracks or would track are copied, leaving out
+// ignored dependencies and build output, with their uncommitted changes.
+func copyTree(src, dst string) error {
+	if r, err := git.Open(src); err == nil {
+		if files, err := r.Files(src); err == nil {
+			for _, rel := range files {
+				if copySkipped(rel) {
+					continue
+				}
+				target := filepath.Join(dst, filepath.FromSlash(rel))
+				if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+					return err
+				}
+				if err := copyFile(filepath.Join(src, filepath.FromSlash(rel)), target); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			if path != src && copySkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copySkipped reports whether a slash-separated path lies in one of copySkipDirs
+func copySkipped(rel string) bool {
+	for _, name := range strings.Split(path.Dir(rel), "/") {
+		if copySkipDirs[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFile copies a regular file or symlink, ignoring anything else and
+// files deleted from the working tree
+func copyFile(path, target string) error {
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, target)
+	case !info.Mode().IsRegular():
+		return nil
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,162 @@
+package validators
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+const commandDiff = `--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-one
++two
+`
+
+func TestCommandValidator(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// The command sees the change; the working tree does not
+	v := NewCommandValidator(root, `grep -q two a.txt && test ! -e .git`, time.Minute)
+	r := v.Validate(ctx, "exec-1", commandDiff)
+	if !r.Approved {
+		t.Fatalf("Expected approval, got findings %+v", r.Findings)
+	}
+	if r.ExecutionID != "exec-1" || r.ValidatorID != CommandValidatorID || r.Backend != CommandBackend {
+		t.Errorf("Unexpected result identity: %+v", r)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(data) != "one\n" {
+		t.Errorf("Expected the working tree untouched, got %q", data)
+	}
+
+	// Failures become located findings plus the tail of the output
+	v = NewCommandValidator(root, `echo "./a_test.go:3:5: boom"; echo done; exit 2`, time.Minute)
+	r = v.Validate(ctx, "exec-2", commandDiff)
+	if r.Approved {
+		t.Fatal("Expected a failing command to reject")
+	}
+	if len(r.Findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", r.Findings)
+	}
+	if f := r.Findings[0]; f.Location != "a_test.go:3" || f.Message != "boom" {
+		t.Errorf("Unexpected located finding: %+v", f)
+	}
+	if f := r.Findings[1]; f.Location != "general" || !strings.Contains(f.Message, "status 2") || !strings.Contains(f.Message, "done") {
+		t.Errorf("Unexpected general finding: %+v", f)
+	}
+
+	// A diff that does not apply is rejected without running anything
+	v = NewCommandValidator(root, `true`, time.Minute)
+	r = v.Validate(ctx, "exec-3", strings.Replace(commandDiff, "-one", "-three", 1))
+	if r.Approved || len(r.Findings) != 1 || !strings.Contains(r.Findings[0].Message, "does not apply") {
+		t.Errorf("Expected a rejection for a conflicting diff, got %+v", r)
+	}
+
+	// A command that runs too long is stopped
+	v = NewCommandValidator(root, `exec sleep 10`, 100*time.Millisecond)
+	start := time.Now()
+	r = v.Validate(ctx, "exec-4", commandDiff)
+	if r.Approved || len(r.Findings) != 1 || !strings.Contains(r.Findings[0].Message, "did not finish") {
+		t.Errorf("Expected a timeout rejection, got %+v", r)
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Errorf("Expected the command to be stopped promptly, took %s", time.Since(start))
+	}
+}
+
+func TestCommandValidator_GitTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"a.txt":                 "one\n",
+		"b.txt":                 "untracked\n",
+		".gitignore":            "node_modules/\nbuild/\n",
+		"node_modules/dep/x.js": "ignored\n",
+		"build/out.bin":         "ignored\n",
+		"src/nested/keep.txt":   "kept\n",
+		".bigo/ledger.db":       "state\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out, err := exec.Command("git", "-C", root, "init", "--quiet").CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	// Only files git would track are copied, uncommitted ones included
+	v := NewCommandValidator(root, `grep -q two a.txt && test -e b.txt && test -e src/nested/keep.txt && `+
+		`test ! -e node_modules && test ! -e build && test ! -e .bigo && test ! -e .git`, time.Minute)
+	if r := v.Validate(context.Background(), "exec-1", commandDiff); !r.Approved {
+		t.Errorf("Expected approval, got findings %+v", r.Findings)
+	}
+}
+
+func TestParseFailures(t *testing.T) {
+	output := `# example.com/pkg
+./main.go:12:5: undefined: x
+--- FAIL: TestAdd (0.00s)
+    add_test.go:40: got 3, want 4
+    add_test.go:40: got 3, want 4
+FAIL	example.com/pkg	0.01s
+src/util.py:7: error: missing return
+`
+	findings := ParseFailures(output, 10)
+	want := []types.Finding{
+		{Severity: "error", Location: "main.go:12", Message: "undefined: x"},
+		{Severity: "error", Location: "add_test.go:40", Message: "got 3, want 4"},
+		{Severity: "error", Location: "src/util.py:7", Message: "error: missing return"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), findings)
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("Finding %d: expected %+v, got %+v", i, want[i], findings[i])
+		}
+	}
+
+	if got := ParseFailures(output, 1); len(got) != 1 {
+		t.Errorf("Expected the limit to hold, got %d findings", len(got))
+	}
+}
+
+func TestVerdict_Veto(t *testing.T) {
+	panel := []*types.ValidationResult{{Approved: true}, {Approved: true}}
+
+	verdict := Tally(panel, 2)
+	verdict.Veto(&types.ValidationResult{Approved: true})
+	if !verdict.Approved || verdict.Vetoed {
+		t.Errorf("Expected a passing veto to leave the verdict approved, got %+v", verdict)
+	}
+
+	verdict = Tally(panel, 2)
+	verdict.Veto(&types.ValidationResult{Findings: []types.Finding{{Severity: "error", Location: "general", Message: "tests failed"}}})
+	if verdict.Approved || !verdict.Vetoed {
+		t.Errorf("Expected a failing veto to reject, got %+v", verdict)
+	}
+	if verdict.Approvals != 2 || len(verdict.Results) != 3 || len(verdict.Findings()) != 1 {
+		t.Errorf("Unexpected verdict: %+v", verdict)
+	}
+}
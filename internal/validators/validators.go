@@ -0,0 +1,267 @@
+package validators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// Reviewer is the subset of a worker needed to run a validation
+type Reviewer interface {
+	Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error)
+	Backend() types.Backend
+}
+
+// Validator reviews execution output without knowing which backend produced it
+type Validator struct {
+	id       string
+	reviewer Reviewer
+	timeout  time.Duration
+}
+
+// NewValidator creates a validator backed by the given reviewer
+func NewValidator(id string, reviewer Reviewer, timeout time.Duration) *Validator {
+	return &Validator{
+		id:       id,
+		reviewer: reviewer,
+		timeout:  timeout,
+	}
+}
+
+// ID returns the validator's identifier
+func (v *Validator) ID() string {
+	return v.id
+}
+
+// Validate asks the reviewer for a verdict on the output of an execution
+func (v *Validator) Validate(ctx context.Context, task *types.Task, executionID, output string) *types.ValidationResult {
+	result := &types.ValidationResult{
+		ExecutionID: executionID,
+		ValidatorID: v.id,
+		Backend:     v.reviewer.Backend(),
+	}
+
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	review := ReviewTask(task, output)
+	review.Backend = v.reviewer.Backend()
+
+	execResult, err := v.reviewer.Execute(ctx, review)
+	result.Review = execResult
+	if err == nil && !execResult.Success {
+		err = fmt.Errorf("%s", execResult.Error)
+	}
+	if err != nil {
+		result.Findings = []types.Finding{{
+			Severity: "error",
+			Location: "general",
+			Message:  fmt.Sprintf("validator failed to produce a verdict: %v", err),
+		}}
+		return result
+	}
+
+	approved, findings, err := ParseVerdict(execResult.Output)
+	if err != nil {
+		result.Findings = []types.Finding{{
+			Severity: "error",
+			Location: "general",
+			Message:  fmt.Sprintf("validator returned an unparseable verdict: %v", err),
+		}}
+		return result
+	}
+
+	result.Approved = approved
+	result.Findings = findings
+	return result
+}
+
+// ReviewTask returns the request a validator sends its reviewer to judge
+// the output of a task
+func ReviewTask(task *types.Task, output string) *types.Task {
+	return &types.Task{
+		ID:          task.ID,
+		Title:       "Review the proposed solution below and return a JSON verdict",
+		Description: buildReviewPrompt(task, output),
+		Tier:        task.Tier,
+	}
+}
+
+// Panel fans a single execution out to several validators in parallel
+type Panel struct {
+	validators []*Validator
+}
+
+// NewPanel creates count validators that all review through the same reviewer
+func NewPanel(reviewer Reviewer, count int, timeout time.Duration) *Panel {
+	p := &Panel{}
+	for i := 1; i <= count; i++ {
+		p.validators = append(p.validators, NewValidator(fmt.Sprintf("validator-%d", i), reviewer, timeout))
+	}
+	return p
+}
+
+// Review runs every validator on the output and returns their results in order
+func (p *Panel) Review(ctx context.Context, task *types.Task, executionID, output string) []*types.ValidationResult {
+	results := make([]*types.ValidationResult, len(p.validators))
+
+	var wg sync.WaitGroup
+	for i, v := range p.validators {
+		wg.Add(1)
+		go func(i int, v *Validator) {
+			defer wg.Done()
+			results[i] = v.Validate(ctx, task, executionID, output)
+		}(i, v)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Verdict is the combined outcome of a validation panel
+type Verdict struct {
+	Results   []*types.ValidationResult
+	Approvals int
+	Required  int
+	Approved  bool
+	Vetoed    bool // A veto result rejected the verdict
+}
+
+// Tally counts approvals and decides whether the required number was reached
+func Tally(results []*types.ValidationResult, required int) *Verdict {
+	v := &Verdict{
+		Results:  results,
+		Required: required,
+	}
+	for _, r := range results {
+		if r.Approved {
+			v.Approvals++
+		}
+	}
+	v.Approved = v.Approvals >= required
+	return v
+}
+
+// Veto adds a result that cannot approve on its own but rejects the whole
+// verdict when it does not approve
+func (v *Verdict) Veto(r *types.ValidationResult) {
+	v.Results = append(v.Results, r)
+	if !r.Approved {
+		v.Approved = false
+		v.Vetoed = true
+	}
+}
+
+// Findings returns every finding reported by the panel
+func (v *Verdict) Findings() []types.Finding {
+	var findings []types.Finding
+	for _, r := range v.Results {
+		findings = append(findings, r.Findings...)
+	}
+	return findings
+}
+
+// verdictResponse is the JSON shape validators are asked to return
+type verdictResponse struct {
+	Approved *bool `json:"approved"`
+	Findings []struct {
+		Severity   string `json:"severity"`
+		Location   string `json:"location"`
+		Message    string `json:"message"`
+		Suggestion string `json:"suggestion"`
+	} `json:"findings"`
+}
+
+var thinkBlock = regexp.MustCompile(`(?s)<think>.*?</think>`)
+
+// ExtractJSONObject returns the JSON object in a model response: the text
+// from its first "{" to its last "}", once any <think> blocks in which
+// reasoning models wrap their chain of thought are removed
+func ExtractJSONObject(output string) (string, error) {
+	text := thinkBlock.ReplaceAllString(output, "")
+
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return "", fmt.Errorf("no JSON object in response")
+	}
+	return text[start : end+1], nil
+}
+
+// ParseVerdict extracts the approval decision and findings from a validator response
+func ParseVerdict(output string) (bool, []types.Finding, error) {
+	text, err := ExtractJSONObject(output)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var resp verdictResponse
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return false, nil, fmt.Errorf("invalid verdict JSON: %w", err)
+	}
+	if resp.Approved == nil {
+		return false, nil, fmt.Errorf("verdict is missing the approved field")
+	}
+
+	findings := make([]types.Finding, 0, len(resp.Findings))
+	for _, f := range resp.Findings {
+		severity := strings.ToLower(f.Severity)
+		switch severity {
+		case "error", "warning", "info":
+		default:
+			severity = "info"
+		}
+		location := f.Location
+		if location == "" {
+			location = "general"
+		}
+		findings = append(findings, types.Finding{
+			Severity:   severity,
+			Location:   location,
+			Message:    f.Message,
+			Suggestion: f.Suggestion,
+		})
+	}
+
+	return *resp.Approved, findings, nil
+}
+
+func buildReviewPrompt(task *types.Task, output string) string {
+	prompt := fmt.Sprintf(`You are reviewing a proposed solution to a software engineering task.
+You do not know who wrote it. Judge it only on its merits.
+
+## Task
+%s
+
+`, task.Title)
+
+	if task.Description != "" {
+		prompt += fmt.Sprintf(`## Details
+%s
+
+`, task.Description)
+	}
+
+	prompt += fmt.Sprintf(`## Proposed Solution
+%s
+
+## Instructions
+- Check the solution for correctness, completeness and safety
+- Approve only if it fully and correctly completes the task
+- Reject if you report any finding with severity "error"
+- Respond with a single JSON object and nothing else, in this format:
+
+{"approved": true, "findings": [{"severity": "error|warning|info", "location": "file:line or general", "message": "what is wrong", "suggestion": "how to fix it"}]}
+`, output)
+
+	return prompt
+}
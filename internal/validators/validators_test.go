@@ -0,0 +1,141 @@
+package validators
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// mockReviewer implements Reviewer
+type mockReviewer struct {
+	backend     types.Backend
+	ExecuteFunc func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error)
+}
+
+func (m *mockReviewer) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+	return m.ExecuteFunc(ctx, task)
+}
+
+func (m *mockReviewer) Backend() types.Backend {
+	return m.backend
+}
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		wantApproved bool
+		wantFindings int
+		wantErr      bool
+	}{
+		{
+			name:         "Plain JSON approval",
+			output:       `{"approved": true, "findings": []}`,
+			wantApproved: true,
+		},
+		{
+			name:         "Fenced JSON rejection",
+			output:       "Here is my review:\n```json\n{\"approved\": false, \"findings\": [{\"severity\": \"error\", \"location\": \"main.go:12\", \"message\": \"nil dereference\"}]}\n```",
+			wantApproved: false,
+			wantFindings: 1,
+		},
+		{
+			name:         "Think block is ignored",
+			output:       "<think>maybe {approved}</think>{\"approved\": true}",
+			wantApproved: true,
+		},
+		{
+			name:    "No JSON",
+			output:  "Looks good to me!",
+			wantErr: true,
+		},
+		{
+			name:    "Missing approved field",
+			output:  `{"findings": []}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approved, findings, err := ParseVerdict(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if approved != tt.wantApproved {
+				t.Errorf("Expected approved=%v, got %v", tt.wantApproved, approved)
+			}
+			if len(findings) != tt.wantFindings {
+				t.Errorf("Expected %d findings, got %d", tt.wantFindings, len(findings))
+			}
+		})
+	}
+}
+
+func TestPanel_Review(t *testing.T) {
+	var calls int32
+	reviewer := &mockReviewer{
+		backend: types.BackendClaudeSonnet,
+		ExecuteFunc: func(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+			n := atomic.AddInt32(&calls, 1)
+
+			// Reviews must be blind: the prompt never names the producing backend
+			if strings.Contains(task.Description, string(types.BackendOllama)) {
+				t.Errorf("Review prompt leaked the producing backend")
+			}
+			if !strings.Contains(task.Description, "func Add") {
+				t.Errorf("Review prompt does not contain the output under review")
+			}
+
+			switch n {
+			case 1:
+				return nil, errors.New("connection refused")
+			case 2:
+				return &types.ExecutionResult{Success: true, Output: `{"approved": false, "findings": [{"severity": "warning", "message": "no tests"}]}`}, nil
+			default:
+				return &types.ExecutionResult{Success: true, Output: `{"approved": true}`}, nil
+			}
+		},
+	}
+
+	task := &types.Task{ID: "task-1", Title: "Add function", Backend: types.BackendOllama}
+	panel := NewPanel(reviewer, 4, 0)
+	results := panel.Review(context.Background(), task, "exec-1", "func Add(a, b int) int { return a + b }")
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.ExecutionID != "exec-1" {
+			t.Errorf("Result %d: expected execution exec-1, got %s", i, r.ExecutionID)
+		}
+		if r.Backend != types.BackendClaudeSonnet {
+			t.Errorf("Result %d: expected backend %s, got %s", i, types.BackendClaudeSonnet, r.Backend)
+		}
+	}
+
+	verdict := Tally(results, 2)
+	if verdict.Approvals != 2 {
+		t.Errorf("Expected 2 approvals, got %d", verdict.Approvals)
+	}
+	if !verdict.Approved {
+		t.Error("Expected verdict to be approved")
+	}
+	if len(verdict.Findings()) != 2 {
+		t.Errorf("Expected 2 findings, got %d", len(verdict.Findings()))
+	}
+
+	if Tally(results, 3).Approved {
+		t.Error("Expected verdict requiring 3 approvals to be rejected")
+	}
+}
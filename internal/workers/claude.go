@@ -1,23 +1,30 @@
 package workers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/cammy/bigo/internal/pricing"
 	"github.com/cammy/bigo/pkg/types"
 )
 
 // ClaudeWorker executes tasks using Claude Code CLI
 type ClaudeWorker struct {
-	id        string
-	model     string
-	backend   types.Backend
-	available bool
-	cliPath   string
-	timeout   time.Duration
+	id      string
+	model   string
+	backend types.Backend
+	busy    atomic.Bool
+	cliPath string
+	timeout time.Duration
+	price   pricing.Price
+	priced  bool // Whether the pricing table has the model
 }
 
 // ClaudeConfig holds configuration for creating a Claude worker
@@ -26,6 +33,7 @@ type ClaudeConfig struct {
 	Backend types.Backend
 	CLIPath string
 	Timeout time.Duration
+	Pricing pricing.Table // Prices by model ID; the defaults when nil
 }
 
 // NewClaudeWorker creates a new Claude worker
@@ -40,20 +48,28 @@ func NewClaudeWorker(id string, cfg ClaudeConfig) *ClaudeWorker {
 		timeout = 10 * time.Minute
 	}
 
+	table := cfg.Pricing
+	if table == nil {
+		table = pricing.Defaults()
+	}
+	price, priced := table.Lookup(cfg.Model)
+
 	return &ClaudeWorker{
-		id:        id,
-		model:     cfg.Model,
-		backend:   cfg.Backend,
-		cliPath:   cliPath,
-		timeout:   timeout,
-		available: true,
+		id:      id,
+		model:   cfg.Model,
+		backend: cfg.Backend,
+		cliPath: cliPath,
+		timeout: timeout,
+		price:   price,
+		priced:  priced,
 	}
 }
 
-// Execute runs a task using Claude Code CLI
+// Execute runs a task using Claude Code CLI, reading the answer, its token
+// usage and its cost from the CLI's JSON output
 func (w *ClaudeWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
-	w.available = false
-	defer func() { w.available = true }()
+	w.busy.Store(true)
+	defer w.busy.Store(false)
 
 	startTime := time.Now()
 
@@ -67,6 +83,7 @@ func (w *ClaudeWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 	args := []string{
 		"--print",          // Print response only
 		"--model", w.model, // Specify model
+		"--output-format", "json",
 	}
 
 	// #nosec G204
@@ -74,38 +91,210 @@ func (w *ClaudeWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 	cmd.Stdin = strings.NewReader(prompt)
 
 	output, err := cmd.Output()
+	final, parseErr := parseClaudeResult(output)
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			return &types.ExecutionResult{
-				TaskID:  task.ID,
-				Backend: w.backend,
-				Success: false,
-				Error:   fmt.Sprintf("claude exited with code %d: %s", exitErr.ExitCode(), string(exitErr.Stderr)),
-			}, nil
+			msg := string(exitErr.Stderr)
+			if parseErr == nil && final.Result != "" {
+				msg = final.Result
+			}
+			return w.failed(task, fmt.Sprintf("claude exited with code %d: %s", exitErr.ExitCode(), msg)), nil
 		}
-		return &types.ExecutionResult{
-			TaskID:  task.ID,
-			Backend: w.backend,
-			Success: false,
-			Error:   err.Error(),
-		}, nil
+		return w.failed(task, err.Error()), nil
+	}
+	if parseErr != nil {
+		return w.failed(task, fmt.Sprintf("unreadable claude output: %v", parseErr)), nil
 	}
 
-	duration := time.Since(startTime)
+	return w.finish(task, prompt, final, startTime), nil
+}
+
+// ExecuteStream runs a task using Claude Code CLI with stream-json output,
+// passing the text of each assistant message to onChunk as it arrives. The
+// final result event carries the answer, its usage and its cost.
+func (w *ClaudeWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	w.busy.Store(true)
+	defer w.busy.Store(false)
 
-	// Estimate cost based on model and output length
-	// These are rough estimates
-	cost := estimateCost(w.model, len(prompt), len(output))
+	startTime := time.Now()
+	prompt := buildClaudePrompt(task)
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	args := []string{
+		"--print",
+		"--model", w.model,
+		"--output-format", "stream-json",
+		"--verbose", // Required by stream-json in print mode
+	}
+
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, w.cliPath, args...)
+	cmd.Stdin = strings.NewReader(prompt)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return w.failed(task, err.Error()), nil
+	}
+	if err := cmd.Start(); err != nil {
+		return w.failed(task, err.Error()), nil
+	}
+
+	var messages []string
+	var final *claudeEvent
+	scanner := newLineScanner(stdout)
+	for scanner.Scan() {
+		var event claudeEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue // Not every line is guaranteed to be an event
+		}
+		switch event.Type {
+		case "assistant":
+			for _, block := range event.Message.Content {
+				if block.Type == "text" && block.Text != "" {
+					messages = append(messages, block.Text)
+					onChunk(block.Text)
+				}
+			}
+		case "result":
+			final = &event
+		}
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		_, _ = io.Copy(io.Discard, stdout) // Let the CLI finish writing before waiting on it
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			msg := stderr.String()
+			if final != nil && final.Result != "" {
+				msg = final.Result
+			}
+			return w.failed(task, fmt.Sprintf("claude exited with code %d: %s", exitErr.ExitCode(), msg)), nil
+		}
+		return w.failed(task, err.Error()), nil
+	}
+	if scanErr != nil {
+		return w.failed(task, fmt.Sprintf("failed to read claude output: %v", scanErr)), nil
+	}
+	if final == nil {
+		return w.failed(task, "claude finished without a result"), nil
+	}
+	if final.Result == "" {
+		final.Result = strings.Join(messages, "\n\n")
+	}
+
+	return w.finish(task, prompt, final, startTime), nil
+}
+
+// finish builds the execution result from the CLI's result event. The cost
+// is the one the CLI reports, or else the usage priced from the table;
+// usage is estimated from the text only when the CLI reports none.
+func (w *ClaudeWorker) finish(task *types.Task, prompt string, final *claudeEvent, startTime time.Time) *types.ExecutionResult {
+	if final.IsError {
+		return w.failed(task, fmt.Sprintf("claude reported an error (%s): %s", final.Subtype, final.Result))
+	}
+
+	usage := types.Usage{
+		InputTokens:      final.Usage.InputTokens,
+		OutputTokens:     final.Usage.OutputTokens,
+		CacheReadTokens:  final.Usage.CacheReadInputTokens,
+		CacheWriteTokens: final.Usage.CacheCreationInputTokens,
+	}
+	if usage.Total() == 0 {
+		usage = types.Usage{
+			InputTokens:  estimateTokens(len(prompt)),
+			OutputTokens: estimateTokens(len(final.Result)),
+		}
+	}
+	cost := final.TotalCostUSD
+	if cost == 0 {
+		cost = w.price.Cost(usage)
+	}
 
 	return &types.ExecutionResult{
 		TaskID:     task.ID,
 		Backend:    w.backend,
 		Success:    true,
-		Output:     string(output),
-		TokensUsed: estimateTokens(len(prompt) + len(output)),
+		Output:     final.Result,
+		TokensUsed: usage.Total(),
+		Usage:      usage,
 		CostUSD:    cost,
-		DurationMs: duration.Milliseconds(),
-	}, nil
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+}
+
+func (w *ClaudeWorker) failed(task *types.Task, msg string) *types.ExecutionResult {
+	return &types.ExecutionResult{
+		TaskID:  task.ID,
+		Backend: w.backend,
+		Success: false,
+		Error:   msg,
+	}
+}
+
+// claudeEvent is a line of the CLI's stream-json output, or the whole of its
+// json output. Assistant events carry a message; the final result event
+// carries the answer, its usage and its cost.
+type claudeEvent struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	Message struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+	Result       string      `json:"result"`
+	IsError      bool        `json:"is_error"`
+	TotalCostUSD float64     `json:"total_cost_usd"`
+	Usage        claudeUsage `json:"usage"`
+}
+
+// claudeUsage is the token usage the CLI reports for a run
+type claudeUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// parseClaudeResult reads the result event from the CLI's json output,
+// which is a single result object, or with --verbose a list of every event
+func parseClaudeResult(data []byte) (*claudeEvent, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var events []claudeEvent
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, err
+		}
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].Type == "result" {
+				return &events[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no result event")
+	}
+
+	var event claudeEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	if event.Type != "result" {
+		return nil, fmt.Errorf("expected a result, got a %q event", event.Type)
+	}
+	return &event, nil
+}
+
+// EstimateCost predicts the cost of running a task before it is executed
+func (w *ClaudeWorker) EstimateCost(task *types.Task) float64 {
+	return w.price.Cost(types.Usage{
+		InputTokens:  estimateTokens(len(buildClaudePrompt(task))),
+		OutputTokens: expectedOutputTokens,
+	})
 }
 
 // CheckQuota verifies if the worker has sufficient quota
@@ -143,7 +332,18 @@ func (w *ClaudeWorker) CheckQuota(ctx context.Context) error {
 
 // Available returns whether the worker is available
 func (w *ClaudeWorker) Available() bool {
-	return w.available
+	return !w.busy.Load()
+}
+
+// Priced reports whether the pricing table has an entry for the worker's
+// model; without one its executions are counted as free
+func (w *ClaudeWorker) Priced() bool {
+	return w.priced
+}
+
+// ID returns the worker's identifier
+func (w *ClaudeWorker) ID() string {
+	return w.id
 }
 
 // Backend returns the worker's backend type
@@ -168,34 +368,18 @@ func buildClaudePrompt(task *types.Task) string {
 		prompt += "\n\n" + task.Description
 	}
 
-	return prompt
-}
-
-func estimateCost(model string, inputLen, outputLen int) float64 {
-	// Rough token estimate (4 chars per token)
-	inputTokens := float64(inputLen) / 4
-	outputTokens := float64(outputLen) / 4
-
-	// Pricing per 1K tokens (approximate)
-	var inputPrice, outputPrice float64
-	switch {
-	case strings.Contains(model, "opus"):
-		inputPrice = 0.015  // $15/1M input
-		outputPrice = 0.075 // $75/1M output
-	case strings.Contains(model, "sonnet"):
-		inputPrice = 0.003  // $3/1M input
-		outputPrice = 0.015 // $15/1M output
-	case strings.Contains(model, "haiku"):
-		inputPrice = 0.00025  // $0.25/1M input
-		outputPrice = 0.00125 // $1.25/1M output
-	default:
-		inputPrice = 0.003
-		outputPrice = 0.015
+	if task.Context != "" {
+		prompt += "\n\nRelevant files from the repository:\n\n" + task.Context
 	}
 
-	return (inputTokens * inputPrice / 1000) + (outputTokens * outputPrice / 1000)
+	prompt += "\n\nTo change files, give a unified diff in a diff code block, with paths relative to the repository root."
+
+	return prompt
 }
 
+// expectedOutputTokens is the response size assumed when pricing a task up front
+const expectedOutputTokens = 2000
+
 func estimateTokens(charCount int) int {
 	// Rough estimate: 4 characters per token
 	return charCount / 4
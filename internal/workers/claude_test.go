@@ -2,7 +2,9 @@ package workers
 
 import (
 	"context"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -71,3 +73,115 @@ func TestClaudeWorker_CheckQuota(t *testing.T) {
 		})
 	}
 }
+
+func TestClaudeWorker_ExecuteStream(t *testing.T) {
+	script := `#!/bin/sh
+case "$*" in
+*"--output-format stream-json"*) ;;
+*) echo "missing stream-json flags: $*" >&2; exit 2 ;;
+esac
+cat > /dev/null
+echo '{"type":"system","subtype":"init"}'
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"Looking at the code."}]}}'
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read"},{"type":"text","text":"Here is the fix."}]}}'
+echo '{"type":"result","subtype":"success","is_error":false,"result":"Here is the fix.","usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":30}}'
+`
+	scriptPath := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	worker := NewClaudeWorker("test", ClaudeConfig{
+		CLIPath: scriptPath,
+		Model:   "sonnet",
+		Backend: types.BackendClaudeSonnet,
+	})
+
+	var chunks []string
+	result, err := worker.ExecuteStream(context.Background(), &types.Task{ID: "task-1", Title: "Fix it"}, func(text string) {
+		chunks = append(chunks, text)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error %q", result.Error)
+	}
+	if result.Output != "Here is the fix." || result.TokensUsed != 150 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if strings.Join(chunks, "|") != "Looking at the code.|Here is the fix." {
+		t.Errorf("Unexpected chunks: %q", chunks)
+	}
+
+	// An error result fails the execution
+	script = "#!/bin/sh\ncat > /dev/null\necho '{\"type\":\"result\",\"subtype\":\"error_max_turns\",\"is_error\":true,\"result\":\"ran out of turns\"}'\n"
+	if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	result, err = worker.ExecuteStream(context.Background(), &types.Task{ID: "task-2", Title: "Fix it"}, func(string) {})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "ran out of turns") {
+		t.Errorf("Expected the reported error, got %+v", result)
+	}
+}
+
+func TestClaudeWorker_Execute(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		tokens int
+		cost   float64
+	}{
+		{
+			name:   "Reported cost",
+			output: `{"type":"result","subtype":"success","is_error":false,"result":"Done.","total_cost_usd":0.25,"usage":{"input_tokens":1000,"output_tokens":100,"cache_read_input_tokens":2000,"cache_creation_input_tokens":500}}`,
+			tokens: 3600,
+			cost:   0.25,
+		},
+		{
+			// Sonnet: 1000 x $3 + 2000 x $0.30 + 500 x $3.75 + 100 x $15 per million
+			name:   "Priced from usage",
+			output: `{"type":"result","subtype":"success","is_error":false,"result":"Done.","usage":{"input_tokens":1000,"output_tokens":100,"cache_read_input_tokens":2000,"cache_creation_input_tokens":500}}`,
+			tokens: 3600,
+			cost:   0.006975,
+		},
+		{
+			name:   "Verbose event list",
+			output: `[{"type":"system","subtype":"init"},{"type":"result","subtype":"success","is_error":false,"result":"Done.","total_cost_usd":0.01,"usage":{"input_tokens":10,"output_tokens":5}}]`,
+			tokens: 15,
+			cost:   0.01,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := "#!/bin/sh\ncat > /dev/null\ncat <<'EOF'\n" + tt.output + "\nEOF\n"
+			scriptPath := filepath.Join(t.TempDir(), "claude")
+			if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			worker := NewClaudeWorker("test", ClaudeConfig{
+				CLIPath: scriptPath,
+				Model:   "claude-sonnet-4-20250514",
+				Backend: types.BackendClaudeSonnet,
+			})
+			result, err := worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Fix it"})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if !result.Success || result.Output != "Done." {
+				t.Fatalf("Unexpected result: %+v", result)
+			}
+			if result.TokensUsed != tt.tokens || result.Usage.Total() != tt.tokens {
+				t.Errorf("Expected %d tokens, got %d (%+v)", tt.tokens, result.TokensUsed, result.Usage)
+			}
+			if math.Abs(result.CostUSD-tt.cost) > 1e-9 {
+				t.Errorf("Expected cost $%v, got $%v", tt.cost, result.CostUSD)
+			}
+		})
+	}
+}
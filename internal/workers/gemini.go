@@ -8,19 +8,23 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/cammy/bigo/internal/pricing"
 	"github.com/cammy/bigo/pkg/types"
 )
 
 // GeminiWorker executes tasks using Google's Gemini API
 type GeminiWorker struct {
-	id        string
-	apiKey    string
-	model     string
-	backend   types.Backend
-	client    *http.Client
-	available bool
+	id      string
+	apiKey  string
+	model   string
+	backend types.Backend
+	client  *http.Client
+	busy    atomic.Bool
+	price   pricing.Price
+	priced  bool // Whether the pricing table has the model
 }
 
 // GeminiConfig holds configuration for creating a Gemini worker
@@ -29,6 +33,7 @@ type GeminiConfig struct {
 	Model   string
 	Backend types.Backend
 	Timeout time.Duration
+	Pricing pricing.Table // Prices by model ID; the defaults when nil
 }
 
 // NewGeminiWorker creates a new Gemini worker
@@ -38,6 +43,12 @@ func NewGeminiWorker(id string, cfg GeminiConfig) *GeminiWorker {
 		timeout = 5 * time.Minute
 	}
 
+	table := cfg.Pricing
+	if table == nil {
+		table = pricing.Defaults()
+	}
+	price, priced := table.Lookup(cfg.Model)
+
 	return &GeminiWorker{
 		id:      id,
 		apiKey:  cfg.APIKey,
@@ -46,14 +57,25 @@ func NewGeminiWorker(id string, cfg GeminiConfig) *GeminiWorker {
 		client: &http.Client{
 			Timeout: timeout,
 		},
-		available: true,
+		price:  price,
+		priced: priced,
 	}
 }
 
 // Execute runs a task using Gemini
 func (w *GeminiWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
-	w.available = false
-	defer func() { w.available = true }()
+	return w.execute(ctx, task, nil)
+}
+
+// ExecuteStream runs a task using Gemini, passing the response to onChunk
+// as it is generated
+func (w *GeminiWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	return w.execute(ctx, task, onChunk)
+}
+
+func (w *GeminiWorker) execute(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	w.busy.Store(true)
+	defer w.busy.Store(false)
 
 	startTime := time.Now()
 
@@ -61,7 +83,7 @@ func (w *GeminiWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 	prompt := buildTaskPrompt(task)
 
 	// Call Gemini API
-	response, err := w.generate(ctx, prompt)
+	response, err := w.generate(ctx, prompt, onChunk)
 	if err != nil {
 		return &types.ExecutionResult{
 			TaskID:  task.ID,
@@ -78,12 +100,15 @@ func (w *GeminiWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 		output = response.Candidates[0].Content.Parts[0].Text
 	}
 
-	tokensUsed := 0
-	if response.UsageMetadata.TotalTokenCount > 0 {
-		tokensUsed = response.UsageMetadata.TotalTokenCount
-	} else {
+	usage := response.UsageMetadata.usage()
+	tokensUsed := response.UsageMetadata.TotalTokenCount
+	if tokensUsed == 0 {
 		// Fallback estimate
-		tokensUsed = estimateTokens(len(prompt) + len(output))
+		usage = types.Usage{
+			InputTokens:  estimateTokens(len(prompt)),
+			OutputTokens: estimateTokens(len(output)),
+		}
+		tokensUsed = usage.Total()
 	}
 
 	return &types.ExecutionResult{
@@ -92,18 +117,27 @@ func (w *GeminiWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 		Success:    true,
 		Output:     output,
 		TokensUsed: tokensUsed,
-		CostUSD:    w.estimateCost(tokensUsed),
+		Usage:      usage,
+		CostUSD:    w.price.Cost(usage),
 		DurationMs: duration.Milliseconds(),
 	}, nil
 }
 
+// EstimateCost predicts the cost of running a task before it is executed
+func (w *GeminiWorker) EstimateCost(task *types.Task) float64 {
+	return w.price.Cost(types.Usage{
+		InputTokens:  estimateTokens(len(buildTaskPrompt(task))),
+		OutputTokens: expectedOutputTokens,
+	})
+}
+
 // CheckQuota verifies if the worker has sufficient quota
 func (w *GeminiWorker) CheckQuota(ctx context.Context) error {
 	// Try a minimal generation
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := w.generate(ctx, "hi")
+	_, err := w.generate(ctx, "hi", nil)
 	if err != nil {
 		errStr := strings.ToLower(err.Error())
 
@@ -128,7 +162,18 @@ func (w *GeminiWorker) CheckQuota(ctx context.Context) error {
 
 // Available returns whether the worker is available
 func (w *GeminiWorker) Available() bool {
-	return w.available
+	return !w.busy.Load()
+}
+
+// Priced reports whether the pricing table has an entry for the worker's
+// model; without one its executions are counted as free
+func (w *GeminiWorker) Priced() bool {
+	return w.priced
+}
+
+// ID returns the worker's identifier
+func (w *GeminiWorker) ID() string {
+	return w.id
 }
 
 // Backend returns the worker's backend type
@@ -171,13 +216,30 @@ type geminiCandidate struct {
 }
 
 type geminiUsageMetadata struct {
-	PromptTokenCount     int `json:"promptTokenCount"`
-	CandidatesTokenCount int `json:"candidatesTokenCount"`
-	TotalTokenCount      int `json:"totalTokenCount"`
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount"` // Part of the prompt served from a context cache
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount"` // Thinking, billed as output
+	TotalTokenCount         int `json:"totalTokenCount"`
 }
 
-func (w *GeminiWorker) generate(ctx context.Context, prompt string) (*geminiResponse, error) {
+// usage splits the counts by how they are billed
+func (m geminiUsageMetadata) usage() types.Usage {
+	return types.Usage{
+		InputTokens:     m.PromptTokenCount - m.CachedContentTokenCount,
+		CacheReadTokens: m.CachedContentTokenCount,
+		OutputTokens:    m.CandidatesTokenCount + m.ThoughtsTokenCount,
+	}
+}
+
+// generate calls generateContent, or with onChunk set streamGenerateContent,
+// whose server-sent events each carry a fragment of the response that is
+// passed to onChunk. The result holds the whole text as a single part.
+func (w *GeminiWorker) generate(ctx context.Context, prompt string, onChunk func(string)) (*geminiResponse, error) {
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", w.model, w.apiKey)
+	if onChunk != nil {
+		url = fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", w.model, w.apiKey)
+	}
 
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
@@ -231,6 +293,11 @@ func (w *GeminiWorker) generate(ctx context.Context, prompt string) (*geminiResp
 
 		return nil, fmt.Errorf("Gemini returned status %d (%s): %s", resp.StatusCode, resp.Status, errMsg)
 	}
+
+	if onChunk != nil {
+		return readGeminiStream(resp.Body, onChunk)
+	}
+
 	var geminiResp geminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&geminiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -239,19 +306,40 @@ func (w *GeminiWorker) generate(ctx context.Context, prompt string) (*geminiResp
 	return &geminiResp, nil
 }
 
-func (w *GeminiWorker) estimateCost(tokens int) float64 {
-	// Pricing (approximate, e.g., for Gemini 1.5 Flash/Pro)
-	// Flash is very cheap, Pro is moderate.
-	// This is a rough estimation.
-
-	// Flash: ~$0.35 / 1M tokens (input), ~$1.05 / 1M tokens (output)
-	// Pro: ~$3.50 / 1M tokens (input), ~$10.50 / 1M tokens (output)
+// readGeminiStream assembles a streamed response from its "data:" events.
+// Usage metadata is cumulative, so the last event's counts are kept.
+func readGeminiStream(body io.Reader, onChunk func(string)) (*geminiResponse, error) {
+	var text strings.Builder
+	var usage geminiUsageMetadata
+	scanner := newLineScanner(body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
 
-	// Simplify to an average per token
-	ratePer1M := 0.5 // Default to Flash-ish
-	if w.backend == types.BackendGeminiPro {
-		ratePer1M = 7.0
+		var event geminiResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if len(event.Candidates) > 0 {
+			for _, part := range event.Candidates[0].Content.Parts {
+				if part.Text != "" {
+					text.WriteString(part.Text)
+					onChunk(part.Text)
+				}
+			}
+		}
+		if event.UsageMetadata.TotalTokenCount > 0 {
+			usage = event.UsageMetadata
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return float64(tokens) * ratePer1M / 1_000_000
+	return &geminiResponse{
+		Candidates:    []geminiCandidate{{Content: geminiContent{Parts: []geminiPart{{Text: text.String()}}}}},
+		UsageMetadata: usage,
+	}, nil
 }
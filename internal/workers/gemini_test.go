@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"math"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/cammy/bigo/internal/pricing"
 	"github.com/cammy/bigo/pkg/types"
 )
 
@@ -101,6 +103,77 @@ func TestGeminiWorker_Execute(t *testing.T) {
 	}
 }
 
+func TestGeminiWorker_Usage(t *testing.T) {
+	respBody := `{
+		"candidates": [{"content": {"parts": [{"text": "ok"}]}}],
+		"usageMetadata": {"promptTokenCount": 1000, "cachedContentTokenCount": 400, "candidatesTokenCount": 200, "thoughtsTokenCount": 50, "totalTokenCount": 1250}
+	}`
+	worker := NewGeminiWorker("worker-1", GeminiConfig{
+		APIKey:  "test-key",
+		Model:   "gemini-2.5-pro",
+		Backend: types.BackendGeminiPro,
+		Pricing: pricing.Table{"gemini-2.5-pro": {Input: 1, Output: 10, CachedInput: 0.25}},
+	})
+	worker.client.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(respBody)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	result, err := worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	want := types.Usage{InputTokens: 600, CacheReadTokens: 400, OutputTokens: 250}
+	if result.Usage != want || result.TokensUsed != 1250 {
+		t.Errorf("Expected usage %+v and 1250 tokens, got %+v and %d", want, result.Usage, result.TokensUsed)
+	}
+	// 600 x $1 + 400 x $0.25 + 250 x $10 per million
+	if cost := 0.0032; math.Abs(result.CostUSD-cost) > 1e-9 {
+		t.Errorf("Expected cost $%v, got $%v", cost, result.CostUSD)
+	}
+}
+
+func TestGeminiWorker_ExecuteStream(t *testing.T) {
+	events := "data: {\"candidates\": [{\"content\": {\"parts\": [{\"text\": \"This is \"}]}}], \"usageMetadata\": {\"promptTokenCount\": 40}}\r\n\r\n" +
+		"data: {\"candidates\": [{\"content\": {\"parts\": [{\"text\": \"streamed.\"}]}}], \"usageMetadata\": {\"promptTokenCount\": 40, \"candidatesTokenCount\": 4, \"totalTokenCount\": 44}}\r\n\r\n"
+
+	worker := NewGeminiWorker("worker-1", GeminiConfig{APIKey: "test-key", Model: "gemini-pro", Backend: types.BackendGeminiPro})
+	worker.client.Transport = &mockTransport{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			if !strings.Contains(req.URL.Path, ":streamGenerateContent") || req.URL.Query().Get("alt") != "sse" {
+				t.Errorf("Expected a streaming request, got %s", req.URL.String())
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(events)),
+				Header:     make(http.Header),
+			}, nil
+		},
+	}
+
+	var chunks []string
+	result, err := worker.ExecuteStream(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"}, func(text string) {
+		chunks = append(chunks, text)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if !result.Success || result.Output != "This is streamed." {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.TokensUsed != 44 {
+		t.Errorf("Expected the final usage of 44 tokens, got %d", result.TokensUsed)
+	}
+	if len(chunks) != 2 || chunks[1] != "streamed." {
+		t.Errorf("Expected two chunks, got %q", chunks)
+	}
+}
+
 func TestGeminiWorker_CheckQuota(t *testing.T) {
 	tests := []struct {
 		name          string
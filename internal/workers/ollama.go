@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cammy/bigo/pkg/types"
@@ -19,7 +21,7 @@ type OllamaWorker struct {
 	model        string
 	backend      types.Backend
 	client       *http.Client
-	available    bool
+	busy         atomic.Bool
 	opencodePath string
 }
 
@@ -48,14 +50,23 @@ func NewOllamaWorker(id string, cfg OllamaConfig) *OllamaWorker {
 		client: &http.Client{
 			Timeout: timeout,
 		},
-		available: true,
 	}
 }
 
 // Execute runs a task using Ollama
 func (w *OllamaWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
-	w.available = false
-	defer func() { w.available = true }()
+	return w.execute(ctx, task, nil)
+}
+
+// ExecuteStream runs a task using Ollama, passing the response to onChunk
+// as it is generated
+func (w *OllamaWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	return w.execute(ctx, task, onChunk)
+}
+
+func (w *OllamaWorker) execute(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	w.busy.Store(true)
+	defer w.busy.Store(false)
 
 	startTime := time.Now()
 
@@ -63,7 +74,7 @@ func (w *OllamaWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 	prompt := buildTaskPrompt(task)
 
 	// Call Ollama API
-	response, err := w.generate(ctx, prompt)
+	response, err := w.generate(ctx, prompt, onChunk)
 	if err != nil {
 		return &types.ExecutionResult{
 			TaskID:  task.ID,
@@ -81,6 +92,7 @@ func (w *OllamaWorker) Execute(ctx context.Context, task *types.Task) (*types.Ex
 		Success:    true,
 		Output:     response.Response,
 		TokensUsed: response.TotalTokens(),
+		Usage:      types.Usage{InputTokens: response.PromptEvalCount, OutputTokens: response.EvalCount},
 		CostUSD:    0, // Ollama is free
 		DurationMs: duration.Milliseconds(),
 	}, nil
@@ -93,7 +105,12 @@ func (w *OllamaWorker) CheckQuota(ctx context.Context) error {
 
 // Available returns whether the worker is available
 func (w *OllamaWorker) Available() bool {
-	return w.available
+	return !w.busy.Load()
+}
+
+// ID returns the worker's identifier
+func (w *OllamaWorker) ID() string {
+	return w.id
 }
 
 // Backend returns the worker's backend type
@@ -135,17 +152,21 @@ type ollamaResponse struct {
 	EvalCount       int    `json:"eval_count"`
 	PromptEvalCount int    `json:"prompt_eval_count"`
 	TotalDuration   int64  `json:"total_duration"`
+	Error           string `json:"error"`
 }
 
 func (r *ollamaResponse) TotalTokens() int {
 	return r.EvalCount + r.PromptEvalCount
 }
 
-func (w *OllamaWorker) generate(ctx context.Context, prompt string) (*ollamaResponse, error) {
+// generate calls the generate API. With onChunk set, the response is
+// streamed as newline-delimited JSON and each fragment is passed to onChunk;
+// the last line carries the token counts.
+func (w *OllamaWorker) generate(ctx context.Context, prompt string, onChunk func(string)) (*ollamaResponse, error) {
 	reqBody := ollamaRequest{
 		Model:  w.model,
 		Prompt: prompt,
-		Stream: false,
+		Stream: onChunk != nil,
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -173,6 +194,10 @@ func (w *OllamaWorker) generate(ctx context.Context, prompt string) (*ollamaResp
 		return nil, fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
+	if onChunk != nil {
+		return readOllamaStream(resp.Body, onChunk)
+	}
+
 	var ollamaResp ollamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -181,6 +206,38 @@ func (w *OllamaWorker) generate(ctx context.Context, prompt string) (*ollamaResp
 	return &ollamaResp, nil
 }
 
+// readOllamaStream assembles a streamed response, passing each fragment to onChunk
+func readOllamaStream(body io.Reader, onChunk func(string)) (*ollamaResponse, error) {
+	var text strings.Builder
+	scanner := newLineScanner(body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama failed mid-response: %s", chunk.Error)
+		}
+		if chunk.Response != "" {
+			text.WriteString(chunk.Response)
+			onChunk(chunk.Response)
+		}
+		if chunk.Done {
+			chunk.Response = text.String()
+			return &chunk, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil, fmt.Errorf("response ended before Ollama finished")
+}
+
 func buildTaskPrompt(task *types.Task) string {
 	prompt := fmt.Sprintf(`You are an expert software engineer. Complete the following task:
 
@@ -196,11 +253,21 @@ func buildTaskPrompt(task *types.Task) string {
 `, task.Description)
 	}
 
+	if task.Context != "" {
+		prompt += fmt.Sprintf(`## Codebase Context
+Relevant files from the repository:
+
+%s
+
+`, task.Context)
+	}
+
 	prompt += `## Instructions
 - Provide clear, working code
 - Include brief explanations for non-obvious decisions
 - If the task is ambiguous, state your assumptions
 - Format code properly with appropriate language tags
+- To change files, give a unified diff in a diff code block, with paths relative to the repository root
 
 ## Response
 `
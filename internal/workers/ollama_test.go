@@ -0,0 +1,75 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+func TestOllamaWorker_ExecuteStream(t *testing.T) {
+	var streamed []bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		streamed = append(streamed, req.Stream)
+
+		if !req.Stream {
+			w.Write([]byte(`{"response": "Hello, world", "done": true, "eval_count": 5, "prompt_eval_count": 10}`))
+			return
+		}
+		for _, line := range []string{
+			`{"response": "Hello", "done": false}`,
+			`{"response": ", world", "done": false}`,
+			`{"response": "", "done": true, "eval_count": 5, "prompt_eval_count": 10}`,
+		} {
+			w.Write([]byte(line + "\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	worker := NewOllamaWorker("ollama-1", OllamaConfig{Endpoint: server.URL, Model: "qwen3:8b", Backend: types.BackendOllama})
+	task := &types.Task{ID: "task-1", Title: "Say hello"}
+
+	var chunks []string
+	result, err := worker.ExecuteStream(context.Background(), task, func(text string) {
+		chunks = append(chunks, text)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if !result.Success || result.Output != "Hello, world" || result.TokensUsed != 15 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if strings.Join(chunks, "|") != "Hello|, world" {
+		t.Errorf("Expected two chunks, got %q", chunks)
+	}
+
+	// Without a chunk handler the response comes in one piece
+	result, err = worker.Execute(context.Background(), task)
+	if err != nil || result.Output != "Hello, world" || result.TokensUsed != 15 {
+		t.Errorf("Unexpected result: %+v (%v)", result, err)
+	}
+	if len(streamed) != 2 || !streamed[0] || streamed[1] {
+		t.Errorf("Expected one streamed and one plain request, got %v", streamed)
+	}
+}
+
+func TestReadOllamaStream_Errors(t *testing.T) {
+	_, err := readOllamaStream(strings.NewReader(`{"response": "Hel"}`+"\n"+`{"error": "model unloaded"}`+"\n"), func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "model unloaded") {
+		t.Errorf("Expected the stream's error, got %v", err)
+	}
+
+	_, err = readOllamaStream(strings.NewReader(`{"response": "Hel"}`+"\n"), func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "ended before") {
+		t.Errorf("Expected a truncated stream to fail, got %v", err)
+	}
+}
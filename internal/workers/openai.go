@@ -0,0 +1,371 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cammy/bigo/internal/pricing"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// OpenAIWorker executes tasks against an OpenAI-compatible chat completions
+// API, such as OpenAI itself, vLLM, the llama.cpp server or LM Studio
+type OpenAIWorker struct {
+	id      string
+	baseURL string
+	apiKey  string
+	model   string
+	backend types.Backend
+	client  *http.Client
+	busy    atomic.Bool
+	price   pricing.Price
+	priced  bool // Whether the pricing table has the model
+}
+
+// OpenAIConfig holds configuration for creating an OpenAI-compatible worker
+type OpenAIConfig struct {
+	BaseURL string // Up to and including the version, e.g. http://localhost:8000/v1
+	APIKey  string // Sent as a bearer token; local servers usually need none
+	Model   string
+	Backend types.Backend
+	Timeout time.Duration
+	Pricing pricing.Table // Prices by model ID; the defaults when nil
+}
+
+// NewOpenAIWorker creates a new OpenAI-compatible worker
+func NewOpenAIWorker(id string, cfg OpenAIConfig) *OpenAIWorker {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	table := cfg.Pricing
+	if table == nil {
+		table = pricing.Defaults()
+	}
+	price, priced := table.Lookup(cfg.Model)
+
+	return &OpenAIWorker{
+		id:      id,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		backend: cfg.Backend,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		price:  price,
+		priced: priced,
+	}
+}
+
+// Execute runs a task through the chat completions API
+func (w *OpenAIWorker) Execute(ctx context.Context, task *types.Task) (*types.ExecutionResult, error) {
+	return w.execute(ctx, task, nil)
+}
+
+// ExecuteStream runs a task through the chat completions API, passing the
+// response to onChunk as it is generated
+func (w *OpenAIWorker) ExecuteStream(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	return w.execute(ctx, task, onChunk)
+}
+
+func (w *OpenAIWorker) execute(ctx context.Context, task *types.Task, onChunk func(string)) (*types.ExecutionResult, error) {
+	w.busy.Store(true)
+	defer w.busy.Store(false)
+
+	startTime := time.Now()
+
+	// Build the prompt
+	prompt := buildTaskPrompt(task)
+
+	response, err := w.complete(ctx, prompt, onChunk)
+	if err != nil {
+		return &types.ExecutionResult{
+			TaskID:  task.ID,
+			Backend: w.backend,
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	duration := time.Since(startTime)
+
+	if len(response.Choices) == 0 {
+		return &types.ExecutionResult{
+			TaskID:     task.ID,
+			Backend:    w.backend,
+			Success:    false,
+			Error:      "response contained no choices",
+			DurationMs: duration.Milliseconds(),
+		}, nil
+	}
+	output := response.Choices[0].Message.Content
+
+	usage := response.Usage.usage()
+	tokensUsed := response.Usage.TotalTokens
+	if tokensUsed == 0 {
+		tokensUsed = usage.Total()
+	}
+	if tokensUsed == 0 {
+		// Not every server reports usage, particularly when streaming
+		usage = types.Usage{
+			InputTokens:  estimateTokens(len(prompt)),
+			OutputTokens: estimateTokens(len(output)),
+		}
+		tokensUsed = usage.Total()
+	}
+
+	return &types.ExecutionResult{
+		TaskID:     task.ID,
+		Backend:    w.backend,
+		Success:    true,
+		Output:     output,
+		TokensUsed: tokensUsed,
+		Usage:      usage,
+		CostUSD:    w.price.Cost(usage),
+		DurationMs: duration.Milliseconds(),
+	}, nil
+}
+
+// EstimateCost predicts the cost of running a task before it is executed
+func (w *OpenAIWorker) EstimateCost(task *types.Task) float64 {
+	return w.price.Cost(types.Usage{
+		InputTokens:  estimateTokens(len(buildTaskPrompt(task))),
+		OutputTokens: expectedOutputTokens,
+	})
+}
+
+// CheckQuota verifies the server is reachable and accepts the API key by
+// listing its models, which costs nothing
+func (w *OpenAIWorker) CheckQuota(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", w.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	w.authorize(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("quota check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("quota exceeded (Rate Limit): %w", readOpenAIError(resp))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("quota check failed (API key issue): %w", readOpenAIError(resp))
+	default:
+		return fmt.Errorf("quota check failed: %w", readOpenAIError(resp))
+	}
+}
+
+// Available returns whether the worker is available
+func (w *OpenAIWorker) Available() bool {
+	return !w.busy.Load()
+}
+
+// Priced reports whether the pricing table has an entry for the worker's
+// model; without one its executions are counted as free
+func (w *OpenAIWorker) Priced() bool {
+	return w.priced
+}
+
+// ID returns the worker's identifier
+func (w *OpenAIWorker) ID() string {
+	return w.id
+}
+
+// Backend returns the worker's backend type
+func (w *OpenAIWorker) Backend() types.Backend {
+	return w.backend
+}
+
+// openAIRequest represents a chat completions request
+type openAIRequest struct {
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// openAIResponse represents a chat completions response; streamed chunks
+// carry a delta in place of the message
+type openAIResponse struct {
+	Choices []openAIChoice `json:"choices"`
+	Usage   openAIUsage    `json:"usage"`
+}
+
+type openAIChoice struct {
+	Message openAIMessage `json:"message"`
+	Delta   openAIMessage `json:"delta"`
+}
+
+type openAIUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"` // Part of the prompt served from the prompt cache
+	} `json:"prompt_tokens_details"`
+}
+
+// usage splits the counts by how they are billed
+func (u openAIUsage) usage() types.Usage {
+	return types.Usage{
+		InputTokens:     u.PromptTokens - u.PromptTokensDetails.CachedTokens,
+		CacheReadTokens: u.PromptTokensDetails.CachedTokens,
+		OutputTokens:    u.CompletionTokens,
+	}
+}
+
+// complete calls the chat completions endpoint. With onChunk set it asks
+// for server-sent events, each carrying a fragment of the response that is
+// passed to onChunk; the result then holds the whole text as its message.
+func (w *OpenAIWorker) complete(ctx context.Context, prompt string, onChunk func(string)) (*openAIResponse, error) {
+	reqBody := openAIRequest{
+		Model:    w.model,
+		Messages: []openAIMessage{{Role: "user", Content: prompt}},
+	}
+	if onChunk != nil {
+		reqBody.Stream = true
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	w.authorize(req)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readOpenAIError(resp)
+	}
+
+	if onChunk != nil {
+		return readOpenAIStream(resp.Body, onChunk)
+	}
+
+	var openAIResp openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &openAIResp, nil
+}
+
+// authorize adds the API key, if there is one, to a request
+func (w *OpenAIWorker) authorize(req *http.Request) {
+	if w.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+w.apiKey)
+	}
+}
+
+// readOpenAIError describes a failed response, preferring the message in
+// an {"error": {"message": ...}} body over the raw body
+func readOpenAIError(resp *http.Response) error {
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("server returned status %d (%s) but failed to read body: %w", resp.StatusCode, resp.Status, err)
+	}
+
+	var errorResponse struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	errMsg := strings.TrimSpace(string(bodyBytes))
+	if json.Unmarshal(bodyBytes, &errorResponse) == nil && errorResponse.Error.Message != "" {
+		errMsg = errorResponse.Error.Message
+	}
+
+	return fmt.Errorf("server returned status %d (%s): %s", resp.StatusCode, resp.Status, errMsg)
+}
+
+// readOpenAIStream assembles a streamed response from its "data:" events,
+// which end with "data: [DONE]". Usage arrives in a final chunk without
+// choices when the server supports include_usage.
+func readOpenAIStream(body io.Reader, onChunk func(string)) (*openAIResponse, error) {
+	var text strings.Builder
+	var usage openAIUsage
+	done := false
+	scanner := newLineScanner(body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var event struct {
+			openAIResponse
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if event.Error != nil {
+			return nil, fmt.Errorf("server reported an error mid-stream: %s", event.Error.Message)
+		}
+		for _, choice := range event.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				onChunk(choice.Delta.Content)
+			}
+		}
+		if event.Usage.TotalTokens > 0 {
+			usage = event.Usage
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !done {
+		return nil, fmt.Errorf("response ended before the stream was done")
+	}
+
+	return &openAIResponse{
+		Choices: []openAIChoice{{Message: openAIMessage{Role: "assistant", Content: text.String()}}},
+		Usage:   usage,
+	}, nil
+}
@@ -0,0 +1,224 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cammy/bigo/internal/pricing"
+	"github.com/cammy/bigo/pkg/types"
+)
+
+// openAIStub serves chat completions, recording each request's body and
+// authorization header
+type openAIStub struct {
+	t        *testing.T
+	status   int
+	response string
+	requests []openAIRequest
+	auth     []string
+}
+
+func (s *openAIStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" || r.URL.Path != "/v1/chat/completions" {
+		s.t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+	}
+	var req openAIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.t.Errorf("Failed to decode request: %v", err)
+	}
+	s.requests = append(s.requests, req)
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+	fmt.Fprint(w, s.response)
+}
+
+func TestOpenAIWorker_Execute(t *testing.T) {
+	stub := &openAIStub{t: t, response: `{
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Done."}, "finish_reason": "stop"}],
+		"usage": {"prompt_tokens": 1200, "completion_tokens": 300, "total_tokens": 1500, "prompt_tokens_details": {"cached_tokens": 200}}
+	}`}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	worker := NewOpenAIWorker("worker-1", OpenAIConfig{
+		BaseURL: server.URL + "/v1/",
+		APIKey:  "sk-test",
+		Model:   "my-model",
+		Backend: "openai:default",
+		Pricing: pricing.Table{"my-model": {Input: 1, Output: 2, CachedInput: 0.5}},
+	})
+	if !worker.Priced() {
+		t.Error("Expected the model to be priced")
+	}
+
+	result, err := worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !result.Success || result.Output != "Done." || result.Backend != "openai:default" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.TokensUsed != 1500 || result.Usage != (types.Usage{InputTokens: 1000, CacheReadTokens: 200, OutputTokens: 300}) {
+		t.Errorf("Unexpected usage: %d tokens, %+v", result.TokensUsed, result.Usage)
+	}
+	// 1000 input at $1, 200 cached at $0.50 and 300 output at $2 per million
+	if want := 0.0017; math.Abs(result.CostUSD-want) > 1e-12 {
+		t.Errorf("Expected cost %v, got %v", want, result.CostUSD)
+	}
+
+	if len(stub.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(stub.requests))
+	}
+	req := stub.requests[0]
+	if req.Model != "my-model" || req.Stream || len(req.Messages) != 1 || req.Messages[0].Role != "user" ||
+		!strings.Contains(req.Messages[0].Content, "Test Task") {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	if stub.auth[0] != "Bearer sk-test" {
+		t.Errorf("Expected the API key as a bearer token, got %q", stub.auth[0])
+	}
+}
+
+func TestOpenAIWorker_Execute_Errors(t *testing.T) {
+	// A local server without an API key or usage reporting
+	stub := &openAIStub{t: t, response: `{"choices": [{"message": {"role": "assistant", "content": "Done."}}]}`}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	worker := NewOpenAIWorker("worker-1", OpenAIConfig{BaseURL: server.URL + "/v1", Model: "qwen3:8b", Backend: "openai:local"})
+	if worker.Priced() {
+		t.Error("Expected a model missing from the pricing table to be unpriced")
+	}
+	result, err := worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !result.Success || result.Usage.InputTokens == 0 || result.Usage.OutputTokens == 0 || result.CostUSD != 0 {
+		t.Errorf("Expected estimated usage at no cost, got %+v", result)
+	}
+	if stub.auth[0] != "" {
+		t.Errorf("Expected no authorization header, got %q", stub.auth[0])
+	}
+
+	// The server's error message is reported
+	stub.status = http.StatusBadRequest
+	stub.response = `{"error": {"message": "model not found", "type": "invalid_request_error"}}`
+	result, err = worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "400") || !strings.Contains(result.Error, "model not found") {
+		t.Errorf("Expected a failure with the server's message, got %+v", result)
+	}
+
+	// A response without choices has no answer to report
+	stub.status = 0
+	stub.response = `{"choices": [], "usage": {"prompt_tokens": 40, "completion_tokens": 0, "total_tokens": 40}}`
+	result, err = worker.Execute(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Success || result.Error != "response contained no choices" {
+		t.Errorf("Expected a response without choices to fail, got %+v", result)
+	}
+}
+
+func TestOpenAIWorker_ExecuteStream(t *testing.T) {
+	events := "data: {\"choices\": [{\"index\": 0, \"delta\": {\"role\": \"assistant\", \"content\": \"\"}}]}\n\n" +
+		"data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"This is \"}}]}\n\n" +
+		": keep-alive\n\n" +
+		"data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"streamed.\"}, \"finish_reason\": \"stop\"}]}\n\n" +
+		"data: {\"choices\": [], \"usage\": {\"prompt_tokens\": 40, \"completion_tokens\": 4, \"total_tokens\": 44}}\n\n" +
+		"data: [DONE]\n\n"
+	stub := &openAIStub{t: t, response: events}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	worker := NewOpenAIWorker("worker-1", OpenAIConfig{BaseURL: server.URL + "/v1", Model: "gpt-4o-mini", Backend: "openai:default"})
+
+	var chunks []string
+	result, err := worker.ExecuteStream(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"}, func(text string) {
+		chunks = append(chunks, text)
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if !result.Success || result.Output != "This is streamed." {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result.TokensUsed != 44 || result.Usage.InputTokens != 40 || result.Usage.OutputTokens != 4 {
+		t.Errorf("Expected the final usage of 44 tokens, got %d (%+v)", result.TokensUsed, result.Usage)
+	}
+	if result.CostUSD <= 0 {
+		t.Errorf("Expected a cost from the default price of gpt-4o-mini, got %v", result.CostUSD)
+	}
+	if len(chunks) != 2 || chunks[1] != "streamed." {
+		t.Errorf("Expected two chunks, got %q", chunks)
+	}
+	if req := stub.requests[0]; !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+		t.Errorf("Expected a streaming request asking for usage, got %+v", req)
+	}
+
+	// A stream cut off before [DONE] fails rather than returning part of the answer
+	stub.response = strings.TrimSuffix(events, "data: [DONE]\n\n")
+	result, err = worker.ExecuteStream(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"}, func(string) {})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "before the stream was done") {
+		t.Errorf("Expected a truncated stream to fail, got %+v", result)
+	}
+
+	stub.response = "data: {\"error\": {\"message\": \"out of memory\"}}\n\n"
+	result, _ = worker.ExecuteStream(context.Background(), &types.Task{ID: "task-1", Title: "Test Task"}, func(string) {})
+	if result.Success || !strings.Contains(result.Error, "out of memory") {
+		t.Errorf("Expected an error event to fail, got %+v", result)
+	}
+}
+
+func TestOpenAIWorker_CheckQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		errorContains string
+	}{
+		{"OK", http.StatusOK, ""},
+		{"Bad key", http.StatusUnauthorized, "API key"},
+		{"Rate limited", http.StatusTooManyRequests, "quota exceeded"},
+		{"Server error", http.StatusInternalServerError, "quota check failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" || r.URL.Path != "/v1/models" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.statusCode)
+				fmt.Fprint(w, `{"error": {"message": "nope"}}`)
+			}))
+			defer server.Close()
+
+			worker := NewOpenAIWorker("w", OpenAIConfig{BaseURL: server.URL + "/v1", APIKey: "sk-test", Model: "m"})
+			err := worker.CheckQuota(context.Background())
+			if tt.errorContains == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) || !strings.Contains(err.Error(), "nope") {
+				t.Errorf("Expected an error containing %q, got %v", tt.errorContains, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package workers
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/cammy/bigo/pkg/types"
@@ -15,24 +16,65 @@ type Worker interface {
 	CheckQuota(ctx context.Context) error
 }
 
-// Pool manages a collection of workers for a specific backend type
+// Pool manages a collection of workers for a specific backend type.
+// Each worker runs one task at a time, so the pool size is the backend's
+// concurrency limit. Pools may also share a Limit, which caps the workers
+// leased across all of them.
 type Pool struct {
 	backend     types.Backend
 	workers     []Worker
 	maxWorkers  int
 	activeCount int
+	idle        chan Worker
+	limit       *Limit
 	mu          sync.Mutex
 }
 
+// Limit caps how many workers can be leased at once across the pools that
+// share it, such as the pools for each of a provider's models
+type Limit struct {
+	slots chan struct{}
+}
+
+// NewLimit creates a limit of n concurrent leases
+func NewLimit(n int) *Limit {
+	if n < 1 {
+		n = 1
+	}
+	return &Limit{slots: make(chan struct{}, n)}
+}
+
+// free returns a slot taken for a lease
+func (l *Limit) free() {
+	if l == nil {
+		return
+	}
+	select {
+	case <-l.slots:
+	default:
+	}
+}
+
 // NewPool creates a new worker pool
 func NewPool(backend types.Backend, maxWorkers int) *Pool {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+
 	return &Pool{
 		backend:    backend,
 		workers:    make([]Worker, 0, maxWorkers),
 		maxWorkers: maxWorkers,
+		idle:       make(chan Worker, maxWorkers),
 	}
 }
 
+// SetLimit makes the pool's leases count against a limit shared with other
+// pools. Set it before the pool is used.
+func (p *Pool) SetLimit(l *Limit) {
+	p.limit = l
+}
+
 // Add adds a worker to the pool
 func (p *Pool) Add(w Worker) {
 	p.mu.Lock()
@@ -40,44 +82,90 @@ func (p *Pool) Add(w Worker) {
 
 	if len(p.workers) < p.maxWorkers {
 		p.workers = append(p.workers, w)
+		p.idle <- w
 	}
 }
 
-// Acquire gets an available worker from the pool
-func (p *Pool) Acquire() Worker {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+// Acquire waits until a worker is free, and the pool's limit has room, and
+// leases it to the caller
+func (p *Pool) Acquire(ctx context.Context) (Worker, error) {
+	if p.Size() == 0 {
+		return nil, fmt.Errorf("no workers registered for %s", p.backend)
+	}
+
+	if p.limit != nil {
+		select {
+		case p.limit.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case w := <-p.idle:
+		p.mu.Lock()
+		p.activeCount++
+		p.mu.Unlock()
+		return w, nil
+	case <-ctx.Done():
+		p.limit.free()
+		return nil, ctx.Err()
+	}
+}
 
-	for _, w := range p.workers {
-		if w.Available() {
-			p.activeCount++
-			return w
+// TryAcquire leases a free worker, or returns nil if the pool or its limit
+// is saturated
+func (p *Pool) TryAcquire() Worker {
+	if p.limit != nil {
+		select {
+		case p.limit.slots <- struct{}{}:
+		default:
+			return nil
 		}
 	}
-	return nil
+
+	select {
+	case w := <-p.idle:
+		p.mu.Lock()
+		p.activeCount++
+		p.mu.Unlock()
+		return w
+	default:
+		p.limit.free()
+		return nil
+	}
 }
 
 // Release returns a worker to the pool
 func (p *Pool) Release(w Worker) {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	if p.activeCount > 0 {
 		p.activeCount--
 	}
+	p.mu.Unlock()
+
+	select {
+	case p.idle <- w:
+	default:
+		// Released more often than acquired; the pool is already full
+		return
+	}
+	p.limit.free()
 }
 
-// Available returns true if any worker is available
+// Available returns true if a worker can be acquired without waiting
 func (p *Pool) Available() bool {
+	if p.limit != nil && len(p.limit.slots) == cap(p.limit.slots) {
+		return false
+	}
+	return len(p.idle) > 0
+}
+
+// Workers returns the workers registered in the pool
+func (p *Pool) Workers() []Worker {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-
-	for _, w := range p.workers {
-		if w.Available() {
-			return true
-		}
-	}
-	return false
+	return append([]Worker(nil), p.workers...)
 }
 
 // Size returns the number of workers in the pool
@@ -0,0 +1,114 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cammy/bigo/pkg/types"
+)
+
+func TestPool_AcquireRelease(t *testing.T) {
+	pool := NewPool(types.BackendOllama, 2)
+	for _, id := range []string{"a", "b", "c"} {
+		pool.Add(NewOllamaWorker(id, OllamaConfig{Backend: types.BackendOllama}))
+	}
+
+	if pool.Size() != 2 {
+		t.Fatalf("Expected pool capped at 2 workers, got %d", pool.Size())
+	}
+
+	ctx := context.Background()
+	w1, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	w2, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if w1 == w2 {
+		t.Error("Expected two distinct workers")
+	}
+	if pool.ActiveCount() != 2 || pool.Available() {
+		t.Errorf("Expected saturated pool, got %d active", pool.ActiveCount())
+	}
+	if pool.TryAcquire() != nil {
+		t.Error("Expected TryAcquire to fail on a saturated pool")
+	}
+
+	// A saturated pool blocks until the context is done
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(waitCtx); err == nil {
+		t.Error("Expected Acquire to time out on a saturated pool")
+	}
+
+	// Release hands capacity to a waiting caller
+	acquired := make(chan Worker)
+	go func() {
+		w, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Errorf("Acquire failed: %v", err)
+		}
+		acquired <- w
+	}()
+
+	pool.Release(w1)
+	select {
+	case w := <-acquired:
+		if w != w1 {
+			t.Error("Expected the released worker to be handed out")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Waiting Acquire was not woken by Release")
+	}
+}
+
+func TestPool_SharedLimit(t *testing.T) {
+	limit := NewLimit(1)
+	fast, reasoning := NewPool(types.BackendOllamaFast, 2), NewPool(types.BackendOllamaReason, 2)
+	for i, pool := range []*Pool{fast, reasoning} {
+		pool.SetLimit(limit)
+		for j := 0; j < 2; j++ {
+			pool.Add(NewOllamaWorker(fmt.Sprintf("ollama-%d-%d", i, j), OllamaConfig{Backend: pool.Backend()}))
+		}
+	}
+
+	ctx := context.Background()
+	w, err := fast.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// The other pool has idle workers, but the limit is taken
+	if reasoning.Available() || reasoning.TryAcquire() != nil || fast.TryAcquire() != nil {
+		t.Error("Expected the shared limit to hold back both pools")
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := reasoning.Acquire(waitCtx); err == nil {
+		t.Error("Expected Acquire to time out while the limit is taken")
+	}
+
+	fast.Release(w)
+	w, err = reasoning.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed after release: %v", err)
+	}
+	if fast.ActiveCount() != 0 || reasoning.ActiveCount() != 1 {
+		t.Errorf("Expected one lease, got %d and %d active", fast.ActiveCount(), reasoning.ActiveCount())
+	}
+	reasoning.Release(w)
+	if !fast.Available() || !reasoning.Available() {
+		t.Error("Expected both pools to be available once the lease is returned")
+	}
+}
+
+func TestPool_Empty(t *testing.T) {
+	pool := NewPool(types.BackendClaudeOpus, 0)
+	if _, err := pool.Acquire(context.Background()); err == nil {
+		t.Error("Expected error acquiring from an empty pool")
+	}
+}
@@ -0,0 +1,17 @@
+package workers
+
+import (
+	"bufio"
+	"io"
+)
+
+// maxStreamLine bounds a single line of streamed output, such as an event
+// carrying a whole assistant message
+const maxStreamLine = 16 * 1024 * 1024
+
+// newLineScanner reads a streamed response line by line
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	return scanner
+}
@@ -1,6 +1,11 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Tier represents task complexity levels
 type Tier int
@@ -30,6 +35,23 @@ func (t Tier) String() string {
 	}
 }
 
+// ParseTier parses a tier name ("simple"), label ("T1") or number ("1")
+func ParseTier(s string) (Tier, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for t := TierTrivial; t <= TierCritical; t++ {
+		if name == strings.ToLower(t.String()) {
+			return t, nil
+		}
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "t"))
+	if err == nil && n >= int(TierTrivial) && n <= int(TierCritical) {
+		return Tier(n), nil
+	}
+
+	return 0, fmt.Errorf("unknown tier %q (use trivial, simple, standard, complex or critical)", s)
+}
+
 // Backend represents an execution backend
 type Backend string
 
@@ -56,6 +78,7 @@ const (
 	StatusRejected   TaskStatus = "rejected"
 	StatusDone       TaskStatus = "done"
 	StatusFailed     TaskStatus = "failed"
+	StatusOrphaned   TaskStatus = "orphaned" // Left in flight by a process that stopped
 )
 
 // Task represents a unit of work to be executed
@@ -68,6 +91,7 @@ type Task struct {
 	Status      TaskStatus
 	Backend     Backend
 	ContextPath string
+	Context     string // Repository files packed into the prompt, as Markdown
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -81,21 +105,54 @@ type ClassificationResult struct {
 	Patterns           []string
 	EstimatedLines     int
 	EstimatedFiles     int
+	RelatedFiles       []string // Files in the working tree the task mentions
+	ScopeEvidence      []string // What in the working tree drove the scope estimate
+	Source             string   // How the tier was decided: patterns, model or cache
 }
 
 // ExecutionResult holds the output of a task execution
 type ExecutionResult struct {
 	TaskID     string
+	WorkerID   string // The pool worker that ran it, where there is one
 	Backend    Backend
 	Success    bool
 	Output     string
 	Diff       string
 	TokensUsed int
+	Usage      Usage // Token counts by kind, where the backend reports them
 	CostUSD    float64
 	DurationMs int64
 	Error      string
 }
 
+// Usage counts the tokens an execution consumed, by how they are billed
+type Usage struct {
+	InputTokens      int // Input not served from a prompt cache
+	OutputTokens     int
+	CacheReadTokens  int // Input read from a prompt cache
+	CacheWriteTokens int // Input written to a prompt cache
+}
+
+// Input returns every input token, cached or not
+func (u Usage) Input() int {
+	return u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// Total returns every token, input and output
+func (u Usage) Total() int {
+	return u.Input() + u.OutputTokens
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:      u.InputTokens + o.InputTokens,
+		OutputTokens:     u.OutputTokens + o.OutputTokens,
+		CacheReadTokens:  u.CacheReadTokens + o.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + o.CacheWriteTokens,
+	}
+}
+
 // ValidationResult holds the output of a validation
 type ValidationResult struct {
 	ExecutionID string
@@ -103,6 +160,7 @@ type ValidationResult struct {
 	Backend     Backend
 	Approved    bool
 	Findings    []Finding
+	Review      *ExecutionResult // The reviewer's own run, with its usage and cost, if it ran
 }
 
 // Finding represents an issue found during validation
@@ -124,9 +182,21 @@ type Message struct {
 // TierConfig maps tiers to their execution configuration
 type TierConfig struct {
 	PrimaryBackend    Backend
+	PlannerBackend    Backend // Splits the task into subtasks first; empty runs it as one unit
 	ValidatorBackend  Backend
 	ValidatorCount    int
 	RequiredApprovals int
+	Fallbacks         []Backend // Tried in order when the primary is unavailable
+	Escalation        []Backend // Stronger backends, climbed one per retry after a failed or rejected attempt
+}
+
+// PremiumBackend returns the strongest backend the tier's tasks can run on:
+// the top of its escalation ladder, or its primary when it does not escalate
+func (tc TierConfig) PremiumBackend() Backend {
+	if n := len(tc.Escalation); n > 0 {
+		return tc.Escalation[n-1]
+	}
+	return tc.PrimaryBackend
 }
 
 // DefaultTierConfigs returns the default tier routing configuration
@@ -137,30 +207,41 @@ func DefaultTierConfigs() map[Tier]TierConfig {
 			ValidatorBackend:  "",
 			ValidatorCount:    0,
 			RequiredApprovals: 0,
+			Fallbacks:         []Backend{BackendOllama, BackendOllamaFast, BackendClaudeHaiku},
+			Escalation:        []Backend{BackendOllama, BackendOllamaReason, BackendClaudeHaiku},
 		},
 		TierSimple: {
 			PrimaryBackend:    BackendOllama,
 			ValidatorBackend:  BackendOllama,
 			ValidatorCount:    1,
 			RequiredApprovals: 1,
+			Fallbacks:         []Backend{BackendOllama, BackendOllamaFast, BackendClaudeHaiku},
+			Escalation:        []Backend{BackendOllamaReason, BackendClaudeHaiku, BackendClaudeSonnet},
 		},
 		TierStandard: {
 			PrimaryBackend:    BackendClaudeSonnet,
 			ValidatorBackend:  BackendClaudeSonnet,
 			ValidatorCount:    2,
 			RequiredApprovals: 2,
+			Fallbacks:         []Backend{BackendClaudeSonnet, BackendOllamaReason, BackendClaudeHaiku},
+			Escalation:        []Backend{BackendClaudeOpus},
 		},
 		TierComplex: {
 			PrimaryBackend:    BackendClaudeSonnet,
+			PlannerBackend:    BackendClaudeOpus,
 			ValidatorBackend:  BackendClaudeSonnet,
 			ValidatorCount:    3,
 			RequiredApprovals: 2,
+			Fallbacks:         []Backend{BackendClaudeOpus, BackendClaudeSonnet},
+			Escalation:        []Backend{BackendClaudeOpus},
 		},
 		TierCritical: {
 			PrimaryBackend:    BackendClaudeOpus,
+			PlannerBackend:    BackendClaudeOpus,
 			ValidatorBackend:  BackendClaudeSonnet,
 			ValidatorCount:    5,
 			RequiredApprovals: 4,
+			Fallbacks:         []Backend{BackendClaudeOpus, BackendClaudeSonnet},
 		},
 	}
 }